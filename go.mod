@@ -1,5 +1,5 @@
 module github.com/screwyprof/roshambo
 
-go 1.12
+go 1.13
 
 require github.com/segmentio/ksuid v1.0.2
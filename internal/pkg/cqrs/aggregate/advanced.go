@@ -1,13 +1,24 @@
 package aggregate
 
 import (
+	"context"
+	"errors"
+
 	"github.com/screwyprof/roshambo/pkg/domain"
 )
 
+var (
+	// ErrSnapshotsAreNotSupported happens if the pure aggregate doesn't implement domain.Snapshotter interface.
+	ErrSnapshotsAreNotSupported = errors.New("aggregate doesn't support snapshots")
+)
+
 // Advanced implements an advanced aggregate root.
 type Advanced struct {
 	domain.Aggregate
 	version int
+	// handled is the number of events produced by the handled commands.
+	// They have changed the state, but are not counted in the version yet.
+	handled int
 
 	commandHandler domain.CommandHandler
 	eventApplier   domain.EventApplier
@@ -41,7 +52,12 @@ func (b *Advanced) Version() int {
 
 // Handle implements domain.CommandHandler.
 func (b *Advanced) Handle(c domain.Command) ([]domain.DomainEvent, error) {
-	events, err := b.commandHandler.Handle(c)
+	return b.HandleContext(context.Background(), c)
+}
+
+// HandleContext implements domain.ContextCommandHandler.
+func (b *Advanced) HandleContext(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+	events, err := domain.ContextCommandHandlerOf(b.commandHandler).HandleContext(ctx, c)
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +65,7 @@ func (b *Advanced) Handle(c domain.Command) ([]domain.DomainEvent, error) {
 	if applierErr := b.eventApplier.Apply(events...); applierErr != nil {
 		return nil, applierErr
 	}
+	b.handled += len(events)
 
 	return events, nil
 }
@@ -61,3 +78,63 @@ func (b *Advanced) Apply(e ...domain.DomainEvent) error {
 	b.version += len(e)
 	return nil
 }
+
+// Rehydrate implements domain.Rehydrator interface.
+func (b *Advanced) Rehydrate(envelopes ...domain.Envelope) error {
+	if err := b.eventApplier.Apply(domain.EventsOf(envelopes)...); err != nil {
+		return err
+	}
+
+	for i, e := range envelopes {
+		if i > 0 && e.Version != 0 && e.Version == envelopes[i-1].Version {
+			continue
+		}
+		b.version++
+	}
+	return nil
+}
+
+// MarkCommitted implements domain.Committer interface.
+func (b *Advanced) MarkCommitted() {
+	b.version += b.handled
+	b.handled = 0
+}
+
+// Snapshot implements domain.SnapshotAggregate interface.
+//
+// The snapshot reflects the current state including the events produced by the handled commands.
+// It returns ErrSnapshotsAreNotSupported if the pure aggregate doesn't implement domain.Snapshotter.
+func (b *Advanced) Snapshot() (domain.Snapshot, error) {
+	snapshotter, ok := b.Aggregate.(domain.Snapshotter)
+	if !ok {
+		return domain.Snapshot{}, ErrSnapshotsAreNotSupported
+	}
+
+	state, err := snapshotter.SnapshotState()
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+
+	return domain.Snapshot{
+		AggregateID:   b.AggregateID().String(),
+		AggregateType: b.AggregateType(),
+		Version:       b.version + b.handled,
+		State:         state,
+	}, nil
+}
+
+// Restore implements domain.SnapshotAggregate interface.
+func (b *Advanced) Restore(snapshot domain.Snapshot) error {
+	snapshotter, ok := b.Aggregate.(domain.Snapshotter)
+	if !ok {
+		return ErrSnapshotsAreNotSupported
+	}
+
+	if err := snapshotter.RestoreState(snapshot.State); err != nil {
+		return err
+	}
+
+	b.version = snapshot.Version
+	b.handled = 0
+	return nil
+}
@@ -1,6 +1,8 @@
 package aggregate_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/screwyprof/roshambo/internal/pkg/assert"
@@ -15,6 +17,18 @@ import (
 // ensure that Advanced implements domain.AdvancedAggregate interface.
 var _ domain.AdvancedAggregate = (*aggregate.Advanced)(nil)
 
+// ensure that Advanced implements domain.SnapshotAggregate interface.
+var _ domain.SnapshotAggregate = (*aggregate.Advanced)(nil)
+
+// ensure that Advanced implements domain.Rehydrator interface.
+var _ domain.Rehydrator = (*aggregate.Advanced)(nil)
+
+// ensure that Advanced implements domain.ContextCommandHandler interface.
+var _ domain.ContextCommandHandler = (*aggregate.Advanced)(nil)
+
+// ensure that Advanced implements domain.Committer interface.
+var _ domain.Committer = (*aggregate.Advanced)(nil)
+
 func TestNewBase(t *testing.T) {
 	t.Run("ItPanicsIfThePureAggregateIsNotGiven", func(t *testing.T) {
 		factory := func() {
@@ -68,6 +82,46 @@ func TestBaseHandle(t *testing.T) {
 	})
 }
 
+func TestBaseHandleContext(t *testing.T) {
+	t.Run("ItPassesTheContextIfTheHandlerAcceptsIt", func(t *testing.T) {
+		// arrange
+		agg := createContextAwareAgg()
+		ctx := domain.WithPrincipal(context.Background(), "tom@game.net")
+
+		// act
+		events, err := agg.HandleContext(ctx, MakeSomethingHappen{})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{SomethingHappened{}}, events)
+	})
+
+	t.Run("ItFailsIfTheContextIsDone", func(t *testing.T) {
+		// arrange
+		agg := createTestAggWithDefaultCommandHandlerAndEventApplier()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		_, err := agg.HandleContext(ctx, MakeSomethingHappen{})
+
+		// assert
+		assert.Equals(t, context.Canceled, err)
+	})
+
+	t.Run("ItReturnsAnErrorIfTheHandlerRejectsTheContext", func(t *testing.T) {
+		// arrange
+		agg := createContextAwareAgg()
+
+		// act
+		_, err := agg.Handle(MakeSomethingHappen{})
+
+		// assert
+		assert.Equals(t, errPrincipalIsRequired, err)
+	})
+}
+
 func TestBaseVersion(t *testing.T) {
 	t.Run("ItReturnsVersion", func(t *testing.T) {
 		agg := createTestAggWithDefaultCommandHandlerAndEventApplier()
@@ -103,6 +157,156 @@ func TestBaseApply(t *testing.T) {
 	})
 }
 
+func TestBaseRehydrate(t *testing.T) {
+	t.Run("ItReturnsAnErrorIfTheEventAppliersNotFound", func(t *testing.T) {
+		agg := createTestAggWithEmptyEventApplier()
+
+		err := agg.Rehydrate(domain.Envelope{Version: 1, Event: SomethingHappened{}})
+
+		assert.Equals(t, ErrOnSomethingHappenedApplierNotFound, err)
+	})
+
+	t.Run("ItCountsTheEnvelopesSharingAStreamVersionOnce", func(t *testing.T) {
+		agg := createTestAggWithDefaultCommandHandlerAndEventApplier()
+
+		err := agg.Rehydrate(
+			domain.Envelope{Version: 1, Event: SomethingHappened{}},
+			domain.Envelope{Version: 2, Event: SomethingElseHappened{}},
+			domain.Envelope{Version: 2, Event: SomethingElseHappened{}},
+		)
+
+		assert.Ok(t, err)
+		assert.Equals(t, 2, agg.Version())
+	})
+}
+
+func TestBaseMarkCommitted(t *testing.T) {
+	t.Run("ItCountsTheHandledEventsInTheVersion", func(t *testing.T) {
+		// arrange
+		agg := createTestAggWithDefaultCommandHandlerAndEventApplier()
+		_, err := agg.Handle(MakeSomethingHappen{})
+		assert.Ok(t, err)
+
+		// act
+		agg.MarkCommitted()
+
+		// assert
+		assert.Equals(t, 1, agg.Version())
+
+		snapshot, err := agg.Snapshot()
+		assert.Ok(t, err)
+		assert.Equals(t, 1, snapshot.Version)
+	})
+}
+
+func TestBaseSnapshot(t *testing.T) {
+	t.Run("ItFailsIfTheAggregateDoesNotSupportSnapshots", func(t *testing.T) {
+		// arrange
+		agg := createTestAggWithoutSnapshots()
+
+		// act
+		_, err := agg.Snapshot()
+
+		// assert
+		assert.Equals(t, aggregate.ErrSnapshotsAreNotSupported, err)
+	})
+
+	t.Run("ItSnapshotsTheStateIncludingHandledEvents", func(t *testing.T) {
+		// arrange
+		agg := createTestAggWithDefaultCommandHandlerAndEventApplier()
+		assert.Ok(t, agg.Apply(SomethingElseHappened{}))
+
+		_, err := agg.Handle(MakeSomethingHappen{})
+		assert.Ok(t, err)
+
+		want := domain.Snapshot{
+			AggregateID:   "TestAgg1",
+			AggregateType: TestAggregateType,
+			Version:       2,
+			State:         TestAggregateSnapshot{AlreadyHappened: true},
+		}
+
+		// act
+		got, err := agg.Snapshot()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, got)
+		assert.Equals(t, 1, agg.Version())
+	})
+}
+
+func TestBaseRestore(t *testing.T) {
+	t.Run("ItFailsIfTheAggregateDoesNotSupportSnapshots", func(t *testing.T) {
+		// arrange
+		agg := createTestAggWithoutSnapshots()
+
+		// act
+		err := agg.Restore(domain.Snapshot{})
+
+		// assert
+		assert.Equals(t, aggregate.ErrSnapshotsAreNotSupported, err)
+	})
+
+	t.Run("ItFailsIfTheStateCannotBeRestored", func(t *testing.T) {
+		// arrange
+		agg := createTestAggWithDefaultCommandHandlerAndEventApplier()
+
+		// act
+		err := agg.Restore(domain.Snapshot{State: "invalid"})
+
+		// assert
+		assert.Equals(t, ErrInvalidSnapshot, err)
+	})
+
+	t.Run("ItRestoresTheStateAndVersion", func(t *testing.T) {
+		// arrange
+		agg := createTestAggWithDefaultCommandHandlerAndEventApplier()
+
+		// act
+		err := agg.Restore(domain.Snapshot{Version: 5, State: TestAggregateSnapshot{AlreadyHappened: true}})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 5, agg.Version())
+
+		_, err = agg.Handle(MakeSomethingHappen{})
+		assert.Equals(t, ErrItCanHappenOnceOnly, err)
+	})
+}
+
+var errPrincipalIsRequired = errors.New("principal is required")
+
+// contextAwareAggregate only makes something happen on behalf of an authenticated principal.
+type contextAwareAggregate struct {
+	*TestAggregate
+}
+
+func (a contextAwareAggregate) MakeSomethingHappen(
+	ctx context.Context, c MakeSomethingHappen) ([]domain.DomainEvent, error) {
+	if _, ok := domain.PrincipalFrom(ctx); !ok {
+		return nil, errPrincipalIsRequired
+	}
+	return []domain.DomainEvent{SomethingHappened{}}, nil
+}
+
+func createContextAwareAgg() *aggregate.Advanced {
+	pureAgg := contextAwareAggregate{NewTestAggregate(StringIdentifier("TestAgg1"))}
+
+	handler := aggregate.NewCommandHandler()
+	handler.RegisterHandlers(pureAgg)
+
+	applier := aggregate.NewEventApplier()
+	applier.RegisterAppliers(pureAgg)
+
+	return aggregate.NewAdvanced(pureAgg, handler, applier)
+}
+
+func createTestAggWithoutSnapshots() *aggregate.Advanced {
+	pureAgg := struct{ domain.Aggregate }{NewTestAggregate(StringIdentifier("TestAgg1"))}
+	return aggregate.NewAdvanced(pureAgg, aggregate.NewCommandHandler(), aggregate.NewEventApplier())
+}
+
 func createTestAggWithDefaultCommandHandlerAndEventApplier() *aggregate.Advanced {
 	ID := StringIdentifier("TestAgg1")
 	pureAgg := NewTestAggregate(ID)
@@ -1,6 +1,7 @@
 package aggregate
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sync"
@@ -10,19 +11,28 @@ import (
 
 // CommandHandler registers and handles commands.
 type CommandHandler struct {
-	handlers   map[string]domain.CommandHandlerFunc
+	handlers   map[string]domain.ContextCommandHandlerFunc
 	handlersMu sync.RWMutex
 }
 
 // NewCommandHandler creates a new instance of CommandHandler.
 func NewCommandHandler() *CommandHandler {
 	return &CommandHandler{
-		handlers: make(map[string]domain.CommandHandlerFunc),
+		handlers: make(map[string]domain.ContextCommandHandlerFunc),
 	}
 }
 
 // Handle implements domain.CommandHandler interface.
 func (h *CommandHandler) Handle(c domain.Command) ([]domain.DomainEvent, error) {
+	return h.HandleContext(context.Background(), c)
+}
+
+// HandleContext implements domain.ContextCommandHandler interface.
+func (h *CommandHandler) HandleContext(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	h.handlersMu.RLock()
 	defer h.handlersMu.RUnlock()
 
@@ -31,17 +41,29 @@ func (h *CommandHandler) Handle(c domain.Command) ([]domain.DomainEvent, error)
 		return nil, fmt.Errorf("handler for %s command is not found", c.CommandType())
 	}
 
-	return handler(c)
+	return handler(ctx, c)
 }
 
 // RegisterHandler registers a command handler for the given method.
 func (h *CommandHandler) RegisterHandler(method string, handler domain.CommandHandlerFunc) {
+	h.RegisterContextHandler(method, func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+		return handler(c)
+	})
+}
+
+// RegisterContextHandler registers a context-aware command handler for the given method.
+func (h *CommandHandler) RegisterContextHandler(method string, handler domain.ContextCommandHandlerFunc) {
 	h.handlersMu.Lock()
 	defer h.handlersMu.Unlock()
 	h.handlers[method] = handler
 }
 
 // RegisterHandlers registers all the command handlers found in the aggregate.
+//
+// A command handler takes the command and optionally the context before it:
+//
+//	MakeSomethingHappen(c MakeSomethingHappen) ([]domain.DomainEvent, error)
+//	MakeSomethingHappen(ctx context.Context, c MakeSomethingHappen) ([]domain.DomainEvent, error)
 func (h *CommandHandler) RegisterHandlers(aggregate domain.Aggregate) {
 	aggregateType := reflect.TypeOf(aggregate)
 	for i := 0; i < aggregateType.NumMethod(); i++ {
@@ -50,20 +72,25 @@ func (h *CommandHandler) RegisterHandlers(aggregate domain.Aggregate) {
 			continue
 		}
 
-		h.RegisterHandler(method.Name, func(c domain.Command) ([]domain.DomainEvent, error) {
-			return h.invokeCommandHandler(method, aggregate, c)
+		h.RegisterContextHandler(method.Name, func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+			return h.invokeCommandHandler(ctx, method, aggregate, c)
 		})
 	}
 }
 
 func (h *CommandHandler) methodHasValidSignature(method reflect.Method) bool {
-	if method.Type.NumIn() != 2 {
+	numIn := 2
+	if takesContext(method) {
+		numIn = 3
+	}
+
+	if method.Type.NumIn() != numIn {
 		return false
 	}
 
 	// ensure that the method has a domain.Command as a parameter.
 	cmdIntfType := reflect.TypeOf((*domain.Command)(nil)).Elem()
-	cmdType := method.Type.In(1)
+	cmdType := method.Type.In(numIn - 1)
 	if !cmdType.Implements(cmdIntfType) {
 		return false
 	}
@@ -72,8 +99,14 @@ func (h *CommandHandler) methodHasValidSignature(method reflect.Method) bool {
 }
 
 func (h *CommandHandler) invokeCommandHandler(
-	method reflect.Method, aggregate domain.Aggregate, c domain.Command) ([]domain.DomainEvent, error) {
-	result := method.Func.Call([]reflect.Value{reflect.ValueOf(aggregate), reflect.ValueOf(c)})
+	ctx context.Context, method reflect.Method, aggregate domain.Aggregate, c domain.Command) ([]domain.DomainEvent, error) {
+	args := []reflect.Value{reflect.ValueOf(aggregate)}
+	if takesContext(method) {
+		args = append(args, reflect.ValueOf(ctx))
+	}
+	args = append(args, reflect.ValueOf(c))
+
+	result := method.Func.Call(args)
 	resErr := result[1].Interface()
 	if resErr != nil {
 		return nil, resErr.(error)
@@ -82,3 +115,9 @@ func (h *CommandHandler) invokeCommandHandler(
 	events := eventsIntf.([]domain.DomainEvent)
 	return events, nil
 }
+
+// takesContext tells whether the method takes a context.Context right after the receiver.
+func takesContext(method reflect.Method) bool {
+	contextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	return method.Type.NumIn() > 1 && method.Type.In(1) == contextType
+}
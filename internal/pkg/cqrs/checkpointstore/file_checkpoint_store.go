@@ -0,0 +1,81 @@
+package checkpointstore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/screwyprof/roshambo/internal/pkg/fsutil"
+)
+
+// FileCheckpointStore keeps projection checkpoints in a JSON file.
+//
+// The file is replaced atomically on every change, so a crash leaves either the old or the new checkpoints.
+type FileCheckpointStore struct {
+	path          string
+	checkpoints   map[string]int64
+	checkpointsMu sync.RWMutex
+}
+
+// NewFileCheckpointStore creates a new instance of FileCheckpointStore which keeps the checkpoints in the given file.
+func NewFileCheckpointStore(path string) (*FileCheckpointStore, error) {
+	if path == "" {
+		panic("path is required")
+	}
+
+	s := &FileCheckpointStore{
+		path:        path,
+		checkpoints: make(map[string]int64),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &s.checkpoints); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// LoadCheckpoint implements domain.CheckpointStore interface.
+func (s *FileCheckpointStore) LoadCheckpoint(projection string) (int64, error) {
+	s.checkpointsMu.RLock()
+	defer s.checkpointsMu.RUnlock()
+
+	return s.checkpoints[projection], nil
+}
+
+// StoreCheckpoint implements domain.CheckpointStore interface.
+func (s *FileCheckpointStore) StoreCheckpoint(projection string, position int64) error {
+	s.checkpointsMu.Lock()
+	defer s.checkpointsMu.Unlock()
+
+	previous, existed := s.checkpoints[projection]
+	s.checkpoints[projection] = position
+
+	if err := s.write(); err != nil {
+		if existed {
+			s.checkpoints[projection] = previous
+		} else {
+			delete(s.checkpoints, projection)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileCheckpointStore) write() error {
+	data, err := json.Marshal(s.checkpoints)
+	if err != nil {
+		return err
+	}
+
+	return fsutil.ReplaceFile(s.path, data, true)
+}
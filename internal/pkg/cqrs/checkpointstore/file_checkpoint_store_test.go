@@ -0,0 +1,113 @@
+package checkpointstore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/checkpointstore"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that file checkpoint store implements domain.CheckpointStore interface.
+var _ domain.CheckpointStore = (*checkpointstore.FileCheckpointStore)(nil)
+
+var testDir string
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "checkpointstore")
+	if err != nil {
+		panic(err)
+	}
+	testDir = dir
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestNewFileCheckpointStore(t *testing.T) {
+	t.Run("ItPanicsIfPathIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			_, _ = checkpointstore.NewFileCheckpointStore("")
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItFailsIfTheFileIsCorrupted", func(t *testing.T) {
+		// arrange
+		path := checkpointsPath(t)
+		assert.Ok(t, ioutil.WriteFile(path, []byte("invalid"), 0644))
+
+		// act
+		_, err := checkpointstore.NewFileCheckpointStore(path)
+
+		// assert
+		assert.True(t, err != nil)
+	})
+}
+
+func TestFileCheckpointStoreLoadCheckpoint(t *testing.T) {
+	t.Run("ItReturnsZeroForAnUnknownProjection", func(t *testing.T) {
+		// arrange
+		s := createFileCheckpointStore(t, checkpointsPath(t))
+
+		// act
+		got, err := s.LoadCheckpoint("GameShortInfo")
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, int64(0), got)
+	})
+
+	t.Run("ItLoadsTheCheckpointsAfterReopening", func(t *testing.T) {
+		// arrange
+		path := checkpointsPath(t)
+		s := createFileCheckpointStore(t, path)
+		assert.Ok(t, s.StoreCheckpoint("GameShortInfo", 7))
+		assert.Ok(t, s.StoreCheckpoint("Another", 3))
+
+		// act
+		s = createFileCheckpointStore(t, path)
+		got, err := s.LoadCheckpoint("GameShortInfo")
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, int64(7), got)
+	})
+}
+
+func TestFileCheckpointStoreStoreCheckpoint(t *testing.T) {
+	t.Run("ItFailsIfTheFileCannotBeWritten", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(checkpointsPath(t), "missing", "checkpoints.json")
+		s := createFileCheckpointStore(t, path)
+
+		// act
+		err := s.StoreCheckpoint("GameShortInfo", 7)
+
+		// assert
+		assert.True(t, err != nil)
+
+		got, err := s.LoadCheckpoint("GameShortInfo")
+		assert.Ok(t, err)
+		assert.Equals(t, int64(0), got)
+	})
+}
+
+func createFileCheckpointStore(t *testing.T, path string) *checkpointstore.FileCheckpointStore {
+	t.Helper()
+	s, err := checkpointstore.NewFileCheckpointStore(path)
+	assert.Ok(t, err)
+	return s
+}
+
+func checkpointsPath(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir(testDir, "")
+	assert.Ok(t, err)
+	return filepath.Join(dir, "checkpoints.json")
+}
@@ -0,0 +1,33 @@
+package checkpointstore
+
+import "sync"
+
+// InMemoryCheckpointStore stores and loads projection checkpoints from memory.
+type InMemoryCheckpointStore struct {
+	checkpoints   map[string]int64
+	checkpointsMu sync.RWMutex
+}
+
+// NewInMemoryCheckpointStore creates a new instance of InMemoryCheckpointStore.
+func NewInMemoryCheckpointStore() *InMemoryCheckpointStore {
+	return &InMemoryCheckpointStore{
+		checkpoints: make(map[string]int64),
+	}
+}
+
+// LoadCheckpoint implements domain.CheckpointStore interface.
+func (s *InMemoryCheckpointStore) LoadCheckpoint(projection string) (int64, error) {
+	s.checkpointsMu.RLock()
+	defer s.checkpointsMu.RUnlock()
+
+	return s.checkpoints[projection], nil
+}
+
+// StoreCheckpoint implements domain.CheckpointStore interface.
+func (s *InMemoryCheckpointStore) StoreCheckpoint(projection string, position int64) error {
+	s.checkpointsMu.Lock()
+	defer s.checkpointsMu.Unlock()
+
+	s.checkpoints[projection] = position
+	return nil
+}
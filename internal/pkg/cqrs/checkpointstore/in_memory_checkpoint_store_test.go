@@ -0,0 +1,47 @@
+package checkpointstore_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/checkpointstore"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that checkpoint store implements domain.CheckpointStore interface.
+var _ domain.CheckpointStore = (*checkpointstore.InMemoryCheckpointStore)(nil)
+
+func TestNewInMemoryCheckpointStore(t *testing.T) {
+	t.Run("ItCreatesCheckpointStore", func(t *testing.T) {
+		assert.True(t, checkpointstore.NewInMemoryCheckpointStore() != nil)
+	})
+}
+
+func TestInMemoryCheckpointStoreLoadCheckpoint(t *testing.T) {
+	t.Run("ItReturnsZeroForAnUnknownProjection", func(t *testing.T) {
+		// arrange
+		s := checkpointstore.NewInMemoryCheckpointStore()
+
+		// act
+		got, err := s.LoadCheckpoint("GameShortInfo")
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, int64(0), got)
+	})
+
+	t.Run("ItLoadsTheStoredCheckpoint", func(t *testing.T) {
+		// arrange
+		s := checkpointstore.NewInMemoryCheckpointStore()
+		assert.Ok(t, s.StoreCheckpoint("GameShortInfo", 7))
+		assert.Ok(t, s.StoreCheckpoint("Another", 3))
+
+		// act
+		got, err := s.LoadCheckpoint("GameShortInfo")
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, int64(7), got)
+	})
+}
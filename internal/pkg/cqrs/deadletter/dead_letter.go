@@ -0,0 +1,34 @@
+package deadletter
+
+import (
+	"errors"
+	"time"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+var (
+	// ErrDeadLetterNotFound happens if there is no dead letter with the given ID.
+	ErrDeadLetterNotFound = errors.New("dead letter is not found")
+)
+
+// DeadLetter is an event which a handler has failed to handle.
+type DeadLetter struct {
+	ID string
+	// Handler is the name of the handler which has failed.
+	Handler  string
+	Envelope domain.Envelope
+	Error    string
+	Attempts int
+	FailedAt time.Time
+}
+
+// Store keeps dead letters.
+//
+// Put adds a new dead letter or replaces the one with the same ID.
+type Store interface {
+	Put(d DeadLetter) error
+	Get(ID string) (DeadLetter, error)
+	List() ([]DeadLetter, error)
+	Remove(ID string) error
+}
@@ -0,0 +1,74 @@
+package deadletter
+
+import (
+	"sort"
+	"sync"
+)
+
+// InMemoryStore keeps dead letters in memory.
+type InMemoryStore struct {
+	deadLetters   map[string]DeadLetter
+	deadLettersMu sync.RWMutex
+}
+
+// NewInMemoryStore creates a new instance of InMemoryStore.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		deadLetters: make(map[string]DeadLetter),
+	}
+}
+
+// Put implements Store interface.
+func (s *InMemoryStore) Put(d DeadLetter) error {
+	s.deadLettersMu.Lock()
+	defer s.deadLettersMu.Unlock()
+
+	s.deadLetters[d.ID] = d
+	return nil
+}
+
+// Get implements Store interface.
+func (s *InMemoryStore) Get(ID string) (DeadLetter, error) {
+	s.deadLettersMu.RLock()
+	defer s.deadLettersMu.RUnlock()
+
+	d, ok := s.deadLetters[ID]
+	if !ok {
+		return DeadLetter{}, ErrDeadLetterNotFound
+	}
+	return d, nil
+}
+
+// List implements Store interface.
+//
+// The dead letters are ordered by the time they have failed.
+func (s *InMemoryStore) List() ([]DeadLetter, error) {
+	s.deadLettersMu.RLock()
+	defer s.deadLettersMu.RUnlock()
+
+	deadLetters := make([]DeadLetter, 0, len(s.deadLetters))
+	for _, d := range s.deadLetters {
+		deadLetters = append(deadLetters, d)
+	}
+
+	sort.SliceStable(deadLetters, func(i, j int) bool {
+		if !deadLetters[i].FailedAt.Equal(deadLetters[j].FailedAt) {
+			return deadLetters[i].FailedAt.Before(deadLetters[j].FailedAt)
+		}
+		return deadLetters[i].ID < deadLetters[j].ID
+	})
+	return deadLetters, nil
+}
+
+// Remove implements Store interface.
+func (s *InMemoryStore) Remove(ID string) error {
+	s.deadLettersMu.Lock()
+	defer s.deadLettersMu.Unlock()
+
+	if _, ok := s.deadLetters[ID]; !ok {
+		return ErrDeadLetterNotFound
+	}
+
+	delete(s.deadLetters, ID)
+	return nil
+}
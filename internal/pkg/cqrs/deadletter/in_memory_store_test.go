@@ -0,0 +1,91 @@
+package deadletter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/deadletter"
+)
+
+// ensure that InMemoryStore implements deadletter.Store interface.
+var _ deadletter.Store = (*deadletter.InMemoryStore)(nil)
+
+func TestNewInMemoryStore(t *testing.T) {
+	t.Run("ItCreatesNewInstance", func(t *testing.T) {
+		assert.True(t, deadletter.NewInMemoryStore() != nil)
+	})
+}
+
+func TestInMemoryStoreGet(t *testing.T) {
+	t.Run("ItFailsIfTheDeadLetterIsNotFound", func(t *testing.T) {
+		// arrange
+		s := deadletter.NewInMemoryStore()
+
+		// act
+		_, err := s.Get("d1")
+
+		// assert
+		assert.Equals(t, deadletter.ErrDeadLetterNotFound, err)
+	})
+
+	t.Run("ItReturnsTheStoredDeadLetter", func(t *testing.T) {
+		// arrange
+		s := deadletter.NewInMemoryStore()
+		want := deadletter.DeadLetter{ID: "d1", Handler: "Test", Attempts: 3}
+		assert.Ok(t, s.Put(want))
+
+		// act
+		got, err := s.Get("d1")
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, got)
+	})
+}
+
+func TestInMemoryStoreList(t *testing.T) {
+	t.Run("ItListsTheDeadLettersInTheOrderTheyHaveFailed", func(t *testing.T) {
+		// arrange
+		now := time.Now()
+		s := deadletter.NewInMemoryStore()
+		assert.Ok(t, s.Put(deadletter.DeadLetter{ID: "a", FailedAt: now.Add(time.Second)}))
+		assert.Ok(t, s.Put(deadletter.DeadLetter{ID: "b", FailedAt: now}))
+
+		// act
+		got, err := s.List()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 2, len(got))
+		assert.Equals(t, "b", got[0].ID)
+		assert.Equals(t, "a", got[1].ID)
+	})
+}
+
+func TestInMemoryStoreRemove(t *testing.T) {
+	t.Run("ItFailsIfTheDeadLetterIsNotFound", func(t *testing.T) {
+		// arrange
+		s := deadletter.NewInMemoryStore()
+
+		// act
+		err := s.Remove("d1")
+
+		// assert
+		assert.Equals(t, deadletter.ErrDeadLetterNotFound, err)
+	})
+
+	t.Run("ItRemovesTheDeadLetter", func(t *testing.T) {
+		// arrange
+		s := deadletter.NewInMemoryStore()
+		assert.Ok(t, s.Put(deadletter.DeadLetter{ID: "d1"}))
+
+		// act
+		err := s.Remove("d1")
+
+		// assert
+		assert.Ok(t, err)
+		_, err = s.Get("d1")
+		assert.Equals(t, deadletter.ErrDeadLetterNotFound, err)
+	})
+}
@@ -0,0 +1,95 @@
+package deadletter
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Queue records the events which handlers have failed to handle and lets them be inspected, replayed or discarded.
+type Queue struct {
+	store Store
+
+	handlers   map[string]domain.EventHandler
+	handlersMu sync.RWMutex
+}
+
+// NewQueue creates a new instance of Queue.
+func NewQueue(store Store) *Queue {
+	if store == nil {
+		panic("store is required")
+	}
+
+	return &Queue{
+		store:    store,
+		handlers: make(map[string]domain.EventHandler),
+	}
+}
+
+// RegisterHandler registers the handler which replays the dead letters of the given name.
+func (q *Queue) RegisterHandler(name string, h domain.EventHandler) {
+	q.handlersMu.Lock()
+	defer q.handlersMu.Unlock()
+	q.handlers[name] = h
+}
+
+// Add records the event the given handler has failed to handle.
+func (q *Queue) Add(handler string, e domain.Envelope, err error, attempts int) error {
+	return q.store.Put(DeadLetter{
+		ID:       ksuid.New().String(),
+		Handler:  handler,
+		Envelope: e,
+		Error:    err.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now().UTC(),
+	})
+}
+
+// List returns all the dead letters ordered by the time they have failed.
+func (q *Queue) List() ([]DeadLetter, error) {
+	return q.store.List()
+}
+
+// Replay hands the dead letter to its handler again.
+//
+// The dead letter is removed if the handler succeeds, otherwise it is kept with the new error and attempt count.
+func (q *Queue) Replay(ID string) error {
+	d, err := q.store.Get(ID)
+	if err != nil {
+		return err
+	}
+
+	h, ok := q.handler(d.Handler)
+	if !ok {
+		return errors.New("handler " + d.Handler + " is not registered")
+	}
+
+	if handleErr := h.Handle(d.Envelope); handleErr != nil {
+		d.Error = handleErr.Error()
+		d.Attempts++
+		d.FailedAt = time.Now().UTC()
+		if err := q.store.Put(d); err != nil {
+			return err
+		}
+		return handleErr
+	}
+
+	return q.store.Remove(ID)
+}
+
+// Discard removes the dead letter without handling it.
+func (q *Queue) Discard(ID string) error {
+	return q.store.Remove(ID)
+}
+
+func (q *Queue) handler(name string) (domain.EventHandler, bool) {
+	q.handlersMu.RLock()
+	defer q.handlersMu.RUnlock()
+
+	h, ok := q.handlers[name]
+	return h, ok
+}
@@ -0,0 +1,135 @@
+package deadletter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/deadletter"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestNewQueue(t *testing.T) {
+	t.Run("ItPanicsIfStoreIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			deadletter.NewQueue(nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestQueueAdd(t *testing.T) {
+	t.Run("ItRecordsTheFailedEvent", func(t *testing.T) {
+		// arrange
+		q := deadletter.NewQueue(deadletter.NewInMemoryStore())
+		e := domain.Envelope{ID: "e1", Event: mock.SomethingHappened{}}
+
+		// act
+		err := q.Add("Test", e, mock.ErrCannotHandleEvent, 3)
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := q.List()
+		assert.Ok(t, err)
+		assert.Equals(t, 1, len(got))
+		assert.True(t, got[0].ID != "")
+		assert.True(t, !got[0].FailedAt.IsZero())
+		assert.Equals(t, "Test", got[0].Handler)
+		assert.Equals(t, e, got[0].Envelope)
+		assert.Equals(t, mock.ErrCannotHandleEvent.Error(), got[0].Error)
+		assert.Equals(t, 3, got[0].Attempts)
+	})
+}
+
+func TestQueueReplay(t *testing.T) {
+	t.Run("ItFailsIfTheDeadLetterIsNotFound", func(t *testing.T) {
+		// arrange
+		q := deadletter.NewQueue(deadletter.NewInMemoryStore())
+
+		// act
+		err := q.Replay("d1")
+
+		// assert
+		assert.Equals(t, deadletter.ErrDeadLetterNotFound, err)
+	})
+
+	t.Run("ItFailsIfTheHandlerIsNotRegistered", func(t *testing.T) {
+		// arrange
+		q := deadletter.NewQueue(deadletter.NewInMemoryStore())
+		ID := addDeadLetter(t, q)
+
+		// act
+		err := q.Replay(ID)
+
+		// assert
+		assert.Equals(t, errors.New("handler Test is not registered"), err)
+	})
+
+	t.Run("ItRemovesTheDeadLetterIfTheHandlerSucceeds", func(t *testing.T) {
+		// arrange
+		handler := &mock.EventHandlerMock{}
+		q := deadletter.NewQueue(deadletter.NewInMemoryStore())
+		q.RegisterHandler("Test", handler)
+		ID := addDeadLetter(t, q)
+
+		// act
+		err := q.Replay(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, handler.Happened)
+
+		got, err := q.List()
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+
+	t.Run("ItKeepsTheDeadLetterIfTheHandlerFailsAgain", func(t *testing.T) {
+		// arrange
+		q := deadletter.NewQueue(deadletter.NewInMemoryStore())
+		q.RegisterHandler("Test", &mock.EventHandlerMock{Err: mock.ErrCannotHandleEvent})
+		ID := addDeadLetter(t, q)
+
+		// act
+		err := q.Replay(ID)
+
+		// assert
+		assert.Equals(t, mock.ErrCannotHandleEvent, err)
+
+		got, err := q.List()
+		assert.Ok(t, err)
+		assert.Equals(t, 1, len(got))
+		assert.Equals(t, 2, got[0].Attempts)
+	})
+}
+
+func TestQueueDiscard(t *testing.T) {
+	t.Run("ItRemovesTheDeadLetter", func(t *testing.T) {
+		// arrange
+		q := deadletter.NewQueue(deadletter.NewInMemoryStore())
+		ID := addDeadLetter(t, q)
+
+		// act
+		err := q.Discard(ID)
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := q.List()
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+func addDeadLetter(t *testing.T, q *deadletter.Queue) string {
+	t.Helper()
+
+	assert.Ok(t, q.Add("Test", domain.Envelope{Event: mock.SomethingHappened{}}, mock.ErrCannotHandleEvent, 1))
+
+	deadLetters, err := q.List()
+	assert.Ok(t, err)
+	return deadLetters[0].ID
+}
@@ -0,0 +1,137 @@
+package dedupstore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+const (
+	// DefaultWindow is how long the results are remembered by default.
+	DefaultWindow = time.Hour
+	// DefaultCapacity is how many results are remembered at most by default.
+	DefaultCapacity = 100000
+)
+
+// Option configures InMemoryDedupStore.
+type Option func(*InMemoryDedupStore)
+
+// WithWindow sets how long the results are remembered.
+func WithWindow(window time.Duration) Option {
+	if window <= 0 {
+		panic("window must be positive")
+	}
+
+	return func(s *InMemoryDedupStore) {
+		s.window = window
+	}
+}
+
+// WithCapacity sets how many results are remembered at most.
+func WithCapacity(capacity int) Option {
+	if capacity <= 0 {
+		panic("capacity must be positive")
+	}
+
+	return func(s *InMemoryDedupStore) {
+		s.capacity = capacity
+	}
+}
+
+// WithClock sets the function which tells the current time.
+func WithClock(now func() time.Time) Option {
+	if now == nil {
+		panic("now is required")
+	}
+
+	return func(s *InMemoryDedupStore) {
+		s.now = now
+	}
+}
+
+// InMemoryDedupStore remembers the results of the processed commands in memory.
+//
+// The results are remembered within the dedup window, DefaultWindow unless configured otherwise.
+// Once the capacity is reached, the oldest results are forgotten before their window is over.
+// A command which is sent again after its result is forgotten is executed again.
+type InMemoryDedupStore struct {
+	window   time.Duration
+	capacity int
+	now      func() time.Time
+
+	results   map[string][]domain.DomainEvent
+	order     []storedResult
+	resultsMu sync.RWMutex
+}
+
+// storedResult tells when the result of the command has been stored.
+type storedResult struct {
+	commandID string
+	storedAt  time.Time
+}
+
+// NewInMemoryDedupStore creates a new instance of InMemoryDedupStore.
+func NewInMemoryDedupStore(opts ...Option) *InMemoryDedupStore {
+	s := &InMemoryDedupStore{
+		window:   DefaultWindow,
+		capacity: DefaultCapacity,
+		now:      time.Now,
+		results:  make(map[string][]domain.DomainEvent),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// LoadCommandResult implements domain.DedupStore interface.
+func (s *InMemoryDedupStore) LoadCommandResult(commandID string) ([]domain.DomainEvent, bool, error) {
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+
+	s.evict()
+
+	events, ok := s.results[commandID]
+	if !ok {
+		return nil, false, nil
+	}
+	return append([]domain.DomainEvent(nil), events...), true, nil
+}
+
+// StoreCommandResult implements domain.DedupStore interface.
+//
+// The result of a command is recorded once, the later ones are ignored.
+func (s *InMemoryDedupStore) StoreCommandResult(commandID string, events []domain.DomainEvent) error {
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+
+	s.evict()
+
+	if _, ok := s.results[commandID]; ok {
+		return nil
+	}
+
+	s.results[commandID] = append([]domain.DomainEvent{}, events...)
+	s.order = append(s.order, storedResult{commandID: commandID, storedAt: s.now()})
+
+	for len(s.order) > s.capacity {
+		s.forgetOldest()
+	}
+	return nil
+}
+
+// evict forgets the results which are older than the window.
+func (s *InMemoryDedupStore) evict() {
+	expiredBefore := s.now().Add(-s.window)
+	for len(s.order) > 0 && !s.order[0].storedAt.After(expiredBefore) {
+		s.forgetOldest()
+	}
+}
+
+func (s *InMemoryDedupStore) forgetOldest() {
+	delete(s.results, s.order[0].commandID)
+	s.order[0] = storedResult{}
+	s.order = s.order[1:]
+}
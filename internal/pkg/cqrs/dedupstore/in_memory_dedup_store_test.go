@@ -0,0 +1,141 @@
+package dedupstore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/dedupstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that dedup store implements domain.DedupStore interface.
+var _ domain.DedupStore = (*dedupstore.InMemoryDedupStore)(nil)
+
+func TestNewInMemoryDedupStore(t *testing.T) {
+	t.Run("ItCreatesDedupStore", func(t *testing.T) {
+		assert.True(t, dedupstore.NewInMemoryDedupStore() != nil)
+	})
+
+	t.Run("ItPanicsIfTheWindowIsNotPositive", func(t *testing.T) {
+		factory := func() {
+			dedupstore.WithWindow(0)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfTheCapacityIsNotPositive", func(t *testing.T) {
+		factory := func() {
+			dedupstore.WithCapacity(0)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfTheClockIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			dedupstore.WithClock(nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestInMemoryDedupStoreLoadCommandResult(t *testing.T) {
+	t.Run("ItReportsAnUnknownCommand", func(t *testing.T) {
+		// arrange
+		s := dedupstore.NewInMemoryDedupStore()
+
+		// act
+		_, ok, err := s.LoadCommandResult("c1")
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, !ok)
+	})
+
+	t.Run("ItLoadsTheStoredResult", func(t *testing.T) {
+		// arrange
+		s := dedupstore.NewInMemoryDedupStore()
+		want := []domain.DomainEvent{mock.SomethingHappened{}}
+		assert.Ok(t, s.StoreCommandResult("c1", want))
+
+		// act
+		got, ok, err := s.LoadCommandResult("c1")
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, ok)
+		assert.Equals(t, want, got)
+	})
+}
+
+func TestInMemoryDedupStoreStoreCommandResult(t *testing.T) {
+	t.Run("ItKeepsTheFirstResult", func(t *testing.T) {
+		// arrange
+		s := dedupstore.NewInMemoryDedupStore()
+		want := []domain.DomainEvent{mock.SomethingHappened{}}
+		assert.Ok(t, s.StoreCommandResult("c1", want))
+
+		// act
+		err := s.StoreCommandResult("c1", []domain.DomainEvent{mock.SomethingElseHappened{}})
+
+		// assert
+		assert.Ok(t, err)
+		got, _, _ := s.LoadCommandResult("c1")
+		assert.Equals(t, want, got)
+	})
+
+	t.Run("ItRemembersACommandWithoutEvents", func(t *testing.T) {
+		// arrange
+		s := dedupstore.NewInMemoryDedupStore()
+
+		// act
+		err := s.StoreCommandResult("c1", nil)
+
+		// assert
+		assert.Ok(t, err)
+		got, ok, _ := s.LoadCommandResult("c1")
+		assert.True(t, ok)
+		assert.Equals(t, 0, len(got))
+	})
+	t.Run("ItForgetsTheResultsOutsideTheWindow", func(t *testing.T) {
+		// arrange
+		now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := dedupstore.NewInMemoryDedupStore(
+			dedupstore.WithWindow(time.Minute),
+			dedupstore.WithClock(func() time.Time { return now }),
+		)
+		assert.Ok(t, s.StoreCommandResult("c1", nil))
+
+		// act
+		now = now.Add(59 * time.Second)
+		_, remembered, _ := s.LoadCommandResult("c1")
+
+		now = now.Add(time.Second)
+		_, forgotten, _ := s.LoadCommandResult("c1")
+
+		// assert
+		assert.True(t, remembered)
+		assert.True(t, !forgotten)
+	})
+
+	t.Run("ItForgetsTheOldestResultsOnceTheCapacityIsReached", func(t *testing.T) {
+		// arrange
+		s := dedupstore.NewInMemoryDedupStore(dedupstore.WithCapacity(2))
+		assert.Ok(t, s.StoreCommandResult("c1", nil))
+		assert.Ok(t, s.StoreCommandResult("c2", nil))
+
+		// act
+		err := s.StoreCommandResult("c3", nil)
+
+		// assert
+		assert.Ok(t, err)
+		_, ok, _ := s.LoadCommandResult("c1")
+		assert.True(t, !ok)
+		_, ok, _ = s.LoadCommandResult("c2")
+		assert.True(t, ok)
+		_, ok, _ = s.LoadCommandResult("c3")
+		assert.True(t, ok)
+	})
+}
@@ -1,6 +1,74 @@
 package dispatcher
 
-import "github.com/screwyprof/roshambo/pkg/domain"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/retry"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ConflictError is returned when a command keeps conflicting with concurrent changes
+// after all the attempts allowed by the conflict retry policy.
+type ConflictError struct {
+	CommandType string
+	Attempts    int
+	Err         error
+}
+
+// Error implements error interface.
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("%s command failed after %d attempt(s): %v", e.CommandType, e.Attempts, e.Err)
+}
+
+// Unwrap returns the last conflict error.
+func (e ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures Dispatcher.
+type Option func(*Dispatcher)
+
+// WithAggregateSerialization makes the dispatcher handle the commands for the same aggregate one at a time.
+//
+// The commands for different aggregates are still handled in parallel.
+// It avoids most of the concurrency conflicts within a single dispatcher,
+// but the event store still has to detect the conflicts with the other processes.
+func WithAggregateSerialization() Option {
+	return func(d *Dispatcher) {
+		d.aggregateLocks = newShardedLock()
+	}
+}
+
+// WithDeduplication makes the dispatcher execute every identified command once.
+//
+// A command whose ID has already been processed is not executed again,
+// the events it originally produced are returned instead. Commands without ID are always executed.
+// A command is recognized only while the dedup store keeps its result, i.e. within the dedup window of the store.
+func WithDeduplication(dedupStore domain.DedupStore) Option {
+	if dedupStore == nil {
+		panic("dedupStore is required")
+	}
+
+	return func(d *Dispatcher) {
+		d.dedupStore = dedupStore
+	}
+}
+
+// WithConflictRetry makes the dispatcher reload the aggregate and handle the command again
+// if the aggregate has been changed concurrently.
+//
+// The attempts are delayed according to the policy. Once they are exhausted, a ConflictError is returned.
+func WithConflictRetry(policy retry.Policy) Option {
+	return func(d *Dispatcher) {
+		d.conflictRetry = &policy
+	}
+}
 
 // Dispatcher is a basic message dispatcher.
 //
@@ -8,13 +76,26 @@ import "github.com/screwyprof/roshambo/pkg/domain"
 // It is suitable for a simple, single node application that can safely build its subscriber list
 // at startup and keep it in memory.
 // Depends on some kind of event storage mechanism.
+//
+// Given an outbox relay as the event publisher, a command succeeds as soon as its events are stored,
+// and the events are published by the relay at least once.
+//
+// Cross-cutting concerns such as logging or authorization are added by wrapping it with middleware.Chain.
 type Dispatcher struct {
-	store          domain.AggregateStore
-	eventPublisher domain.EventPublisher
+	store          domain.ContextAggregateStore
+	eventPublisher domain.ContextEventPublisher
+	conflictRetry  *retry.Policy
+
+	dedupStore domain.DedupStore
+	dedupLocks *shardedLock
+	inFlight   *inFlight
+
+	aggregateLocks *shardedLock
 }
 
 // NewDispatcher creates a new instance of Dispatcher.
-func NewDispatcher(aggregateStore domain.AggregateStore, eventPublisher domain.EventPublisher) *Dispatcher {
+func NewDispatcher(
+	aggregateStore domain.AggregateStore, eventPublisher domain.EventPublisher, opts ...Option) *Dispatcher {
 	if aggregateStore == nil {
 		panic("aggregateStore is required")
 	}
@@ -23,42 +104,235 @@ func NewDispatcher(aggregateStore domain.AggregateStore, eventPublisher domain.E
 		panic("eventPublisher is required")
 	}
 
-	return &Dispatcher{
-		store:          aggregateStore,
-		eventPublisher: eventPublisher,
+	d := &Dispatcher{
+		store:          domain.ContextAggregateStoreOf(aggregateStore),
+		eventPublisher: domain.ContextEventPublisherOf(eventPublisher),
+		dedupLocks:     newShardedLock(),
+		inFlight:       newInFlight(),
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
 }
 
 // Handle implements domain.CommandHandler interface.
 func (d *Dispatcher) Handle(c domain.Command) ([]domain.DomainEvent, error) {
-	agg, err := d.store.Load(c.AggregateID(), c.AggregateType())
+	return d.HandleContext(context.Background(), c)
+}
+
+// HandleContext implements domain.ContextCommandHandler interface.
+//
+// The correlation and causation IDs carried by the context are recorded in the envelopes.
+func (d *Dispatcher) HandleContext(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+	commandID := domain.CommandIDOf(c)
+	if d.dedupStore != nil && commandID != "" {
+		return d.handleOnce(ctx, commandID, c)
+	}
+
+	envelopes, err := d.handleWithConflictRetry(ctx, c)
 	if err != nil {
 		return nil, err
 	}
 
-	events, err := agg.Handle(c)
+	return d.publish(ctx, envelopes)
+}
+
+// handleOnce handles the command unless it has been processed already.
+//
+// The result is recorded as soon as the events are stored, so the command is not executed again
+// even if publishing its events fails. The command is released before the events are published.
+func (d *Dispatcher) handleOnce(ctx context.Context, commandID string, c domain.Command) ([]domain.DomainEvent, error) {
+	events, release, err := d.claim(ctx, commandID)
+	if err != nil || release == nil {
+		return events, err
+	}
+
+	envelopes, err := d.handleWithConflictRetry(ctx, c)
 	if err != nil {
+		release()
 		return nil, err
 	}
 
-	err = d.storeAndPublishEvents(agg, events...)
+	// the events are stored already, so failing to remember the result doesn't fail the command.
+	_ = d.dedupStore.StoreCommandResult(commandID, domain.EventsOf(envelopes))
+	release()
+
+	return d.publish(ctx, envelopes)
+}
+
+// claim marks the command in flight unless it has been processed already, in which case its events are returned.
+//
+// The command is locked only while its result is checked, a duplicate of the command in flight waits for it.
+// The returned function releases the claimed command, it is nil if the command has been processed.
+func (d *Dispatcher) claim(ctx context.Context, commandID string) ([]domain.DomainEvent, func(), error) {
+	for {
+		unlock, err := d.dedupLocks.lock(ctx, commandID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if done, ok := d.inFlight.done(commandID); ok {
+			unlock()
+
+			select {
+			case <-done:
+				continue
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			}
+		}
+
+		events, processed, err := d.dedupStore.LoadCommandResult(commandID)
+		if err != nil || processed {
+			unlock()
+			return events, nil, err
+		}
+
+		release := d.inFlight.add(commandID)
+		unlock()
+
+		return nil, release, nil
+	}
+}
+
+func (d *Dispatcher) handleWithConflictRetry(ctx context.Context, c domain.Command) ([]domain.Envelope, error) {
+	if d.conflictRetry == nil {
+		return d.handleAndStore(ctx, c)
+	}
+
+	for attempt := 1; ; attempt++ {
+		envelopes, err := d.handleAndStore(ctx, c)
+		if !errors.Is(err, domain.ErrConcurrencyViolation) {
+			return envelopes, err
+		}
+
+		if attempt >= d.conflictRetry.MaxAttempts {
+			return nil, ConflictError{CommandType: c.CommandType(), Attempts: attempt, Err: err}
+		}
+
+		if err := sleep(ctx, d.conflictRetry.Delay(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// handleAndStore handles the command by the aggregate and stores the events it produced.
+//
+// The aggregate is unlocked before the events are published,
+// so the subscribers may dispatch commands for the same aggregate synchronously.
+func (d *Dispatcher) handleAndStore(ctx context.Context, c domain.Command) ([]domain.Envelope, error) {
+	if d.aggregateLocks != nil {
+		unlock, err := d.aggregateLocks.lock(ctx, c.AggregateType()+"/"+c.AggregateID().String())
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	agg, err := d.store.LoadContext(ctx, c.AggregateID(), c.AggregateType())
 	if err != nil {
 		return nil, err
 	}
 
-	return events, nil
-}
+	events, err := domain.ContextCommandHandlerOf(agg).HandleContext(ctx, c)
+	if err != nil {
+		return nil, err
+	}
 
-func (d *Dispatcher) storeAndPublishEvents(aggregate domain.AdvancedAggregate, events ...domain.DomainEvent) error {
-	err := d.store.Store(aggregate, events...)
+	envelopes := d.wrap(ctx, c, agg, events)
+	err = d.store.StoreContext(ctx, agg, envelopes...)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return envelopes, nil
+}
+
+// wrap puts the events produced by a command into envelopes.
+func (d *Dispatcher) wrap(
+	ctx context.Context, c domain.Command, agg domain.AdvancedAggregate, events []domain.DomainEvent) []domain.Envelope {
+	commandID := domain.CommandIDOf(c)
+	if commandID == "" {
+		commandID = ksuid.New().String()
+	}
+	recordedAt := time.Now().UTC()
+
+	correlationID, ok := domain.CorrelationIDFrom(ctx)
+	if !ok {
+		correlationID = commandID
 	}
 
-	err = d.eventPublisher.Publish(events...)
+	causationID, ok := domain.CausationIDFrom(ctx)
+	if !ok {
+		causationID = commandID
+	}
+
+	envelopes := make([]domain.Envelope, 0, len(events))
+	for _, e := range events {
+		envelopes = append(envelopes, domain.Envelope{
+			ID:            ksuid.New().String(),
+			AggregateID:   agg.AggregateID().String(),
+			AggregateType: agg.AggregateType(),
+			RecordedAt:    recordedAt,
+			CommandID:     commandID,
+			CausationID:   causationID,
+			CorrelationID: correlationID,
+			Event:         e,
+		})
+	}
+	return envelopes
+}
+
+// publish publishes the stored events and returns them.
+//
+// Once the events are stored, the command has happened, so they are published even if the context is done meanwhile.
+func (d *Dispatcher) publish(ctx context.Context, envelopes []domain.Envelope) ([]domain.DomainEvent, error) {
+	err := d.eventPublisher.PublishContext(detach(ctx), envelopes...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	return domain.EventsOf(envelopes), nil
+}
+
+// detachedContext carries the values of its parent, but is never done.
+type detachedContext struct {
+	parent context.Context
+}
+
+// detach returns a context which carries the values of the given one, e.g. the correlation ID,
+// but is neither cancelled nor timed out with it.
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
 	return nil
 }
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// sleep waits for the given delay unless the context is done earlier.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
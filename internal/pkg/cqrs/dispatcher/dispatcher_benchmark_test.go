@@ -0,0 +1,148 @@
+package dispatcher_test
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/aggregate"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/dispatcher"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventbus"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/retry"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/snapshotstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/store"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// BenchmarkDispatcherHandle compares the throughput of the parallel commands
+// with and without the per-aggregate serialization.
+//
+// The commands either contend for a single aggregate or are spread over many ones.
+// The aggregates are snapshotted, so that the growing streams don't dominate the results.
+// Without serialization the conflicting commands are retried, the number of conflicts is logged.
+func BenchmarkDispatcherHandle(b *testing.B) {
+	benchmarks := []struct {
+		name       string
+		aggregates int
+		opts       []dispatcher.Option
+	}{
+		{"SingleAggregate/Unserialized", 1, nil},
+		{"SingleAggregate/Serialized", 1, []dispatcher.Option{dispatcher.WithAggregateSerialization()}},
+		{"ManyAggregates/Unserialized", 1024, nil},
+		{"ManyAggregates/Serialized", 1024, []dispatcher.Option{dispatcher.WithAggregateSerialization()}},
+	}
+
+	for _, bm := range benchmarks {
+		bm := bm
+		b.Run(bm.name, func(b *testing.B) {
+			var conflicts int64
+			es := &conflictCountingEventStore{EventStore: eventstore.NewInInMemoryEventStore(), conflicts: &conflicts}
+
+			opts := append([]dispatcher.Option{dispatcher.WithConflictRetry(retry.Policy{MaxAttempts: 1000})}, bm.opts...)
+			aggregateStore := store.NewStore(
+				es,
+				createCounterFactory(),
+				store.WithSnapshots(snapshotstore.NewInMemorySnapshotStore(), store.SnapshotEvery(10)),
+			)
+			d := dispatcher.NewDispatcher(aggregateStore, eventbus.NewInMemoryEventBus(), opts...)
+
+			var next int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					ID := mock.StringIdentifier(strconv.FormatInt(atomic.AddInt64(&next, 1)%int64(bm.aggregates), 10))
+					if _, err := d.Handle(incrementCounter{ID: ID}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.StopTimer()
+
+			b.Logf("%d command(s), %d conflict(s)", b.N, atomic.LoadInt64(&conflicts))
+		})
+	}
+}
+
+type conflictCountingEventStore struct {
+	domain.EventStore
+	conflicts *int64
+}
+
+func (s *conflictCountingEventStore) StoreEventsFor(ID domain.Identifier, version int, events []domain.Envelope) error {
+	err := s.EventStore.StoreEventsFor(ID, version, events)
+	if err == domain.ErrConcurrencyViolation {
+		atomic.AddInt64(s.conflicts, 1)
+	}
+	return err
+}
+
+type incrementCounter struct {
+	ID domain.Identifier
+}
+
+func (c incrementCounter) AggregateID() domain.Identifier {
+	return c.ID
+}
+
+func (c incrementCounter) AggregateType() string {
+	return "dispatcher_test.counter"
+}
+
+func (c incrementCounter) CommandType() string {
+	return "IncrementCounter"
+}
+
+type counterIncremented struct{}
+
+func (e counterIncremented) EventType() string {
+	return "CounterIncremented"
+}
+
+type counter struct {
+	ID    domain.Identifier
+	value int
+}
+
+func (a *counter) AggregateID() domain.Identifier {
+	return a.ID
+}
+
+func (a *counter) AggregateType() string {
+	return "dispatcher_test.counter"
+}
+
+func (a *counter) IncrementCounter(c incrementCounter) ([]domain.DomainEvent, error) {
+	return []domain.DomainEvent{counterIncremented{}}, nil
+}
+
+func (a *counter) OnCounterIncremented(e counterIncremented) {
+	a.value++
+}
+
+func (a *counter) SnapshotState() (interface{}, error) {
+	return a.value, nil
+}
+
+func (a *counter) RestoreState(state interface{}) error {
+	a.value = state.(int)
+	return nil
+}
+
+func createCounterFactory() *aggregate.Factory {
+	f := aggregate.NewFactory()
+	f.RegisterAggregate(func(ID domain.Identifier) domain.AdvancedAggregate {
+		pureAgg := &counter{ID: ID}
+
+		commandHandler := aggregate.NewCommandHandler()
+		commandHandler.RegisterHandlers(pureAgg)
+
+		eventApplier := aggregate.NewEventApplier()
+		eventApplier.RegisterAppliers(pureAgg)
+
+		return aggregate.NewAdvanced(pureAgg, commandHandler, eventApplier)
+	})
+	return f
+}
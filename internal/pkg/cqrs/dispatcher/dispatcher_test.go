@@ -1,14 +1,21 @@
 package dispatcher_test
 
 import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/segmentio/ksuid"
 
 	"github.com/screwyprof/roshambo/internal/pkg/assert"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/aggregate"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/dedupstore"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/dispatcher"
 	. "github.com/screwyprof/roshambo/internal/pkg/cqrs/dispatcher/testdata/fixture"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/retry"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
 
 	"github.com/screwyprof/roshambo/pkg/domain"
@@ -17,6 +24,9 @@ import (
 // ensure that Dispatcher  implements domain.CommandHandler interface.
 var _ domain.CommandHandler = (*dispatcher.Dispatcher)(nil)
 
+// ensure that Dispatcher implements domain.ContextCommandHandler interface.
+var _ domain.ContextCommandHandler = (*dispatcher.Dispatcher)(nil)
+
 func TestNewDispatcher(t *testing.T) {
 	t.Run("ItPanicsIfAggregateStoreIsNotGiven", func(t *testing.T) {
 		factory := func() {
@@ -90,6 +100,588 @@ func TestNewDispatcherHandle(t *testing.T) {
 			Then(mock.SomethingHappened{}),
 		)
 	})
+
+	t.Run("ItWrapsEventsIntoEnvelopes", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+
+		var published []domain.Envelope
+		publisher := &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				published = e
+				return nil
+			},
+		}
+		d := dispatcher.NewDispatcher(createAggregateStoreMock(createAgg(ID), nil, nil), publisher)
+
+		// act
+		_, err := d.Handle(mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 1, len(published))
+
+		e := published[0]
+		assert.Equals(t, mock.SomethingHappened{}, e.Event)
+		assert.Equals(t, ID.String(), e.AggregateID)
+		assert.Equals(t, mock.TestAggregateType, e.AggregateType)
+		assert.True(t, e.ID != "")
+		assert.True(t, e.CommandID != "")
+		assert.Equals(t, e.CommandID, e.CausationID)
+		assert.Equals(t, e.CommandID, e.CorrelationID)
+		assert.True(t, !e.RecordedAt.IsZero())
+	})
+}
+
+func TestDispatcherHandleContext(t *testing.T) {
+	t.Run("ItFailsIfTheContextIsDone", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		d := createDispatcher(ID)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Equals(t, context.Canceled, err)
+	})
+
+	t.Run("ItRecordsTheCorrelationAndCausationIDsCarriedByTheContext", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+
+		var published []domain.Envelope
+		publisher := &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				published = e
+				return nil
+			},
+		}
+		d := dispatcher.NewDispatcher(createAggregateStoreMock(createAgg(ID), nil, nil), publisher)
+
+		ctx := domain.WithCorrelationID(context.Background(), "correlation")
+		ctx = domain.WithCausationID(ctx, "causation")
+
+		// act
+		_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 1, len(published))
+		assert.Equals(t, "correlation", published[0].CorrelationID)
+		assert.Equals(t, "causation", published[0].CausationID)
+	})
+
+	t.Run("ItPublishesTheStoredEventsEvenIfTheContextIsDoneMeanwhile", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		ctx, cancel := context.WithCancel(domain.WithCorrelationID(context.Background(), "correlation"))
+		defer cancel()
+
+		aggregateStore := &mock.AggregateStoreMock{
+			Loader: func(domain.Identifier, string) (domain.AdvancedAggregate, error) {
+				return createAgg(ID), nil
+			},
+			Saver: func(domain.AdvancedAggregate, ...domain.Envelope) error {
+				cancel()
+				return nil
+			},
+		}
+
+		var published []domain.Envelope
+		publisher := eventPublisherFunc(func(ctx context.Context, e ...domain.Envelope) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			published = e
+			return nil
+		})
+		d := dispatcher.NewDispatcher(aggregateStore, publisher)
+
+		// act
+		events, err := d.HandleContext(ctx, mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+		assert.Equals(t, 1, len(published))
+		assert.Equals(t, "correlation", published[0].CorrelationID)
+	})
+}
+
+func TestDispatcherWithConflictRetry(t *testing.T) {
+	t.Run("ItReloadsTheAggregateAndHandlesTheCommandAgain", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		aggregateStore := createConflictingAggregateStore(ID, 2)
+		d := dispatcher.NewDispatcher(
+			aggregateStore,
+			createEventPublisherMock(nil),
+			dispatcher.WithConflictRetry(retry.Policy{MaxAttempts: 3}),
+		)
+
+		// act
+		events, err := d.Handle(mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+		assert.Equals(t, 3, aggregateStore.loaded)
+	})
+
+	t.Run("ItRetriesTheWrappedConflicts", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		aggregateStore := createConflictingAggregateStore(ID, 1)
+		aggregateStore.err = fmt.Errorf("cannot store the aggregate: %w", domain.ErrConcurrencyViolation)
+
+		d := dispatcher.NewDispatcher(
+			aggregateStore,
+			createEventPublisherMock(nil),
+			dispatcher.WithConflictRetry(retry.Policy{MaxAttempts: 2}),
+		)
+
+		// act
+		events, err := d.Handle(mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+		assert.Equals(t, 2, aggregateStore.loaded)
+	})
+
+	t.Run("ItFailsWithConflictErrorOnceTheAttemptsAreExhausted", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		aggregateStore := createConflictingAggregateStore(ID, 5)
+		d := dispatcher.NewDispatcher(
+			aggregateStore,
+			createEventPublisherMock(nil),
+			dispatcher.WithConflictRetry(retry.Policy{MaxAttempts: 3}),
+		)
+
+		want := dispatcher.ConflictError{
+			CommandType: "MakeSomethingHappen",
+			Attempts:    3,
+			Err:         domain.ErrConcurrencyViolation,
+		}
+
+		// act
+		_, err := d.Handle(mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Equals(t, want, err)
+		assert.Equals(t, 3, aggregateStore.loaded)
+	})
+
+	t.Run("ItDoesNotRetryOtherErrors", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		d := dispatcher.NewDispatcher(
+			createAggregateStoreMock(createAgg(ID), nil, mock.ErrAggregateStoreCannotStoreAggregate),
+			createEventPublisherMock(nil),
+			dispatcher.WithConflictRetry(retry.Policy{MaxAttempts: 3}),
+		)
+
+		// act
+		_, err := d.Handle(mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Equals(t, mock.ErrAggregateStoreCannotStoreAggregate, err)
+	})
+
+	t.Run("ItStopsWaitingOnceTheContextIsDone", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		d := dispatcher.NewDispatcher(
+			createConflictingAggregateStore(ID, 5),
+			createEventPublisherMock(nil),
+			dispatcher.WithConflictRetry(retry.Policy{MaxAttempts: 3, InitialDelay: time.Minute}),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// act
+		_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Equals(t, context.DeadlineExceeded, err)
+	})
+}
+
+func TestDispatcherWithAggregateSerialization(t *testing.T) {
+	t.Run("ItHandlesTheCommandsForTheSameAggregateOneAtATime", func(t *testing.T) {
+		// arrange
+		const commands = 10
+		ID := mock.StringIdentifier("TestAgg1")
+
+		var inFlight, maxInFlight int32
+		aggregateStore := &hookedAggregateStore{onLoad: func(domain.Identifier) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+		}}
+		d := dispatcher.NewDispatcher(aggregateStore, createEventPublisherMock(nil), dispatcher.WithAggregateSerialization())
+
+		// act
+		var wg sync.WaitGroup
+		wg.Add(commands)
+		for i := 0; i < commands; i++ {
+			go func() {
+				defer wg.Done()
+				_, _ = d.Handle(mock.MakeSomethingHappen{AggID: ID})
+			}()
+		}
+		wg.Wait()
+
+		// assert
+		assert.Equals(t, int32(1), atomic.LoadInt32(&maxInFlight))
+	})
+
+	t.Run("ItHandlesTheCommandsForDifferentAggregatesInParallel", func(t *testing.T) {
+		// arrange
+		first, second := mock.StringIdentifier("TestAgg1"), mock.StringIdentifier("TestAgg2")
+
+		secondLoaded := make(chan struct{})
+		aggregateStore := &hookedAggregateStore{onLoad: func(ID domain.Identifier) {
+			if ID == second {
+				close(secondLoaded)
+				return
+			}
+
+			select {
+			case <-secondLoaded:
+			case <-time.After(time.Second):
+				t.Error("the commands for different aggregates are not handled in parallel")
+			}
+		}}
+		d := dispatcher.NewDispatcher(aggregateStore, createEventPublisherMock(nil), dispatcher.WithAggregateSerialization())
+
+		// act
+		done := make(chan error)
+		go func() {
+			_, err := d.Handle(mock.MakeSomethingHappen{AggID: first})
+			done <- err
+		}()
+		_, err := d.Handle(mock.MakeSomethingHappen{AggID: second})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Ok(t, <-done)
+	})
+
+	t.Run("ItStopsWaitingOnceTheContextIsDone", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg1")
+
+		loaded, release := make(chan struct{}), make(chan struct{})
+		aggregateStore := &hookedAggregateStore{onLoad: func(domain.Identifier) {
+			close(loaded)
+			<-release
+		}}
+		d := dispatcher.NewDispatcher(aggregateStore, createEventPublisherMock(nil), dispatcher.WithAggregateSerialization())
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = d.Handle(mock.MakeSomethingHappen{AggID: ID})
+		}()
+		<-loaded
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// act
+		_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{AggID: ID})
+		close(release)
+		<-done
+
+		// assert
+		assert.Equals(t, context.DeadlineExceeded, err)
+	})
+	t.Run("ItLetsTheSubscribersDispatchCommandsForTheSameAggregate", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		aggregateStore := &hookedAggregateStore{onLoad: func(domain.Identifier) {}}
+
+		// the deadline makes a deadlocked subscriber fail instead of hanging.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		var (
+			d          *dispatcher.Dispatcher
+			dispatched int
+		)
+		publisher := eventPublisherFunc(func(_ context.Context, e ...domain.Envelope) error {
+			dispatched++
+			if dispatched > 1 {
+				return nil
+			}
+			_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{AggID: ID})
+			return err
+		})
+		d = dispatcher.NewDispatcher(aggregateStore, publisher, dispatcher.WithAggregateSerialization())
+
+		// act
+		_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 2, dispatched)
+	})
+}
+
+func TestDispatcherWithDeduplication(t *testing.T) {
+	t.Run("ItPanicsIfDedupStoreIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			dispatcher.WithDeduplication(nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItReturnsTheOriginalEventsIfTheCommandHasBeenProcessed", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+
+		var published int
+		publisher := &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				published++
+				return nil
+			},
+		}
+		d := dispatcher.NewDispatcher(
+			createAggregateStoreMock(createAgg(ID), nil, nil),
+			publisher,
+			dispatcher.WithDeduplication(dedupstore.NewInMemoryDedupStore()),
+		)
+
+		_, err := d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+		assert.Ok(t, err)
+
+		// act
+		events, err := d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+		assert.Equals(t, 1, published)
+	})
+
+	t.Run("ItExecutesTheCommandsWithoutID", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		d := dispatcher.NewDispatcher(
+			createAggregateStoreMock(createAgg(ID), nil, nil),
+			createEventPublisherMock(nil),
+			dispatcher.WithDeduplication(dedupstore.NewInMemoryDedupStore()),
+		)
+
+		_, err := d.Handle(mock.MakeSomethingHappen{AggID: ID})
+		assert.Ok(t, err)
+
+		// act
+		_, err = d.Handle(mock.MakeSomethingHappen{AggID: ID})
+
+		// assert
+		assert.Equals(t, mock.ErrItCanHappenOnceOnly, err)
+	})
+
+	t.Run("ItExecutesTheFailedCommandAgain", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		aggregateStore := createConflictingAggregateStore(ID, 1)
+		d := dispatcher.NewDispatcher(
+			aggregateStore,
+			createEventPublisherMock(nil),
+			dispatcher.WithDeduplication(dedupstore.NewInMemoryDedupStore()),
+		)
+
+		_, err := d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+		assert.Equals(t, domain.ErrConcurrencyViolation, err)
+
+		// act
+		events, err := d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+		assert.Equals(t, 2, aggregateStore.loaded)
+	})
+
+	t.Run("ItDoesNotExecuteTheCommandAgainIfPublishingItsEventsFailed", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+
+		var loaded int
+		aggregateStore := &hookedAggregateStore{onLoad: func(domain.Identifier) {
+			loaded++
+		}}
+		d := dispatcher.NewDispatcher(
+			aggregateStore,
+			createEventPublisherMock(mock.ErrCannotPublishEvents),
+			dispatcher.WithDeduplication(dedupstore.NewInMemoryDedupStore()),
+		)
+
+		_, err := d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+		assert.Equals(t, mock.ErrCannotPublishEvents, err)
+
+		// act
+		events, err := d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+		assert.Equals(t, 1, loaded)
+	})
+
+	t.Run("ItLetsTheSubscribersDispatchTheSameCommandAgain", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+
+		// the deadline makes a deadlocked subscriber fail instead of hanging.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		var (
+			d          *dispatcher.Dispatcher
+			dispatched int
+		)
+		publisher := eventPublisherFunc(func(_ context.Context, e ...domain.Envelope) error {
+			dispatched++
+			_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+			return err
+		})
+		d = dispatcher.NewDispatcher(
+			&hookedAggregateStore{onLoad: func(domain.Identifier) {}},
+			publisher,
+			dispatcher.WithDeduplication(dedupstore.NewInMemoryDedupStore()),
+		)
+
+		// act
+		_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 1, dispatched)
+	})
+
+	t.Run("ItDoesNotHoldUpTheOtherCommandsWhileHandlingACommand", func(t *testing.T) {
+		// arrange
+		slow, other := mock.StringIdentifier("TestAgg1"), mock.StringIdentifier("TestAgg2")
+
+		slowLoaded, release := make(chan struct{}), make(chan struct{})
+		aggregateStore := &hookedAggregateStore{onLoad: func(ID domain.Identifier) {
+			if ID == slow {
+				close(slowLoaded)
+				<-release
+			}
+		}}
+		d := dispatcher.NewDispatcher(
+			aggregateStore,
+			createEventPublisherMock(nil),
+			dispatcher.WithDeduplication(dedupstore.NewInMemoryDedupStore()),
+		)
+
+		done := make(chan error)
+		go func() {
+			_, err := d.Handle(mock.MakeSomethingHappen{ID: "c0", AggID: slow})
+			done <- err
+		}()
+		<-slowLoaded
+
+		// the deadline makes a command held up by the slow one fail instead of hanging.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		// act
+		var errs []error
+		for i := 1; i <= 256; i++ {
+			_, err := d.HandleContext(ctx, mock.MakeSomethingHappen{ID: fmt.Sprintf("c%d", i), AggID: other})
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		close(release)
+
+		// assert
+		assert.Equals(t, 0, len(errs))
+		assert.Ok(t, <-done)
+	})
+
+	t.Run("ItHandlesTheConcurrentDuplicatesOnce", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+
+		var loaded int32
+		firstLoaded, release := make(chan struct{}), make(chan struct{})
+		aggregateStore := &hookedAggregateStore{onLoad: func(domain.Identifier) {
+			if atomic.AddInt32(&loaded, 1) == 1 {
+				close(firstLoaded)
+				<-release
+			}
+		}}
+		d := dispatcher.NewDispatcher(
+			aggregateStore,
+			createEventPublisherMock(nil),
+			dispatcher.WithDeduplication(dedupstore.NewInMemoryDedupStore()),
+		)
+
+		done := make(chan error)
+		go func() {
+			_, err := d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+			done <- err
+		}()
+		<-firstLoaded
+
+		// act
+		var events []domain.DomainEvent
+		duplicated := make(chan error)
+		go func() {
+			var err error
+			events, err = d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+			duplicated <- err
+		}()
+		close(release)
+
+		// assert
+		assert.Ok(t, <-done)
+		assert.Ok(t, <-duplicated)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+		assert.Equals(t, int32(1), atomic.LoadInt32(&loaded))
+	})
+
+	t.Run("ItRecordsTheCommandIDInTheEnvelopes", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+
+		var published []domain.Envelope
+		publisher := &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				published = e
+				return nil
+			},
+		}
+		d := dispatcher.NewDispatcher(createAggregateStoreMock(createAgg(ID), nil, nil), publisher)
+
+		// act
+		_, err := d.Handle(mock.MakeSomethingHappen{ID: "c1", AggID: ID})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, "c1", published[0].CommandID)
+	})
 }
 
 type dispatcherOptions struct {
@@ -134,15 +726,7 @@ func createDispatcher(ID domain.Identifier, opts ...option) *dispatcher.Dispatch
 		opt(config)
 	}
 
-	pureAgg := mock.NewTestAggregate(ID)
-
-	commandHandler := aggregate.NewCommandHandler()
-	commandHandler.RegisterHandlers(pureAgg)
-
-	eventApplier := aggregate.NewEventApplier()
-	eventApplier.RegisterAppliers(pureAgg)
-
-	agg := aggregate.NewAdvanced(pureAgg, commandHandler, eventApplier)
+	agg := createAgg(ID)
 	if config.loadedEvents != nil {
 		_ = agg.Apply(config.loadedEvents...)
 	}
@@ -153,12 +737,24 @@ func createDispatcher(ID domain.Identifier, opts ...option) *dispatcher.Dispatch
 	)
 }
 
+func createAgg(ID domain.Identifier) *aggregate.Advanced {
+	pureAgg := mock.NewTestAggregate(ID)
+
+	commandHandler := aggregate.NewCommandHandler()
+	commandHandler.RegisterHandlers(pureAgg)
+
+	eventApplier := aggregate.NewEventApplier()
+	eventApplier.RegisterAppliers(pureAgg)
+
+	return aggregate.NewAdvanced(pureAgg, commandHandler, eventApplier)
+}
+
 func createAggregateStoreMock(want domain.AdvancedAggregate, loadErr error, storeErr error) *mock.AggregateStoreMock {
 	eventStore := &mock.AggregateStoreMock{
 		Loader: func(aggregateID domain.Identifier, aggregateType string) (domain.AdvancedAggregate, error) {
 			return want, loadErr
 		},
-		Saver: func(aggregate domain.AdvancedAggregate, events ...domain.DomainEvent) error {
+		Saver: func(aggregate domain.AdvancedAggregate, events ...domain.Envelope) error {
 			return storeErr
 		},
 	}
@@ -167,9 +763,59 @@ func createAggregateStoreMock(want domain.AdvancedAggregate, loadErr error, stor
 
 func createEventPublisherMock(err error) *mock.EventPublisherMock {
 	eventPublisher := &mock.EventPublisherMock{
-		Publisher: func(e ...domain.DomainEvent) error {
+		Publisher: func(e ...domain.Envelope) error {
 			return err
 		},
 	}
 	return eventPublisher
 }
+
+// conflictingAggregateStore fails to store the aggregate the given number of times.
+type conflictingAggregateStore struct {
+	ID        domain.Identifier
+	conflicts int
+	loaded    int
+	err       error
+}
+
+func (s *conflictingAggregateStore) Load(domain.Identifier, string) (domain.AdvancedAggregate, error) {
+	s.loaded++
+	return createAgg(s.ID), nil
+}
+
+func (s *conflictingAggregateStore) Store(domain.AdvancedAggregate, ...domain.Envelope) error {
+	if s.conflicts > 0 {
+		s.conflicts--
+		return s.err
+	}
+	return nil
+}
+
+func createConflictingAggregateStore(ID domain.Identifier, conflicts int) *conflictingAggregateStore {
+	return &conflictingAggregateStore{ID: ID, conflicts: conflicts, err: domain.ErrConcurrencyViolation}
+}
+
+// hookedAggregateStore calls the hook whenever an aggregate is loaded.
+type hookedAggregateStore struct {
+	onLoad func(ID domain.Identifier)
+}
+
+func (s *hookedAggregateStore) Load(ID domain.Identifier, _ string) (domain.AdvancedAggregate, error) {
+	s.onLoad(ID)
+	return createAgg(ID), nil
+}
+
+func (s *hookedAggregateStore) Store(domain.AdvancedAggregate, ...domain.Envelope) error {
+	return nil
+}
+
+// eventPublisherFunc is a context-aware event publisher.
+type eventPublisherFunc func(ctx context.Context, e ...domain.Envelope) error
+
+func (f eventPublisherFunc) Publish(e ...domain.Envelope) error {
+	return f(context.Background(), e...)
+}
+
+func (f eventPublisherFunc) PublishContext(ctx context.Context, e ...domain.Envelope) error {
+	return f(ctx, e...)
+}
@@ -0,0 +1,41 @@
+package dispatcher
+
+import "sync"
+
+// inFlight tracks the commands being handled, so that their duplicates wait for them to finish.
+type inFlight struct {
+	commands   map[string]chan struct{}
+	commandsMu sync.Mutex
+}
+
+func newInFlight() *inFlight {
+	return &inFlight{
+		commands: make(map[string]chan struct{}),
+	}
+}
+
+// done returns the channel which is closed once the command is not in flight anymore.
+func (f *inFlight) done(commandID string) (<-chan struct{}, bool) {
+	f.commandsMu.Lock()
+	defer f.commandsMu.Unlock()
+
+	done, ok := f.commands[commandID]
+	return done, ok
+}
+
+// add marks the command in flight and returns the function which unmarks it.
+func (f *inFlight) add(commandID string) func() {
+	f.commandsMu.Lock()
+	defer f.commandsMu.Unlock()
+
+	done := make(chan struct{})
+	f.commands[commandID] = done
+
+	return func() {
+		f.commandsMu.Lock()
+		defer f.commandsMu.Unlock()
+
+		delete(f.commands, commandID)
+		close(done)
+	}
+}
@@ -0,0 +1,37 @@
+package dispatcher
+
+import (
+	"context"
+	"hash/fnv"
+)
+
+const lockShards = 64
+
+// shardedLock serializes the work on the same key, while the work on different keys rarely contends.
+type shardedLock struct {
+	shards [lockShards]chan struct{}
+}
+
+func newShardedLock() *shardedLock {
+	l := &shardedLock{}
+	for i := range l.shards {
+		l.shards[i] = make(chan struct{}, 1)
+	}
+	return l
+}
+
+// lock locks the shard of the given key and returns the function which unlocks it.
+//
+// It stops waiting for the shard once the context is done.
+func (l *shardedLock) lock(ctx context.Context, key string) (func(), error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	shard := l.shards[h.Sum32()%lockShards]
+	select {
+	case shard <- struct{}{}:
+		return func() { <-shard }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,176 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+var (
+	// ErrBusClosed happens if events are published after the bus has been closed.
+	ErrBusClosed = errors.New("event bus is closed")
+)
+
+const defaultQueueSize = 64
+
+// AsyncOption configures AsyncEventBus.
+type AsyncOption func(*AsyncEventBus)
+
+// WithQueueSize sets how many events each handler may have queued before publishing blocks.
+func WithQueueSize(size int) AsyncOption {
+	return func(b *AsyncEventBus) {
+		b.queueSize = size
+	}
+}
+
+// WithErrorHandler sets a function which is called when a handler fails to handle an event.
+func WithErrorHandler(onError func(h domain.EventHandler, e domain.Envelope, err error)) AsyncOption {
+	return func(b *AsyncEventBus) {
+		b.onError = onError
+	}
+}
+
+// AsyncEventBus publishes events asynchronously.
+//
+// Every handler has its own bounded queue and worker, so the handlers receive the events in the order
+// they have been published and a slow handler doesn't delay the others. When a queue is full,
+// publishing blocks until the handler catches up.
+//
+// Handler errors don't fail publishing, they are reported to the error handler instead.
+// The handlers outlive the publishing request, so its context is not passed to them.
+type AsyncEventBus struct {
+	queueSize int
+	onError   func(h domain.EventHandler, e domain.Envelope, err error)
+
+	queues     map[domain.EventHandler]chan domain.Envelope
+	closed     bool
+	closing    chan struct{}
+	queuesMu   sync.RWMutex
+	publishers sync.WaitGroup
+	workers    sync.WaitGroup
+}
+
+// NewAsyncEventBus creates a new instance of AsyncEventBus.
+func NewAsyncEventBus(opts ...AsyncOption) *AsyncEventBus {
+	b := &AsyncEventBus{
+		queueSize: defaultQueueSize,
+		onError:   func(domain.EventHandler, domain.Envelope, error) {},
+		queues:    make(map[domain.EventHandler]chan domain.Envelope),
+		closing:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Register registers event handler and starts its worker.
+//
+// Handlers registered after the bus has been closed are ignored.
+func (b *AsyncEventBus) Register(h domain.EventHandler) {
+	b.queuesMu.Lock()
+	defer b.queuesMu.Unlock()
+
+	if _, ok := b.queues[h]; ok || b.closed {
+		return
+	}
+
+	queue := make(chan domain.Envelope, b.queueSize)
+	b.queues[h] = queue
+
+	b.workers.Add(1)
+	go b.work(h, queue)
+}
+
+// Publish implements domain.EventPublisher interface.
+//
+// It returns ErrBusClosed if the bus has been closed.
+func (b *AsyncEventBus) Publish(events ...domain.Envelope) error {
+	return b.PublishContext(context.Background(), events...)
+}
+
+// PublishContext implements domain.ContextEventPublisher interface.
+//
+// It stops waiting for a full queue once the context is done and returns the context error,
+// or once the bus is being closed and returns ErrBusClosed.
+// The events which have been queued by then are still handled.
+//
+// The queues are not locked while waiting, so the handlers may publish events themselves.
+func (b *AsyncEventBus) PublishContext(ctx context.Context, events ...domain.Envelope) error {
+	queues, err := b.acquireQueues()
+	if err != nil {
+		return err
+	}
+	defer b.publishers.Done()
+
+	for h, queue := range queues {
+		matches := h.SubscribedTo()
+		for _, e := range events {
+			if !matches(e.Event) {
+				continue
+			}
+
+			select {
+			case queue <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-b.closing:
+				return ErrBusClosed
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops accepting events and waits until all the queued events are handled.
+func (b *AsyncEventBus) Close() {
+	b.queuesMu.Lock()
+	alreadyClosed := b.closed
+	if !alreadyClosed {
+		b.closed = true
+		close(b.closing)
+	}
+	b.queuesMu.Unlock()
+
+	if !alreadyClosed {
+		// the queues are closed once no one is sending to them anymore
+		b.publishers.Wait()
+		for _, queue := range b.queues {
+			close(queue)
+		}
+	}
+
+	b.workers.Wait()
+}
+
+// acquireQueues returns a snapshot of the queues and registers the caller as a publisher,
+// so that Close doesn't close the queues while they are being sent to.
+func (b *AsyncEventBus) acquireQueues() (map[domain.EventHandler]chan domain.Envelope, error) {
+	b.queuesMu.RLock()
+	defer b.queuesMu.RUnlock()
+
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+
+	queues := make(map[domain.EventHandler]chan domain.Envelope, len(b.queues))
+	for h, queue := range b.queues {
+		queues[h] = queue
+	}
+
+	b.publishers.Add(1)
+	return queues, nil
+}
+
+func (b *AsyncEventBus) work(h domain.EventHandler, queue <-chan domain.Envelope) {
+	defer b.workers.Done()
+
+	for e := range queue {
+		if err := h.Handle(e); err != nil {
+			b.onError(h, e, err)
+		}
+	}
+}
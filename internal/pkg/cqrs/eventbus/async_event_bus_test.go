@@ -0,0 +1,295 @@
+package eventbus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventbus"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that AsyncEventBus implements domain.EventPublisher interface.
+var _ domain.EventPublisher = (*eventbus.AsyncEventBus)(nil)
+
+// ensure that AsyncEventBus implements domain.ContextEventPublisher interface.
+var _ domain.ContextEventPublisher = (*eventbus.AsyncEventBus)(nil)
+
+func TestNewAsyncEventBus(t *testing.T) {
+	t.Run("ItCreatesNewInstance", func(t *testing.T) {
+		assert.True(t, eventbus.NewAsyncEventBus() != nil)
+	})
+}
+
+func TestAsyncEventBusPublish(t *testing.T) {
+	t.Run("ItDeliversEventsToEachHandlerInOrder", func(t *testing.T) {
+		// arrange
+		want := []domain.DomainEvent{mock.SomethingHappened{}, mock.SomethingElseHappened{}, mock.SomethingHappened{}}
+		first, second := &mock.EventHandlerMock{}, &mock.EventHandlerMock{}
+
+		b := eventbus.NewAsyncEventBus()
+		b.Register(first)
+		b.Register(second)
+
+		// act
+		err := b.Publish(mock.Envelopes(want...)...)
+		b.Close()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, first.Happened)
+		assert.Equals(t, want, second.Happened)
+	})
+
+	t.Run("ItHandlesOnlyMatchedEvents", func(t *testing.T) {
+		// arrange
+		eventHandler := &mock.EventHandlerMock{Matcher: domain.MatchEvent("SomethingHappened")}
+
+		b := eventbus.NewAsyncEventBus()
+		b.Register(eventHandler)
+
+		// act
+		err := b.Publish(mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{})...)
+		b.Close()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, eventHandler.Happened)
+	})
+
+	t.Run("ItReportsHandlerErrorsSeparately", func(t *testing.T) {
+		// arrange
+		failing := &mock.EventHandlerMock{Err: mock.ErrCannotHandleEvent}
+		succeeding := &mock.EventHandlerMock{}
+
+		var errs []error
+		b := eventbus.NewAsyncEventBus(eventbus.WithErrorHandler(func(h domain.EventHandler, e domain.Envelope, err error) {
+			errs = append(errs, err)
+		}))
+		b.Register(failing)
+		b.Register(succeeding)
+
+		// act
+		err := b.Publish(mock.Envelopes(mock.SomethingHappened{})...)
+		b.Close()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []error{mock.ErrCannotHandleEvent}, errs)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, succeeding.Happened)
+	})
+
+	t.Run("ItDoesNotWaitForSlowHandlers", func(t *testing.T) {
+		// arrange
+		release := make(chan struct{})
+		slow := &blockingHandler{release: release}
+
+		b := eventbus.NewAsyncEventBus()
+		b.Register(slow)
+		defer b.Close()
+		defer close(release)
+
+		// act
+		err := b.Publish(mock.Envelopes(mock.SomethingHappened{})...)
+
+		// assert
+		assert.Ok(t, err)
+	})
+
+	t.Run("ItBlocksWhenTheQueueIsFull", func(t *testing.T) {
+		// arrange
+		release := make(chan struct{})
+		slow := &blockingHandler{release: release}
+
+		b := eventbus.NewAsyncEventBus(eventbus.WithQueueSize(1))
+		b.Register(slow)
+
+		// the first event is taken by the worker, the second one fills the queue
+		assert.Ok(t, b.Publish(mock.Envelopes(mock.SomethingHappened{}, mock.SomethingHappened{})...))
+
+		// act
+		published := make(chan error)
+		go func() {
+			published <- b.Publish(mock.Envelopes(mock.SomethingHappened{})...)
+		}()
+
+		// assert
+		select {
+		case <-published:
+			t.Fatal("publish is expected to block")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		close(release)
+		assert.Ok(t, <-published)
+		b.Close()
+	})
+
+	t.Run("ItFailsIfTheBusIsClosed", func(t *testing.T) {
+		// arrange
+		b := eventbus.NewAsyncEventBus()
+		b.Close()
+
+		// act
+		err := b.Publish(mock.Envelopes(mock.SomethingHappened{})...)
+
+		// assert
+		assert.Equals(t, eventbus.ErrBusClosed, err)
+	})
+
+	t.Run("ItLetsTheHandlersPublishWhileAnotherPublisherWaits", func(t *testing.T) {
+		// arrange
+		release := make(chan struct{})
+		b := eventbus.NewAsyncEventBus(eventbus.WithQueueSize(1))
+
+		republishing := &republishingHandler{release: release, bus: b, handled: make(chan struct{}, 3)}
+		other := &mock.EventHandlerMock{Matcher: domain.MatchEvent("SomethingElseHappened")}
+		b.Register(republishing)
+		b.Register(other)
+
+		// the first event is taken by the worker, the second one fills the queue
+		assert.Ok(t, b.Publish(mock.Envelopes(mock.SomethingHappened{}, mock.SomethingHappened{})...))
+
+		published := make(chan error)
+		go func() {
+			published <- b.Publish(mock.Envelopes(mock.SomethingHappened{})...)
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		// the registration waits for the blocked publisher
+		go b.Register(&mock.EventHandlerMock{})
+		time.Sleep(10 * time.Millisecond)
+
+		// act
+		close(release)
+
+		// assert
+		select {
+		case err := <-published:
+			assert.Ok(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("publish is expected to complete")
+		}
+
+		for i := 0; i < 3; i++ {
+			<-republishing.handled
+		}
+		b.Close()
+		assert.Equals(t, []error{nil, nil, nil}, republishing.errs)
+		assert.Equals(t, 3, len(other.Happened))
+	})
+}
+
+func TestAsyncEventBusPublishContext(t *testing.T) {
+	t.Run("ItStopsWaitingForAFullQueueOnceTheContextIsDone", func(t *testing.T) {
+		// arrange
+		release := make(chan struct{})
+		slow := &blockingHandler{release: release}
+
+		b := eventbus.NewAsyncEventBus(eventbus.WithQueueSize(1))
+		b.Register(slow)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// act
+		events := mock.Envelopes(mock.SomethingHappened{}, mock.SomethingHappened{}, mock.SomethingHappened{})
+		err := b.PublishContext(ctx, events...)
+		close(release)
+		b.Close()
+
+		// assert
+		assert.Equals(t, context.DeadlineExceeded, err)
+		assert.Equals(t, 2, slow.handled)
+	})
+}
+
+func TestAsyncEventBusClose(t *testing.T) {
+	t.Run("ItDrainsTheQueues", func(t *testing.T) {
+		// arrange
+		release := make(chan struct{})
+		slow := &blockingHandler{release: release}
+
+		b := eventbus.NewAsyncEventBus()
+		b.Register(slow)
+		assert.Ok(t, b.Publish(mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{})...))
+
+		// act
+		close(release)
+		b.Close()
+
+		// assert
+		assert.Equals(t, 2, slow.handled)
+	})
+
+	t.Run("ItStopsThePublishersWaitingForAFullQueue", func(t *testing.T) {
+		// arrange
+		release := make(chan struct{})
+		slow := &blockingHandler{release: release}
+
+		b := eventbus.NewAsyncEventBus(eventbus.WithQueueSize(1))
+		b.Register(slow)
+		assert.Ok(t, b.Publish(mock.Envelopes(mock.SomethingHappened{}, mock.SomethingHappened{})...))
+
+		published := make(chan error)
+		go func() {
+			published <- b.Publish(mock.Envelopes(mock.SomethingHappened{})...)
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		// act
+		closed := make(chan struct{})
+		go func() {
+			b.Close()
+			close(closed)
+		}()
+
+		// assert
+		select {
+		case err := <-published:
+			assert.Equals(t, eventbus.ErrBusClosed, err)
+		case <-time.After(time.Second):
+			t.Fatal("publish is expected to stop waiting")
+		}
+
+		close(release)
+		<-closed
+		assert.Equals(t, 2, slow.handled)
+	})
+}
+
+type blockingHandler struct {
+	release <-chan struct{}
+	handled int
+}
+
+func (h *blockingHandler) SubscribedTo() domain.EventMatcher {
+	return domain.MatchAny()
+}
+
+func (h *blockingHandler) Handle(domain.Envelope) error {
+	<-h.release
+	h.handled++
+	return nil
+}
+
+type republishingHandler struct {
+	release <-chan struct{}
+	bus     *eventbus.AsyncEventBus
+	errs    []error
+	handled chan struct{}
+}
+
+func (h *republishingHandler) SubscribedTo() domain.EventMatcher {
+	return domain.MatchEvent("SomethingHappened")
+}
+
+func (h *republishingHandler) Handle(domain.Envelope) error {
+	<-h.release
+	h.errs = append(h.errs, h.bus.Publish(mock.Envelopes(mock.SomethingElseHappened{})...))
+	h.handled <- struct{}{}
+	return nil
+}
@@ -1,6 +1,7 @@
 package eventbus
 
 import (
+	"context"
 	"sync"
 
 	"github.com/screwyprof/roshambo/pkg/domain"
@@ -28,21 +29,31 @@ func (b *InMemoryEventBus) Register(h domain.EventHandler) {
 }
 
 // Publish implements domain.EventPublisher interface.
-func (b *InMemoryEventBus) Publish(events ...domain.DomainEvent) error {
+//
+// A failing handler doesn't prevent the others from handling the events, the first error is returned.
+func (b *InMemoryEventBus) Publish(events ...domain.Envelope) error {
+	return b.PublishContext(context.Background(), events...)
+}
+
+// PublishContext implements domain.ContextEventPublisher interface.
+//
+// The context is passed to the handlers, the handlers which are not context-aware are skipped once it is done.
+func (b *InMemoryEventBus) PublishContext(ctx context.Context, events ...domain.Envelope) error {
 	b.eventHandlersMu.RLock()
 	defer b.eventHandlersMu.RUnlock()
 
+	var firstErr error
 	for h := range b.eventHandlers {
-		if err := b.handleEvents(h, events...); err != nil {
-			return err
+		if err := b.handleEvents(ctx, h, events...); err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
-	return nil
+	return firstErr
 }
 
-func (b *InMemoryEventBus) handleEvents(h domain.EventHandler, events ...domain.DomainEvent) error {
+func (b *InMemoryEventBus) handleEvents(ctx context.Context, h domain.EventHandler, events ...domain.Envelope) error {
 	for _, e := range events {
-		err := b.handleEventIfMatches(h.SubscribedTo(), h, e)
+		err := b.handleEventIfMatches(ctx, h.SubscribedTo(), h, e)
 		if err != nil {
 			return err
 		}
@@ -51,9 +62,9 @@ func (b *InMemoryEventBus) handleEvents(h domain.EventHandler, events ...domain.
 }
 
 func (b *InMemoryEventBus) handleEventIfMatches(
-	m domain.EventMatcher, h domain.EventHandler, e domain.DomainEvent) error {
-	if !m(e) {
+	ctx context.Context, m domain.EventMatcher, h domain.EventHandler, e domain.Envelope) error {
+	if !m(e.Event) {
 		return nil
 	}
-	return h.Handle(e)
+	return domain.ContextEventHandlerOf(h).HandleContext(ctx, e)
 }
@@ -1,6 +1,7 @@
 package eventbus_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/screwyprof/roshambo/internal/pkg/assert"
@@ -13,6 +14,9 @@ import (
 // ensure that EventBus implements domain.EventPublisher interface.
 var _ domain.EventPublisher = (*eventbus.InMemoryEventBus)(nil)
 
+// ensure that EventBus implements domain.ContextEventPublisher interface.
+var _ domain.ContextEventPublisher = (*eventbus.InMemoryEventBus)(nil)
+
 func TestNewInMemoryEventBus(t *testing.T) {
 	t.Run("ItCreatesNewInstance", func(t *testing.T) {
 		assert.True(t, eventbus.NewInMemoryEventBus() != nil)
@@ -30,10 +34,27 @@ func TestInMemoryEventBus_Publish(t *testing.T) {
 		b.Register(eventHandler)
 
 		// act
-		err := b.Publish(mock.SomethingHappened{}, mock.SomethingElseHappened{})
+		err := b.Publish(mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{})...)
+
+		// assert
+		assert.Equals(t, mock.ErrCannotHandleEvent, err)
+	})
+
+	t.Run("ItPublishesEventsToTheOtherHandlersIfOneFails", func(t *testing.T) {
+		// arrange
+		failing := &mock.EventHandlerMock{Err: mock.ErrCannotHandleEvent}
+		succeeding := &mock.EventHandlerMock{}
+
+		b := eventbus.NewInMemoryEventBus()
+		b.Register(failing)
+		b.Register(succeeding)
+
+		// act
+		err := b.Publish(mock.Envelopes(mock.SomethingHappened{})...)
 
 		// assert
 		assert.Equals(t, mock.ErrCannotHandleEvent, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, succeeding.Happened)
 	})
 
 	t.Run("ItPublishesEvents", func(t *testing.T) {
@@ -45,7 +66,7 @@ func TestInMemoryEventBus_Publish(t *testing.T) {
 		b.Register(eventHandler)
 
 		// act
-		err := b.Publish(mock.SomethingHappened{}, mock.SomethingElseHappened{})
+		err := b.Publish(mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{})...)
 
 		// assert
 		assert.Ok(t, err)
@@ -63,13 +84,67 @@ func TestInMemoryEventBus_Publish(t *testing.T) {
 		b.Register(eventHandler)
 
 		// act
-		err := b.Publish([]domain.DomainEvent{
+		err := b.Publish(mock.Envelopes(
 			mock.SomethingHappened{},
 			mock.SomethingElseHappened{},
-		}...)
+		)...)
 
 		// assert
 		assert.Ok(t, err)
 		assert.Equals(t, want, eventHandler.Happened)
 	})
 }
+
+func TestInMemoryEventBusPublishContext(t *testing.T) {
+	t.Run("ItPassesTheContextToTheHandlers", func(t *testing.T) {
+		// arrange
+		eventHandler := &contextAwareHandler{}
+
+		b := eventbus.NewInMemoryEventBus()
+		b.Register(eventHandler)
+
+		ctx := domain.WithPrincipal(context.Background(), "tom@game.net")
+
+		// act
+		err := b.PublishContext(ctx, mock.Envelopes(mock.SomethingHappened{})...)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, "tom@game.net", eventHandler.principal)
+	})
+
+	t.Run("ItDoesNotCallTheHandlersOnceTheContextIsDone", func(t *testing.T) {
+		// arrange
+		eventHandler := &mock.EventHandlerMock{}
+
+		b := eventbus.NewInMemoryEventBus()
+		b.Register(eventHandler)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		err := b.PublishContext(ctx, mock.Envelopes(mock.SomethingHappened{})...)
+
+		// assert
+		assert.Equals(t, context.Canceled, err)
+		assert.Equals(t, 0, len(eventHandler.Happened))
+	})
+}
+
+type contextAwareHandler struct {
+	principal string
+}
+
+func (h *contextAwareHandler) SubscribedTo() domain.EventMatcher {
+	return domain.MatchAny()
+}
+
+func (h *contextAwareHandler) Handle(e domain.Envelope) error {
+	return h.HandleContext(context.Background(), e)
+}
+
+func (h *contextAwareHandler) HandleContext(ctx context.Context, e domain.Envelope) error {
+	h.principal, _ = domain.PrincipalFrom(ctx)
+	return nil
+}
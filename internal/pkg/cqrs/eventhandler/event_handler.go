@@ -1,6 +1,7 @@
 package eventhandler
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -11,19 +12,27 @@ import (
 
 // EventHandler handles events.
 type EventHandler struct {
-	handlers   map[string]domain.EventHandlerFunc
+	handlers   map[string]domain.ContextEventHandlerFunc
+	resetters  []domain.Resetter
 	handlersMu sync.RWMutex
 }
 
 // New creates new instance of New.
 func New() *EventHandler {
 	return &EventHandler{
-		handlers: make(map[string]domain.EventHandlerFunc),
+		handlers: make(map[string]domain.ContextEventHandlerFunc),
 	}
 }
 
 // RegisterHandler registers an event handler for the given method.
 func (s *EventHandler) RegisterHandler(method string, handler domain.EventHandlerFunc) {
+	s.RegisterContextHandler(method, func(ctx context.Context, e domain.Envelope) error {
+		return handler(e)
+	})
+}
+
+// RegisterContextHandler registers a context-aware event handler for the given method.
+func (s *EventHandler) RegisterContextHandler(method string, handler domain.ContextEventHandlerFunc) {
 	s.handlersMu.Lock()
 	defer s.handlersMu.Unlock()
 	s.handlers[method] = handler
@@ -39,21 +48,61 @@ func (s *EventHandler) SubscribedTo() domain.EventMatcher {
 }
 
 // Handle implements domain.EventHandler interface.
-func (s *EventHandler) Handle(e domain.DomainEvent) error {
+func (s *EventHandler) Handle(e domain.Envelope) error {
+	return s.HandleContext(context.Background(), e)
+}
+
+// HandleContext implements domain.ContextEventHandler interface.
+func (s *EventHandler) HandleContext(ctx context.Context, e domain.Envelope) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	s.handlersMu.RLock()
 	defer s.handlersMu.RUnlock()
 
-	handlerID := "On" + e.EventType()
+	handlerID := "On" + e.Event.EventType()
 	handler, ok := s.handlers[handlerID]
 	if !ok {
 		return fmt.Errorf("event handler for %s event is not found", handlerID)
 	}
 
-	return handler(e)
+	return handler(ctx, e)
+}
+
+// Reset implements domain.Resetter interface.
+//
+// It resets the registered entities which implement domain.Resetter interface.
+func (s *EventHandler) Reset() error {
+	s.handlersMu.RLock()
+	defer s.handlersMu.RUnlock()
+
+	for _, r := range s.resetters {
+		if err := r.Reset(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// RegisterHandlers registers all the event handlers found in .
+// RegisterHandlers registers all the event handlers found in the entity.
+//
+// An event handler is a method named after the event with the "On" prefix.
+// It optionally takes the context first, then the event and optionally its envelope and returns an error:
+//
+//	OnSomethingHappened(e SomethingHappened) error
+//	OnSomethingHappened(e SomethingHappened, envelope domain.Envelope) error
+//	OnSomethingHappened(ctx context.Context, e SomethingHappened) error
+//	OnSomethingHappened(ctx context.Context, e SomethingHappened, envelope domain.Envelope) error
+//
+// If the entity implements domain.Resetter interface, it is reset along with the handler.
 func (h *EventHandler) RegisterHandlers(entity interface{}) {
+	if r, ok := entity.(domain.Resetter); ok {
+		h.handlersMu.Lock()
+		h.resetters = append(h.resetters, r)
+		h.handlersMu.Unlock()
+	}
+
 	entityType := reflect.TypeOf(entity)
 	for i := 0; i < entityType.NumMethod(); i++ {
 		method := entityType.Method(i)
@@ -66,16 +115,33 @@ func (h *EventHandler) registerHandlerDynamically(method reflect.Method, entity
 		return
 	}
 
-	h.RegisterHandler(method.Name, func(e domain.DomainEvent) error {
-		return h.invokeEventHandler(method, entity, e)
+	h.RegisterContextHandler(method.Name, func(ctx context.Context, e domain.Envelope) error {
+		return h.invokeEventHandler(ctx, method, entity, e)
 	})
 }
 
-func (h *EventHandler) invokeEventHandler(method reflect.Method, entity interface{}, e domain.DomainEvent) error {
-	result := method.Func.Call([]reflect.Value{reflect.ValueOf(entity), reflect.ValueOf(e)})
+func (h *EventHandler) invokeEventHandler(
+	ctx context.Context, method reflect.Method, entity interface{}, e domain.Envelope) error {
+	args := []reflect.Value{reflect.ValueOf(entity)}
+	if takesContext(method) {
+		args = append(args, reflect.ValueOf(ctx))
+	}
+
+	args = append(args, reflect.ValueOf(e.Event))
+	if method.Type.NumIn() > len(args) {
+		args = append(args, reflect.ValueOf(e))
+	}
+
+	result := method.Func.Call(args)
 	resErr := result[0].Interface()
 	if resErr != nil {
 		return resErr.(error)
 	}
 	return nil
 }
+
+// takesContext tells whether the method takes a context.Context right after the receiver.
+func takesContext(method reflect.Method) bool {
+	contextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	return method.Type.NumIn() > 1 && method.Type.In(1) == contextType
+}
@@ -1,6 +1,7 @@
 package eventhandler_test
 
 import (
+	"context"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
 	"testing"
 
@@ -13,6 +14,9 @@ import (
 // ensure that event handler implements domain.EventHandler interface.
 var _ domain.EventHandler = (*eventhandler.EventHandler)(nil)
 
+// ensure that event handler implements domain.ContextEventHandler interface.
+var _ domain.ContextEventHandler = (*eventhandler.EventHandler)(nil)
+
 func TestNew(t *testing.T) {
 	t.Run("ItCreatesNewInstance", func(t *testing.T) {
 		assert.True(t, eventhandler.New() != nil)
@@ -28,19 +32,36 @@ func TestEventHandlerHandle(t *testing.T) {
 		s.RegisterHandlers(eh)
 
 		// act
-		err := s.Handle(mock.SomethingHappened{})
+		err := s.Handle(domain.Envelope{Event: mock.SomethingHappened{}})
 
 		// assert
 		assert.Ok(t, err)
 		assert.Equals(t, "test", eh.SomethingHappened)
 	})
 
+	t.Run("ItPassesTheEnvelopeIfTheHandlerAcceptsIt", func(t *testing.T) {
+		// arrange
+		eh := &mock.TestEventHandler{}
+
+		s := eventhandler.New()
+		s.RegisterHandlers(eh)
+
+		want := domain.Envelope{ID: "e1", Event: mock.SomethingChanged{Value: "test"}}
+
+		// act
+		err := s.Handle(want)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, eh.SomethingChanged)
+	})
+
 	t.Run("ItFailsIfEventHandlerIsNotRegistered", func(t *testing.T) {
 		// arrange
 		s := eventhandler.New()
 
 		// act
-		err := s.Handle(mock.SomethingElseHappened{})
+		err := s.Handle(domain.Envelope{Event: mock.SomethingElseHappened{}})
 
 		// assert
 		assert.Equals(t, mock.ErrEventHandlerNotFound, err)
@@ -53,24 +74,63 @@ func TestEventHandlerHandle(t *testing.T) {
 		s := eventhandler.New()
 		s.RegisterHandlers(eh)
 		// act
-		err := s.Handle(mock.SomethingElseHappened{})
+		err := s.Handle(domain.Envelope{Event: mock.SomethingElseHappened{}})
 
 		// assert
 		assert.Equals(t, mock.ErrCannotHandleEvent, err)
 	})
 }
 
+func TestEventHandlerHandleContext(t *testing.T) {
+	t.Run("ItPassesTheContextIfTheHandlerAcceptsIt", func(t *testing.T) {
+		// arrange
+		eh := &contextAwareEventHandler{}
+
+		s := eventhandler.New()
+		s.RegisterHandlers(eh)
+
+		ctx := domain.WithPrincipal(context.Background(), "tom@game.net")
+		want := domain.Envelope{ID: "e1", Event: mock.SomethingChanged{Value: "test"}}
+
+		// act
+		err := s.HandleContext(ctx, want)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, "tom@game.net", eh.principal)
+		assert.Equals(t, want, eh.SomethingChanged)
+	})
+
+	t.Run("ItFailsIfTheContextIsDone", func(t *testing.T) {
+		// arrange
+		eh := &mock.TestEventHandler{}
+
+		s := eventhandler.New()
+		s.RegisterHandlers(eh)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		err := s.HandleContext(ctx, domain.Envelope{Event: mock.SomethingHappened{}})
+
+		// assert
+		assert.Equals(t, context.Canceled, err)
+		assert.Equals(t, "", eh.SomethingHappened)
+	})
+}
+
 func TestEventHandlerSubscribedTo(t *testing.T) {
 	t.Run("ItReturnersTheEventsItSubscribedTo", func(t *testing.T) {
 		// arrange
 		eh := &mock.TestEventHandler{}
 
 		s := eventhandler.New()
-		s.RegisterHandler("OnSomethingHappened", func(e domain.DomainEvent) error {
-			return eh.OnSomethingHappened(e.(mock.SomethingHappened))
+		s.RegisterHandler("OnSomethingHappened", func(e domain.Envelope) error {
+			return eh.OnSomethingHappened(e.Event.(mock.SomethingHappened))
 		})
-		s.RegisterHandler("OnSomethingElseHappened", func(e domain.DomainEvent) error {
-			return eh.OnSomethingElseHappened(e.(mock.SomethingElseHappened))
+		s.RegisterHandler("OnSomethingElseHappened", func(e domain.Envelope) error {
+			return eh.OnSomethingElseHappened(e.Event.(mock.SomethingElseHappened))
 		})
 
 		///want := []string{"SomethingHappened", "SomethingElseHappened"}
@@ -83,3 +143,33 @@ func TestEventHandlerSubscribedTo(t *testing.T) {
 		assert.True(t, matcher(mock.SomethingElseHappened{}))
 	})
 }
+
+func TestEventHandlerReset(t *testing.T) {
+	t.Run("ItResetsTheRegisteredEntities", func(t *testing.T) {
+		// arrange
+		eh := &mock.TestEventHandler{}
+
+		s := eventhandler.New()
+		s.RegisterHandlers(eh)
+		assert.Ok(t, s.Handle(domain.Envelope{Event: mock.SomethingHappened{}}))
+
+		// act
+		err := s.Reset()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, "", eh.SomethingHappened)
+	})
+}
+
+type contextAwareEventHandler struct {
+	principal        string
+	SomethingChanged domain.Envelope
+}
+
+func (h *contextAwareEventHandler) OnSomethingChanged(
+	ctx context.Context, e mock.SomethingChanged, envelope domain.Envelope) error {
+	h.principal, _ = domain.PrincipalFrom(ctx)
+	h.SomethingChanged = envelope
+	return nil
+}
@@ -3,41 +3,30 @@ package eventstore_test
 import (
 	"fmt"
 
-	"github.com/screwyprof/roshambo/internal/pkg/cqrs/aggregate"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
 
 	"github.com/screwyprof/roshambo/pkg/domain"
 )
 
-func ExampleInMemoryEventStoreLoadEventsFor() {
+func ExampleInMemoryEventStore_LoadEventsFor() {
 	ID := mock.StringIdentifier("TestAgg")
 
 	es := eventstore.NewInInMemoryEventStore()
-	_ = es.StoreEventsFor(ID, 0, []domain.DomainEvent{mock.SomethingHappened{}})
+	_ = es.StoreEventsFor(ID, 0, mock.Envelopes(mock.SomethingHappened{}))
 
 	events, _ := es.LoadEventsFor(ID)
-	fmt.Printf("%#v", events)
+	fmt.Printf("%#v", domain.EventsOf(events))
 
 	// Output:
 	// []domain.DomainEvent{mock.SomethingHappened{}}
 }
 
-func ExampleInMemoryEventStoreStoreEventsForConcurrencyError() {
+func ExampleInMemoryEventStore_StoreEventsFor_concurrencyError() {
 	ID := mock.StringIdentifier("TestAgg")
 
-	pureAgg := mock.NewTestAggregate(ID)
-
-	commandHandler := aggregate.NewCommandHandler()
-	commandHandler.RegisterHandlers(pureAgg)
-
-	eventApplier := aggregate.NewEventApplier()
-	eventApplier.RegisterAppliers(pureAgg)
-
-	aggregate.NewAdvanced(pureAgg, commandHandler, eventApplier)
-
 	es := eventstore.NewInInMemoryEventStore()
-	err := es.StoreEventsFor(ID, 1, []domain.DomainEvent{mock.SomethingHappened{}})
+	err := es.StoreEventsFor(ID, 1, mock.Envelopes(mock.SomethingHappened{}))
 
 	fmt.Printf("%v", err)
 
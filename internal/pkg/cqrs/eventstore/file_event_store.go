@@ -0,0 +1,1054 @@
+package eventstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/fsutil"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+var (
+	// ErrEventStoreClosed happens if the event store is used after it has been closed.
+	ErrEventStoreClosed = errors.New("event store is closed")
+
+	errTornRecord = errors.New("torn record")
+)
+
+// SyncPolicy defines when the written data is flushed to the disk.
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes every append before returning. It is the safest and the slowest policy.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval flushes the appended streams periodically.
+	SyncInterval
+	// SyncNever leaves flushing to the operating system.
+	SyncNever
+)
+
+const (
+	defaultSegmentSize  = 64 << 20
+	defaultSyncInterval = time.Second
+
+	segmentExt        = ".seg"
+	indexFileName     = "index"
+	publishedFileName = "published"
+	recordHeaderSize  = 8
+	indexEntrySize    = 16
+)
+
+// FileOption configures FileEventStore.
+type FileOption func(*FileEventStore)
+
+// WithSyncPolicy sets the sync policy, SyncAlways is used by default.
+func WithSyncPolicy(policy SyncPolicy) FileOption {
+	return func(s *FileEventStore) {
+		s.syncPolicy = policy
+	}
+}
+
+// WithSyncInterval sets how often the streams are flushed when SyncInterval policy is used.
+func WithSyncInterval(interval time.Duration) FileOption {
+	return func(s *FileEventStore) {
+		s.syncInterval = interval
+	}
+}
+
+// WithSegmentSize sets the size in bytes after which a new log segment is started.
+func WithSegmentSize(size int64) FileOption {
+	return func(s *FileEventStore) {
+		s.segmentSize = size
+	}
+}
+
+// FileEventStore is a durable append-only event store.
+//
+// Each event stream is kept in its own directory as a sequence of log segments
+// and an index which maps stream versions to record offsets. Every record is
+// checksummed, so a torn write left by a crash is detected and truncated
+// when the stream is opened.
+//
+// The events stored at once make a batch which is either recovered as a whole or dropped.
+//
+// Appends are serialized across all the streams, so the global positions grow monotonically.
+// With SyncAlways policy an append is flushed before the next one starts, so the stored events are visible
+// only once they are durable. Reads don't block each other and only read the records they return.
+// The global order of the events is kept in an in-memory index which is rebuilt when the store is opened.
+type FileEventStore struct {
+	dir          string
+	serializer   domain.EventSerializer
+	syncPolicy   SyncPolicy
+	syncInterval time.Duration
+	segmentSize  int64
+
+	streams   map[string]*fileStream
+	all       []globalEntry
+	position  int64
+	published int64
+	dirty     map[string]struct{}
+	closed    bool
+	mu        sync.RWMutex
+
+	done     chan struct{}
+	syncerWg sync.WaitGroup
+}
+
+// NewFileEventStore creates a new instance of FileEventStore which keeps its data in the given directory.
+func NewFileEventStore(dir string, serializer domain.EventSerializer, opts ...FileOption) (*FileEventStore, error) {
+	if dir == "" {
+		panic("dir is required")
+	}
+
+	if serializer == nil {
+		panic("serializer is required")
+	}
+
+	s := &FileEventStore{
+		dir:          dir,
+		serializer:   serializer,
+		syncPolicy:   SyncAlways,
+		syncInterval: defaultSyncInterval,
+		segmentSize:  defaultSegmentSize,
+		streams:      make(map[string]*fileStream),
+		dirty:        make(map[string]struct{}),
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := s.recoverGlobalIndex(); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPublished(); err != nil {
+		return nil, err
+	}
+
+	if s.syncPolicy == SyncInterval {
+		s.syncerWg.Add(1)
+		go s.runSyncer()
+	}
+
+	return s, nil
+}
+
+// LoadEventsFor loads the whole event history for the given aggregate.
+func (s *FileEventStore) LoadEventsFor(aggregateID domain.Identifier) ([]domain.Envelope, error) {
+	return s.LoadEventsFrom(aggregateID, 0)
+}
+
+// LoadEventsFrom loads the events of the given aggregate which follow the given version.
+//
+// The index is used to find the first record, so the preceding records are not read.
+// It returns ErrNegativeVersion if the version is negative.
+func (s *FileEventStore) LoadEventsFrom(aggregateID domain.Identifier, version int) ([]domain.Envelope, error) {
+	if version < 0 {
+		return nil, ErrNegativeVersion
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil, ErrEventStoreClosed
+	}
+
+	// all the streams are opened along with the store, so an unknown stream has no events yet
+	stream, ok := s.streams[aggregateID.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	records, err := stream.readFrom(version)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.Envelope, 0, len(records))
+	for _, rec := range records {
+		e, err := s.unmarshalRecord(aggregateID.String(), rec)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// StoreEventsFor appends events to the stream of the given aggregate.
+//
+// It returns ErrConcurrencyViolation if the stream has been changed since the given version.
+// The given envelopes get their stream versions and global positions assigned.
+func (s *FileEventStore) StoreEventsFor(aggregateID domain.Identifier, version int, events []domain.Envelope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrEventStoreClosed
+	}
+
+	stream, err := s.openStream(aggregateID)
+	if err != nil {
+		return err
+	}
+
+	if stream.version() != version {
+		return ErrConcurrencyViolation
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	records := make([]fileRecord, 0, len(events))
+	for i, e := range events {
+		rec, err := s.marshalRecord(version+i+1, s.position+int64(i)+1, e)
+		if err != nil {
+			return err
+		}
+		rec.BatchSize, rec.BatchIndex = len(events), i
+		records = append(records, rec)
+	}
+
+	touched, err := stream.append(records, s.segmentSize, s.syncPolicy == SyncAlways)
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		events[i].AggregateID = aggregateID.String()
+		events[i].Version = records[i].Version
+		events[i].Position = records[i].Position
+		s.all = append(s.all, globalEntry{position: records[i].Position, key: aggregateID.String(), version: records[i].Version})
+	}
+	s.position += int64(len(events))
+
+	for _, path := range touched {
+		s.dirty[path] = struct{}{}
+	}
+
+	return nil
+}
+
+// LoadAllEventsFrom implements domain.EventLog interface.
+func (s *FileEventStore) LoadAllEventsFrom(position int64, limit int) ([]domain.Envelope, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil, ErrEventStoreClosed
+	}
+
+	return s.loadAllEventsFrom(position, limit)
+}
+
+// PendingEvents implements domain.Outbox interface.
+func (s *FileEventStore) PendingEvents(limit int) ([]domain.Envelope, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil, ErrEventStoreClosed
+	}
+
+	return s.loadAllEventsFrom(s.published, limit)
+}
+
+// MarkPublished implements domain.Outbox interface.
+//
+// The published position is kept in a separate file which is replaced atomically.
+func (s *FileEventStore) MarkPublished(position int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrEventStoreClosed
+	}
+
+	if position <= s.published {
+		return nil
+	}
+
+	var data [8]byte
+	binary.BigEndian.PutUint64(data[:], uint64(position))
+	if err := fsutil.ReplaceFile(filepath.Join(s.dir, publishedFileName), data[:], s.syncPolicy != SyncNever); err != nil {
+		return err
+	}
+
+	s.published = position
+	return nil
+}
+
+func (s *FileEventStore) loadAllEventsFrom(position int64, limit int) ([]domain.Envelope, error) {
+	first := sort.Search(len(s.all), func(i int) bool {
+		return s.all[i].position > position
+	})
+
+	entries := s.all[first:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+
+	files := make(segmentFiles)
+	defer files.close()
+
+	events := make([]domain.Envelope, 0, len(entries))
+	for _, entry := range entries {
+		rec, err := s.streams[entry.key].readAt(entry.version, files)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := s.unmarshalRecord(entry.key, rec)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// Close flushes all the pending writes and releases the store.
+func (s *FileEventStore) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	s.syncerWg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.syncDirty()
+}
+
+func (s *FileEventStore) runSyncer() {
+	defer s.syncerWg.Done()
+
+	ticker := time.NewTicker(s.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// the files are flushed without holding the lock, so the appends are not blocked meanwhile
+			s.mu.Lock()
+			dirty := s.dirty
+			s.dirty = make(map[string]struct{})
+			s.mu.Unlock()
+
+			_ = syncFiles(dirty)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *FileEventStore) syncDirty() error {
+	dirty := s.dirty
+	s.dirty = make(map[string]struct{})
+
+	if s.syncPolicy == SyncNever {
+		return nil
+	}
+	return syncFiles(dirty)
+}
+
+func syncFiles(paths map[string]struct{}) error {
+	var firstErr error
+	for path := range paths {
+		if err := fsutil.SyncFile(path); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *FileEventStore) openStream(aggregateID domain.Identifier) (*fileStream, error) {
+	return s.openStreamByKey(aggregateID.String())
+}
+
+func (s *FileEventStore) openStreamByKey(key string) (*fileStream, error) {
+	if stream, ok := s.streams[key]; ok {
+		return stream, nil
+	}
+
+	stream := &fileStream{dir: filepath.Join(s.dir, hex.EncodeToString([]byte(key)))}
+	if err := stream.recover(); err != nil {
+		return nil, fmt.Errorf("cannot open stream %s: %v", key, err)
+	}
+
+	s.streams[key] = stream
+	return stream, nil
+}
+
+func (s *FileEventStore) loadPublished() error {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, publishedFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 8 {
+		s.published = int64(binary.BigEndian.Uint64(data))
+	}
+	return nil
+}
+
+// recoverGlobalIndex opens all the streams and orders their events by the global positions.
+func (s *FileEventStore) recoverGlobalIndex() error {
+	dirs, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range dirs {
+		key, err := hex.DecodeString(d.Name())
+		if !d.IsDir() || err != nil {
+			continue
+		}
+
+		stream, err := s.openStreamByKey(string(key))
+		if err != nil {
+			return err
+		}
+
+		records, err := stream.readFrom(0)
+		if err != nil {
+			return err
+		}
+
+		for _, rec := range records {
+			s.all = append(s.all, globalEntry{position: rec.Position, key: string(key), version: rec.Version})
+		}
+	}
+
+	sort.Slice(s.all, func(i, j int) bool {
+		return s.all[i].position < s.all[j].position
+	})
+
+	if n := len(s.all); n > 0 {
+		s.position = s.all[n-1].position
+	}
+
+	return nil
+}
+
+func (s *FileEventStore) marshalRecord(version int, position int64, e domain.Envelope) (fileRecord, error) {
+	data, err := s.serializer.MarshalEvent(e.Event)
+	if err != nil {
+		return fileRecord{}, err
+	}
+
+	return fileRecord{
+		ID:            e.ID,
+		AggregateType: e.AggregateType,
+		Version:       version,
+		Position:      position,
+		RecordedAt:    e.RecordedAt,
+		CommandID:     e.CommandID,
+		CausationID:   e.CausationID,
+		CorrelationID: e.CorrelationID,
+		Metadata:      e.Metadata,
+		EventType:     e.Event.EventType(),
+		SchemaVersion: domain.SchemaVersionOf(e.Event),
+		Data:          data,
+	}, nil
+}
+
+func (s *FileEventStore) unmarshalRecord(aggregateID string, rec fileRecord) (domain.Envelope, error) {
+	e, err := s.serializer.UnmarshalEvent(rec.EventType, rec.SchemaVersion, rec.Data)
+	if err != nil {
+		return domain.Envelope{}, err
+	}
+
+	return domain.Envelope{
+		ID:            rec.ID,
+		AggregateID:   aggregateID,
+		AggregateType: rec.AggregateType,
+		Version:       rec.Version,
+		Position:      rec.Position,
+		RecordedAt:    rec.RecordedAt,
+		CommandID:     rec.CommandID,
+		CausationID:   rec.CausationID,
+		CorrelationID: rec.CorrelationID,
+		Metadata:      rec.Metadata,
+		Event:         e,
+	}, nil
+}
+
+type fileRecord struct {
+	ID            string
+	AggregateType string
+	Version       int
+	Position      int64
+	RecordedAt    time.Time
+	CommandID     string
+	CausationID   string
+	CorrelationID string
+	Metadata      map[string]string
+	EventType     string
+	SchemaVersion int
+	Data          []byte
+
+	// BatchSize is the number of the records stored at once, BatchIndex is the place of the record among them.
+	BatchSize  int
+	BatchIndex int
+}
+
+// endsBatch tells whether the record is the last one of its batch.
+func (rec fileRecord) endsBatch() bool {
+	return rec.BatchIndex+1 == rec.BatchSize
+}
+
+// globalEntry locates an event of the global stream.
+type globalEntry struct {
+	position int64
+	key      string
+	version  int
+}
+
+type indexEntry struct {
+	segment int
+	offset  int64
+}
+
+type segment struct {
+	base int
+	path string
+	size int64
+}
+
+// fileStream is a single event stream on the disk.
+type fileStream struct {
+	dir      string
+	segments []segment
+	entries  []indexEntry
+}
+
+func (st *fileStream) version() int {
+	return len(st.entries)
+}
+
+func (st *fileStream) indexPath() string {
+	return filepath.Join(st.dir, indexFileName)
+}
+
+// recover loads the stream state from the disk.
+//
+// Index entries which point to invalid records are dropped, records which are missing in the index are re-indexed,
+// and a torn record at the end of the log is truncated along with everything after it.
+// The last batch is always scanned again, so it is dropped as a whole if any of its records is missing or torn.
+func (st *fileStream) recover() error {
+	if err := st.loadSegments(); err != nil {
+		return err
+	}
+
+	if len(st.segments) == 0 {
+		if err := os.Remove(st.indexPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	entries, err := st.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	for len(entries) > 0 && !st.isValidEntry(entries[len(entries)-1], len(entries)) {
+		entries = entries[:len(entries)-1]
+	}
+	st.entries = entries
+
+	indexed, err := st.lastBatchStart()
+	if err != nil {
+		return err
+	}
+	st.entries = st.entries[:indexed]
+
+	if err := st.scanTail(); err != nil {
+		return err
+	}
+
+	return st.rewriteIndex(indexed)
+}
+
+func (st *fileStream) loadSegments() error {
+	files, err := ioutil.ReadDir(st.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), segmentExt) {
+			continue
+		}
+
+		base, err := strconv.Atoi(strings.TrimSuffix(f.Name(), segmentExt))
+		if err != nil {
+			continue
+		}
+
+		st.segments = append(st.segments, segment{base: base, path: filepath.Join(st.dir, f.Name()), size: f.Size()})
+	}
+
+	sort.Slice(st.segments, func(i, j int) bool {
+		return st.segments[i].base < st.segments[j].base
+	})
+
+	return nil
+}
+
+func (st *fileStream) loadIndex() ([]indexEntry, error) {
+	data, err := ioutil.ReadFile(st.indexPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]indexEntry, 0, len(data)/indexEntrySize)
+	for i := 0; i+indexEntrySize <= len(data); i += indexEntrySize {
+		base := int(binary.BigEndian.Uint64(data[i:]))
+		offset := int64(binary.BigEndian.Uint64(data[i+8:]))
+
+		seg := st.segmentByBase(base)
+		if seg < 0 {
+			break
+		}
+		entries = append(entries, indexEntry{segment: seg, offset: offset})
+	}
+
+	return entries, nil
+}
+
+func (st *fileStream) isValidEntry(e indexEntry, version int) bool {
+	rec, _, err := st.recordAt(e)
+	return err == nil && rec.Version == version
+}
+
+// lastBatchStart returns the number of the indexed records which precede the last batch.
+func (st *fileStream) lastBatchStart() (int, error) {
+	n := len(st.entries)
+	if n == 0 {
+		return 0, nil
+	}
+
+	rec, _, err := st.recordAt(st.entries[n-1])
+	if err != nil {
+		return 0, err
+	}
+
+	if rec.BatchIndex >= n {
+		return 0, nil
+	}
+	return n - 1 - rec.BatchIndex, nil
+}
+
+// recordAt reads the record the index entry points to and returns the number of bytes it occupies.
+func (st *fileStream) recordAt(e indexEntry) (fileRecord, int, error) {
+	f, err := os.Open(st.segments[e.segment].path)
+	if err != nil {
+		return fileRecord{}, 0, err
+	}
+	defer f.Close()
+
+	return readRecordAt(f, e.offset, st.segments[e.segment].size)
+}
+
+// tail returns the segment and the offset which follow the last indexed record.
+func (st *fileStream) tail() (int, int64, error) {
+	n := len(st.entries)
+	if n == 0 {
+		return 0, 0, nil
+	}
+
+	last := st.entries[n-1]
+	_, size, err := st.recordAt(last)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return last.segment, last.offset + int64(size), nil
+}
+
+// batchStart locates the first record of a batch.
+type batchStart struct {
+	segment int
+	offset  int64
+	version int
+}
+
+// continues tells whether the record follows the given version within the batch.
+func (b batchStart) continues(rec fileRecord, version int) bool {
+	return rec.Version == version+1 && rec.BatchIndex == version-b.version
+}
+
+// scanTail indexes the records which follow the last indexed one.
+//
+// The records are indexed by whole batches, an incomplete batch at the end of the log is truncated.
+func (st *fileStream) scanTail() error {
+	seg, offset, err := st.tail()
+	if err != nil {
+		return err
+	}
+
+	batch := batchStart{segment: seg, offset: offset, version: st.version()}
+	for ; seg < len(st.segments); seg, offset = seg+1, 0 {
+		data, err := readRange(st.segments[seg].path, offset, st.segments[seg].size-offset)
+		if err != nil {
+			return err
+		}
+
+		for pos := 0; pos < len(data); {
+			rec, size, err := decodeRecord(data[pos:])
+			if err == errTornRecord || (err == nil && !batch.continues(rec, st.version())) {
+				return st.truncateBatch(batch)
+			}
+			if err != nil {
+				return err
+			}
+
+			st.entries = append(st.entries, indexEntry{segment: seg, offset: offset + int64(pos)})
+			pos += size
+
+			if rec.endsBatch() {
+				batch = batchStart{segment: seg, offset: offset + int64(pos), version: st.version()}
+			}
+		}
+	}
+
+	if st.version() > batch.version {
+		return st.truncateBatch(batch)
+	}
+	return nil
+}
+
+// truncateBatch cuts the log at the beginning of the batch dropping its index entries.
+func (st *fileStream) truncateBatch(b batchStart) error {
+	st.entries = st.entries[:b.version]
+	return st.truncate(b.segment, b.offset)
+}
+
+// truncate cuts the log at the given position dropping all the following segments.
+func (st *fileStream) truncate(seg int, offset int64) error {
+	if err := os.Truncate(st.segments[seg].path, offset); err != nil {
+		return err
+	}
+	st.segments[seg].size = offset
+
+	for _, s := range st.segments[seg+1:] {
+		if err := os.Remove(s.path); err != nil {
+			return err
+		}
+	}
+	st.segments = st.segments[:seg+1]
+
+	return nil
+}
+
+// rewriteIndex makes the index file match the in-memory entries, the first valid entries are kept intact.
+func (st *fileStream) rewriteIndex(valid int) error {
+	f, err := os.OpenFile(st.indexPath(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := f.Truncate(int64(valid * indexEntrySize)); err != nil {
+		return err
+	}
+
+	if _, err := f.WriteAt(st.encodeEntries(st.entries[valid:]), int64(valid*indexEntrySize)); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+func (st *fileStream) segmentByBase(base int) int {
+	for i, s := range st.segments {
+		if s.base == base {
+			return i
+		}
+	}
+	return -1
+}
+
+func (st *fileStream) encodeEntries(entries []indexEntry) []byte {
+	buf := make([]byte, len(entries)*indexEntrySize)
+	for i, e := range entries {
+		binary.BigEndian.PutUint64(buf[i*indexEntrySize:], uint64(st.segments[e.segment].base))
+		binary.BigEndian.PutUint64(buf[i*indexEntrySize+8:], uint64(e.offset))
+	}
+	return buf
+}
+
+// append writes the events to the log and the index, it returns the paths of the written files.
+func (st *fileStream) append(records []fileRecord, segmentSize int64, sync bool) ([]string, error) {
+	var buf bytes.Buffer
+	offsets := make([]int64, 0, len(records))
+	for _, rec := range records {
+		offsets = append(offsets, int64(buf.Len()))
+		if err := encodeRecord(&buf, rec); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := st.ensureActiveSegment(int64(buf.Len()), segmentSize, sync); err != nil {
+		return nil, err
+	}
+
+	seg := len(st.segments) - 1
+	active := &st.segments[seg]
+	if err := appendFile(active.path, buf.Bytes(), active.size, sync); err != nil {
+		return nil, err
+	}
+
+	entries := make([]indexEntry, 0, len(records))
+	for _, offset := range offsets {
+		entries = append(entries, indexEntry{segment: seg, offset: active.size + offset})
+	}
+
+	if err := appendFile(st.indexPath(), st.encodeEntries(entries), int64(st.version()*indexEntrySize), sync); err != nil {
+		_ = os.Truncate(active.path, active.size)
+		return nil, err
+	}
+
+	active.size += int64(buf.Len())
+	st.entries = append(st.entries, entries...)
+
+	return []string{active.path, st.indexPath()}, nil
+}
+
+func (st *fileStream) ensureActiveSegment(size, segmentSize int64, sync bool) error {
+	if n := len(st.segments); n > 0 && (st.segments[n-1].size == 0 || st.segments[n-1].size+size <= segmentSize) {
+		return nil
+	}
+
+	if err := os.MkdirAll(st.dir, 0755); err != nil {
+		return err
+	}
+
+	base := st.version() + 1
+	path := filepath.Join(st.dir, fmt.Sprintf("%020d%s", base, segmentExt))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if sync {
+		if err := fsutil.SyncFile(st.dir); err != nil {
+			return err
+		}
+	}
+
+	st.segments = append(st.segments, segment{base: base, path: path})
+	return nil
+}
+
+// readAt reads the record of the given version, the segments are kept open in the given files.
+func (st *fileStream) readAt(version int, files segmentFiles) (fileRecord, error) {
+	entry := st.entries[version-1]
+	seg := st.segments[entry.segment]
+
+	f, err := files.open(seg.path)
+	if err != nil {
+		return fileRecord{}, err
+	}
+
+	rec, _, err := readRecordAt(f, entry.offset, seg.size)
+	return rec, err
+}
+
+// readFrom reads the records which follow the given version.
+//
+// Only the part of the segments which starts at the first record is read.
+func (st *fileStream) readFrom(version int) ([]fileRecord, error) {
+	if version >= st.version() {
+		return nil, nil
+	}
+
+	records := make([]fileRecord, 0, st.version()-version)
+
+	first := st.entries[version]
+	for seg, offset := first.segment, first.offset; seg < len(st.segments); seg, offset = seg+1, 0 {
+		data, err := readRange(st.segments[seg].path, offset, st.segments[seg].size-offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for pos := 0; pos < len(data); {
+			rec, size, err := decodeRecord(data[pos:])
+			if err != nil {
+				return nil, err
+			}
+
+			records = append(records, rec)
+			pos += size
+		}
+	}
+
+	return records, nil
+}
+
+// segmentFiles keeps the segments open while several records are read.
+type segmentFiles map[string]*os.File
+
+func (fs segmentFiles) open(path string) (*os.File, error) {
+	if f, ok := fs[path]; ok {
+		return f, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fs[path] = f
+	return f, nil
+}
+
+func (fs segmentFiles) close() {
+	for _, f := range fs {
+		_ = f.Close()
+	}
+}
+
+// readRecordAt reads the record which starts at the given offset of the segment of the given size.
+func readRecordAt(f *os.File, offset, segmentSize int64) (fileRecord, int, error) {
+	var header [recordHeaderSize]byte
+	if offset+recordHeaderSize > segmentSize {
+		return fileRecord{}, 0, errTornRecord
+	}
+	if _, err := f.ReadAt(header[:], offset); err != nil {
+		return fileRecord{}, 0, err
+	}
+
+	size := int64(binary.BigEndian.Uint32(header[0:]))
+	if offset+recordHeaderSize+size > segmentSize {
+		return fileRecord{}, 0, errTornRecord
+	}
+
+	data := make([]byte, recordHeaderSize+size)
+	if _, err := f.ReadAt(data, offset); err != nil {
+		return fileRecord{}, 0, err
+	}
+
+	return decodeRecord(data)
+}
+
+// readRange reads size bytes of the file starting at the given offset.
+func readRange(path string, offset, size int64) ([]byte, error) {
+	if size <= 0 {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data := make([]byte, size)
+	if _, err := f.ReadAt(data, offset); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func encodeRecord(buf *bytes.Buffer, rec fileRecord) error {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(&rec); err != nil {
+		return err
+	}
+
+	var header [recordHeaderSize]byte
+	binary.BigEndian.PutUint32(header[0:], uint32(payload.Len()))
+	binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(payload.Bytes()))
+
+	buf.Write(header[:])
+	buf.Write(payload.Bytes())
+
+	return nil
+}
+
+// decodeRecord decodes the record at the beginning of the data and returns the number of bytes it occupies.
+func decodeRecord(data []byte) (fileRecord, int, error) {
+	var rec fileRecord
+	if len(data) < recordHeaderSize {
+		return rec, 0, errTornRecord
+	}
+
+	size := int(binary.BigEndian.Uint32(data[0:]))
+	checksum := binary.BigEndian.Uint32(data[4:])
+	if len(data)-recordHeaderSize < size {
+		return rec, 0, errTornRecord
+	}
+
+	payload := data[recordHeaderSize : recordHeaderSize+size]
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return rec, 0, errTornRecord
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec); err != nil {
+		return rec, 0, err
+	}
+
+	return rec, recordHeaderSize + size, nil
+}
+
+// appendFile writes the data at the given offset, a partially written data is truncated.
+func appendFile(path string, data []byte, offset int64, sync bool) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteAt(data, offset); err != nil {
+		_ = f.Truncate(offset)
+		return err
+	}
+
+	if sync {
+		return f.Sync()
+	}
+	return nil
+}
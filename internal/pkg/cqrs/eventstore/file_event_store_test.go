@@ -0,0 +1,617 @@
+package eventstore_test
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/serializer"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that file event store implements domain.EventStore interface.
+var _ domain.EventStore = (*eventstore.FileEventStore)(nil)
+
+// ensure that file event store implements domain.EventLog interface.
+var _ domain.EventLog = (*eventstore.FileEventStore)(nil)
+
+// ensure that file event store implements domain.Outbox interface.
+var _ domain.Outbox = (*eventstore.FileEventStore)(nil)
+
+var testDir string
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		panic(err)
+	}
+	testDir = dir
+
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestNewFileEventStore(t *testing.T) {
+	t.Run("ItPanicsIfDirIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			_, _ = eventstore.NewFileEventStore("", nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfSerializerIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			_, _ = eventstore.NewFileEventStore(tempDir(t), nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItCreatesEventStore", func(t *testing.T) {
+		es, err := eventstore.NewFileEventStore(tempDir(t), createSerializer())
+
+		assert.Ok(t, err)
+		assert.True(t, es != nil)
+		assert.Ok(t, es.Close())
+	})
+}
+
+func TestFileEventStoreLoadEventsFor(t *testing.T) {
+	t.Run("ItReturnsNoEventsForAnUnknownAggregate", func(t *testing.T) {
+		// arrange
+		es := createFileEventStore(t, tempDir(t))
+		defer es.Close()
+
+		// act
+		got, err := es.LoadEventsFor(mock.StringIdentifier("TestAgg"))
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+
+	t.Run("ItLoadsTheWholeHistory", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := createFileEventStore(t, tempDir(t))
+		defer es.Close()
+
+		want := changes("1", "2", "3")
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(want[:2]...)))
+		assert.Ok(t, es.StoreEventsFor(ID, 2, mock.Envelopes(want[2:]...)))
+
+		// act
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, domain.EventsOf(got))
+	})
+
+	t.Run("ItLoadsEventsAfterReopening", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+		want := changes("1", "2")
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(want...)))
+		assert.Ok(t, es.Close())
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, domain.EventsOf(got))
+	})
+
+	t.Run("ItLoadsEventsSpreadOverSeveralSegments", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+		want := changes("1", "2", "3", "4")
+
+		es := createFileEventStore(t, dir, eventstore.WithSegmentSize(1))
+		for i, e := range want {
+			assert.Ok(t, es.StoreEventsFor(ID, i, mock.Envelopes(e)))
+		}
+		assert.Ok(t, es.Close())
+
+		// act
+		es = createFileEventStore(t, dir, eventstore.WithSegmentSize(1))
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, domain.EventsOf(got))
+		assert.Equals(t, len(want), len(segments(t, dir, ID)))
+	})
+
+	t.Run("ItFailsIfTheStoreIsClosed", func(t *testing.T) {
+		// arrange
+		es := createFileEventStore(t, tempDir(t))
+		assert.Ok(t, es.Close())
+
+		// act
+		_, err := es.LoadEventsFor(mock.StringIdentifier("TestAgg"))
+
+		// assert
+		assert.Equals(t, eventstore.ErrEventStoreClosed, err)
+	})
+}
+
+func TestFileEventStoreLoadEventsFrom(t *testing.T) {
+	t.Run("ItLoadsEventsFollowingTheGivenVersion", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := createFileEventStore(t, tempDir(t), eventstore.WithSegmentSize(2))
+		defer es.Close()
+
+		want := changes("1", "2", "3", "4", "5")
+		for i, e := range want {
+			assert.Ok(t, es.StoreEventsFor(ID, i, mock.Envelopes(e)))
+		}
+
+		// act
+		got, err := es.LoadEventsFrom(ID, 3)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want[3:], domain.EventsOf(got))
+		assert.Equals(t, 4, got[0].Version)
+	})
+
+	t.Run("ItFailsIfTheVersionIsNegative", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := createFileEventStore(t, tempDir(t))
+		defer es.Close()
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+
+		// act
+		_, err := es.LoadEventsFrom(ID, -1)
+
+		// assert
+		assert.Equals(t, eventstore.ErrNegativeVersion, err)
+	})
+
+	t.Run("ItReturnsNoEventsIfTheVersionIsUpToDate", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := createFileEventStore(t, tempDir(t))
+		defer es.Close()
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+
+		// act
+		got, err := es.LoadEventsFrom(ID, 1)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+func TestFileEventStoreLoadAllEventsFrom(t *testing.T) {
+	t.Run("ItLoadsTheEventsOfAllTheStreamsInTheGlobalOrder", func(t *testing.T) {
+		// arrange
+		es := createFileEventStore(t, tempDir(t))
+		defer es.Close()
+
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, mock.Envelopes(changes("1")...)))
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg2"), 0, mock.Envelopes(changes("2")...)))
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 1, mock.Envelopes(changes("3")...)))
+
+		// act
+		got, err := es.LoadAllEventsFrom(1, 0)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, changes("2", "3"), domain.EventsOf(got))
+		assert.Equals(t, []int64{2, 3}, positionsOf(got))
+		assert.Equals(t, "TestAgg2", got[0].AggregateID)
+	})
+
+	t.Run("ItRebuildsTheGlobalOrderAfterReopening", func(t *testing.T) {
+		// arrange
+		dir := tempDir(t)
+		es := createFileEventStore(t, dir, eventstore.WithSegmentSize(1))
+		for i, v := range []string{"1", "2", "3", "4"} {
+			ID := mock.StringIdentifier("TestAgg" + strconv.Itoa(i%2))
+			assert.Ok(t, es.StoreEventsFor(ID, i/2, mock.Envelopes(changes(v)...)))
+		}
+		assert.Ok(t, es.Close())
+
+		// act
+		es = createFileEventStore(t, dir, eventstore.WithSegmentSize(1))
+		defer es.Close()
+		got, err := es.LoadAllEventsFrom(0, 3)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, changes("1", "2", "3"), domain.EventsOf(got))
+		assert.Equals(t, []int64{1, 2, 3}, positionsOf(got))
+	})
+
+	t.Run("ItFailsIfTheStoreIsClosed", func(t *testing.T) {
+		// arrange
+		es := createFileEventStore(t, tempDir(t))
+		assert.Ok(t, es.Close())
+
+		// act
+		_, err := es.LoadAllEventsFrom(0, 0)
+
+		// assert
+		assert.Equals(t, eventstore.ErrEventStoreClosed, err)
+	})
+}
+
+func TestFileEventStorePendingEvents(t *testing.T) {
+	t.Run("ItKeepsThePublishedPositionAfterReopening", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1", "2", "3")...)))
+		assert.Ok(t, es.MarkPublished(2))
+		assert.Ok(t, es.Close())
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+		got, err := es.PendingEvents(0)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, changes("3"), domain.EventsOf(got))
+	})
+
+	t.Run("ItFailsIfTheStoreIsClosed", func(t *testing.T) {
+		// arrange
+		es := createFileEventStore(t, tempDir(t))
+		assert.Ok(t, es.Close())
+
+		// act
+		_, pendingErr := es.PendingEvents(0)
+		markErr := es.MarkPublished(1)
+
+		// assert
+		assert.Equals(t, eventstore.ErrEventStoreClosed, pendingErr)
+		assert.Equals(t, eventstore.ErrEventStoreClosed, markErr)
+	})
+}
+
+func TestFileEventStoreStoreEventsFor(t *testing.T) {
+	t.Run("ItReturnsConcurrencyErrorIfVersionsAreNotTheSame", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := createFileEventStore(t, tempDir(t))
+		defer es.Close()
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+
+		// act
+		err := es.StoreEventsFor(ID, 0, mock.Envelopes(changes("2")...))
+
+		// assert
+		assert.Equals(t, eventstore.ErrConcurrencyViolation, err)
+	})
+
+	t.Run("ItKeepsTheVersionAfterReopening", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+		assert.Ok(t, es.Close())
+
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+
+		// act
+		err := es.StoreEventsFor(ID, 0, mock.Envelopes(changes("2")...))
+
+		// assert
+		assert.Equals(t, eventstore.ErrConcurrencyViolation, err)
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(changes("2")...)))
+	})
+
+	t.Run("ItFlushesEventsPeriodically", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+		opts := []eventstore.FileOption{
+			eventstore.WithSyncPolicy(eventstore.SyncInterval),
+			eventstore.WithSyncInterval(time.Millisecond),
+		}
+
+		// act
+		es := createFileEventStore(t, dir, opts...)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+		time.Sleep(5 * time.Millisecond)
+		assert.Ok(t, es.Close())
+
+		// assert
+		es = createFileEventStore(t, dir, opts...)
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+		assert.Ok(t, err)
+		assert.Equals(t, changes("1"), domain.EventsOf(got))
+	})
+
+	t.Run("ItFailsIfTheStoreIsClosed", func(t *testing.T) {
+		// arrange
+		es := createFileEventStore(t, tempDir(t), eventstore.WithSyncPolicy(eventstore.SyncNever))
+		assert.Ok(t, es.Close())
+
+		// act
+		err := es.StoreEventsFor(mock.StringIdentifier("TestAgg"), 0, mock.Envelopes(changes("1")...))
+
+		// assert
+		assert.Equals(t, eventstore.ErrEventStoreClosed, err)
+	})
+}
+
+func TestFileEventStoreEnvelopes(t *testing.T) {
+	t.Run("ItRestoresTheSchemaVersionsOfTheEvents", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+		want := []domain.DomainEvent{mock.SomethingChangedV1{Text: "1"}, mock.SomethingChanged{Value: "2"}}
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(want...)))
+		assert.Ok(t, es.Close())
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, domain.EventsOf(got))
+	})
+
+	t.Run("ItKeepsTheEnvelopeMetadata", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+
+		want := []domain.Envelope{{
+			ID:            "e1",
+			AggregateType: mock.TestAggregateType,
+			RecordedAt:    time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC),
+			CommandID:     "c1",
+			CausationID:   "c1",
+			CorrelationID: "r1",
+			Metadata:      map[string]string{"player": "tom"},
+			Event:         mock.SomethingChanged{Value: "1"},
+		}}
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, want))
+		assert.Ok(t, es.Close())
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, got)
+		assert.Equals(t, ID.String(), got[0].AggregateID)
+		assert.Equals(t, 1, got[0].Version)
+		assert.Equals(t, int64(1), got[0].Position)
+	})
+
+	t.Run("ItContinuesGlobalPositionsAfterReopening", func(t *testing.T) {
+		// arrange
+		dir := tempDir(t)
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, mock.Envelopes(changes("1", "2")...)))
+		assert.Ok(t, es.Close())
+
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+
+		events := mock.Envelopes(changes("3")...)
+
+		// act
+		err := es.StoreEventsFor(mock.StringIdentifier("TestAgg2"), 0, events)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, int64(3), events[0].Position)
+	})
+}
+
+func TestFileEventStoreRecovery(t *testing.T) {
+	t.Run("ItTruncatesATornWrite", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+		assert.Ok(t, es.Close())
+
+		appendGarbage(t, segments(t, dir, ID)[0])
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, changes("1"), domain.EventsOf(got))
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(changes("2")...)))
+
+		got, err = es.LoadEventsFor(ID)
+		assert.Ok(t, err)
+		assert.Equals(t, changes("1", "2"), domain.EventsOf(got))
+	})
+
+	t.Run("ItRebuildsAMissingIndex", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1", "2")...)))
+		assert.Ok(t, es.Close())
+
+		assert.Ok(t, os.Remove(filepath.Join(streamDir(dir, ID), "index")))
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+
+		// assert
+		assert.Equals(t, eventstore.ErrConcurrencyViolation, es.StoreEventsFor(ID, 1, mock.Envelopes(changes("3")...)))
+		assert.Ok(t, es.StoreEventsFor(ID, 2, mock.Envelopes(changes("3")...)))
+	})
+
+	t.Run("ItDropsIndexEntriesPointingToMissingRecords", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(changes("2")...)))
+		assert.Ok(t, es.Close())
+
+		truncateBy(t, segments(t, dir, ID)[0], 1)
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, changes("1"), domain.EventsOf(got))
+	})
+	t.Run("ItDropsATornBatchAsAWhole", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(changes("2", "3")...)))
+		assert.Ok(t, es.Close())
+
+		truncateBy(t, segments(t, dir, ID)[0], 1)
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, changes("1"), domain.EventsOf(got))
+
+		all, err := es.LoadAllEventsFrom(0, 0)
+		assert.Ok(t, err)
+		assert.Equals(t, changes("1"), domain.EventsOf(all))
+	})
+
+	t.Run("ItDropsATornBatchWhichIsNotIndexed", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		dir := tempDir(t)
+
+		es := createFileEventStore(t, dir)
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(changes("1")...)))
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(changes("2", "3")...)))
+		assert.Ok(t, es.Close())
+
+		// the batch is written to the log before the index, so a crash leaves it out of the index
+		assert.Ok(t, os.Truncate(filepath.Join(streamDir(dir, ID), "index"), 16))
+		truncateBy(t, segments(t, dir, ID)[0], 1)
+
+		// act
+		es = createFileEventStore(t, dir)
+		defer es.Close()
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, changes("1"), domain.EventsOf(got))
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(changes("2")...)))
+	})
+}
+
+func createFileEventStore(t *testing.T, dir string, opts ...eventstore.FileOption) *eventstore.FileEventStore {
+	t.Helper()
+	es, err := eventstore.NewFileEventStore(dir, createSerializer(), opts...)
+	assert.Ok(t, err)
+	return es
+}
+
+func createSerializer() *serializer.Serializer {
+	registry := serializer.NewRegistry()
+	registry.Register(mock.SomethingChangedV1{}, mock.SomethingChanged{})
+
+	return serializer.NewSerializer(registry, serializer.JSONCodec{})
+}
+
+func changes(values ...string) []domain.DomainEvent {
+	events := make([]domain.DomainEvent, 0, len(values))
+	for _, v := range values {
+		events = append(events, mock.SomethingChanged{Value: v})
+	}
+	return events
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir(testDir, "")
+	assert.Ok(t, err)
+	return dir
+}
+
+func streamDir(dir string, ID domain.Identifier) string {
+	return filepath.Join(dir, hex.EncodeToString([]byte(ID.String())))
+}
+
+func segments(t *testing.T, dir string, ID domain.Identifier) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(streamDir(dir, ID), "*.seg"))
+	assert.Ok(t, err)
+	return files
+}
+
+func appendGarbage(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.Ok(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 0, 42})
+	assert.Ok(t, err)
+	assert.Ok(t, f.Close())
+}
+
+func truncateBy(t *testing.T, path string, n int64) {
+	t.Helper()
+	info, err := os.Stat(path)
+	assert.Ok(t, err)
+	assert.Ok(t, os.Truncate(path, info.Size()-n))
+}
@@ -9,42 +9,127 @@ import (
 
 var (
 	// ErrConcurrencyViolation happens if aggregate has been modified concurrently.
-	ErrConcurrencyViolation = errors.New("concurrency error: aggregate versions differ")
+	//
+	// It is the same error as domain.ErrConcurrencyViolation.
+	ErrConcurrencyViolation = domain.ErrConcurrencyViolation
+
+	// ErrNegativeVersion happens if the events are loaded from a negative version.
+	ErrNegativeVersion = errors.New("version must not be negative")
 )
 
 // InMemoryEventStore stores and loads events from memory.
+//
+// Event streams are append-only: the expected version is checked
+// and the events are appended while holding the same lock.
 type InMemoryEventStore struct {
-	eventStreams   map[domain.Identifier][]domain.DomainEvent
+	eventStreams   map[domain.Identifier][]domain.Envelope
+	all            []domain.Envelope
+	published      int64
 	eventStreamsMu sync.RWMutex
 }
 
 // NewInInMemoryEventStore creates a new instance of InMemoryEventStore.
 func NewInInMemoryEventStore() *InMemoryEventStore {
 	return &InMemoryEventStore{
-		eventStreams: make(map[domain.Identifier][]domain.DomainEvent),
+		eventStreams: make(map[domain.Identifier][]domain.Envelope),
 	}
 }
 
-// LoadEventsFor loads events for the given aggregate.
-func (s *InMemoryEventStore) LoadEventsFor(aggregateID domain.Identifier) ([]domain.DomainEvent, error) {
+// LoadEventsFor loads the whole event history for the given aggregate.
+func (s *InMemoryEventStore) LoadEventsFor(aggregateID domain.Identifier) ([]domain.Envelope, error) {
+	return s.LoadEventsFrom(aggregateID, 0)
+}
+
+// LoadEventsFrom loads the events of the given aggregate which follow the given version.
+//
+// It returns ErrNegativeVersion if the version is negative.
+func (s *InMemoryEventStore) LoadEventsFrom(aggregateID domain.Identifier, version int) ([]domain.Envelope, error) {
+	if version < 0 {
+		return nil, ErrNegativeVersion
+	}
+
 	s.eventStreamsMu.RLock()
 	defer s.eventStreamsMu.RUnlock()
 
-	return s.eventStreams[aggregateID], nil
+	stream := s.eventStreams[aggregateID]
+	if version >= len(stream) {
+		return nil, nil
+	}
+
+	events := make([]domain.Envelope, len(stream)-version)
+	copy(events, stream[version:])
+
+	return events, nil
 }
 
-// StoreEventsFor saves evens of the given aggregate.
+// StoreEventsFor appends events to the stream of the given aggregate.
+//
+// The version is the number of events the aggregate has seen so far.
+// It returns ErrConcurrencyViolation if the stream has been changed since then.
+// The given envelopes get their stream versions and global positions assigned.
 func (s *InMemoryEventStore) StoreEventsFor(
-	aggregateID domain.Identifier, version int, events []domain.DomainEvent) error {
+	aggregateID domain.Identifier, version int, events []domain.Envelope) error {
+	s.eventStreamsMu.Lock()
+	defer s.eventStreamsMu.Unlock()
 
-	previousEvents, _ := s.LoadEventsFor(aggregateID)
-	if len(previousEvents) != version {
+	if len(s.eventStreams[aggregateID]) != version {
 		return ErrConcurrencyViolation
 	}
 
+	for i := range events {
+		events[i].AggregateID = aggregateID.String()
+		events[i].Version = version + i + 1
+		events[i].Position = int64(len(s.all) + i + 1)
+	}
+
+	s.eventStreams[aggregateID] = append(s.eventStreams[aggregateID], events...)
+	s.all = append(s.all, events...)
+
+	return nil
+}
+
+// LoadAllEventsFrom implements domain.EventLog interface.
+func (s *InMemoryEventStore) LoadAllEventsFrom(position int64, limit int) ([]domain.Envelope, error) {
+	s.eventStreamsMu.RLock()
+	defer s.eventStreamsMu.RUnlock()
+
+	return s.loadAllEventsFrom(position, limit), nil
+}
+
+// PendingEvents implements domain.Outbox interface.
+func (s *InMemoryEventStore) PendingEvents(limit int) ([]domain.Envelope, error) {
+	s.eventStreamsMu.RLock()
+	defer s.eventStreamsMu.RUnlock()
+
+	return s.loadAllEventsFrom(s.published, limit), nil
+}
+
+// MarkPublished implements domain.Outbox interface.
+func (s *InMemoryEventStore) MarkPublished(position int64) error {
 	s.eventStreamsMu.Lock()
 	defer s.eventStreamsMu.Unlock()
-	s.eventStreams[aggregateID] = events
 
+	if position > s.published {
+		s.published = position
+	}
 	return nil
 }
+
+func (s *InMemoryEventStore) loadAllEventsFrom(position int64, limit int) []domain.Envelope {
+	if position < 0 {
+		position = 0
+	}
+	if position >= int64(len(s.all)) {
+		return nil
+	}
+
+	tail := s.all[position:]
+	if limit > 0 && limit < len(tail) {
+		tail = tail[:limit]
+	}
+
+	events := make([]domain.Envelope, len(tail))
+	copy(events, tail)
+
+	return events
+}
@@ -1,6 +1,7 @@
 package eventstore_test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/screwyprof/roshambo/internal/pkg/assert"
@@ -13,6 +14,12 @@ import (
 // ensure that event store implements domain.EventStore interface.
 var _ domain.EventStore = (*eventstore.InMemoryEventStore)(nil)
 
+// ensure that event store implements domain.EventLog interface.
+var _ domain.EventLog = (*eventstore.InMemoryEventStore)(nil)
+
+// ensure that event store implements domain.Outbox interface.
+var _ domain.Outbox = (*eventstore.InMemoryEventStore)(nil)
+
 func TestNewInInMemoryEventStore(t *testing.T) {
 	t.Run("ItCreatesEventStore", func(t *testing.T) {
 		es := eventstore.NewInInMemoryEventStore()
@@ -28,15 +35,178 @@ func TestInMemoryEventStoreLoadEventsFor(t *testing.T) {
 
 		want := []domain.DomainEvent{mock.SomethingHappened{}}
 
-		err := es.StoreEventsFor(ID, 0, want)
+		err := es.StoreEventsFor(ID, 0, mock.Envelopes(want...))
+		assert.Ok(t, err)
+
+		// act
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, domain.EventsOf(got))
+	})
+
+	t.Run("ItLoadsTheWholeHistory", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := eventstore.NewInInMemoryEventStore()
+
+		want := []domain.DomainEvent{mock.SomethingHappened{}, mock.SomethingElseHappened{}}
+
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(want[:1]...)))
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(want[1:]...)))
+
+		// act
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
 		assert.Ok(t, err)
+		assert.Equals(t, want, domain.EventsOf(got))
+	})
+
+	t.Run("ItReturnsACopyOfTheStream", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := eventstore.NewInInMemoryEventStore()
+
+		want := []domain.DomainEvent{mock.SomethingHappened{}}
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(want...)))
 
 		// act
+		loaded, _ := es.LoadEventsFor(ID)
+		loaded[0].Event = mock.SomethingElseHappened{}
+
 		got, err := es.LoadEventsFor(ID)
 
 		// assert
 		assert.Ok(t, err)
-		assert.Equals(t, want, got)
+		assert.Equals(t, want, domain.EventsOf(got))
+	})
+}
+
+func TestInMemoryEventStoreLoadEventsFrom(t *testing.T) {
+	t.Run("ItLoadsEventsFollowingTheGivenVersion", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := eventstore.NewInInMemoryEventStore()
+
+		events := []domain.DomainEvent{mock.SomethingHappened{}, mock.SomethingElseHappened{}}
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(events...)))
+
+		// act
+		got, err := es.LoadEventsFrom(ID, 1)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, events[1:], domain.EventsOf(got))
+		assert.Equals(t, 2, got[0].Version)
+	})
+
+	t.Run("ItFailsIfTheVersionIsNegative", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := eventstore.NewInInMemoryEventStore()
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(mock.SomethingHappened{})))
+
+		// act
+		_, err := es.LoadEventsFrom(ID, -1)
+
+		// assert
+		assert.Equals(t, eventstore.ErrNegativeVersion, err)
+	})
+
+	t.Run("ItReturnsNoEventsIfTheVersionIsUpToDate", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := eventstore.NewInInMemoryEventStore()
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(mock.SomethingHappened{})))
+
+		// act
+		got, err := es.LoadEventsFrom(ID, 1)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+func TestInMemoryEventStoreLoadAllEventsFrom(t *testing.T) {
+	t.Run("ItLoadsTheEventsOfAllTheStreamsInTheGlobalOrder", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, mock.Envelopes(mock.SomethingHappened{})))
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg2"), 0, mock.Envelopes(mock.SomethingElseHappened{})))
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 1, mock.Envelopes(mock.SomethingElseHappened{})))
+
+		// act
+		got, err := es.LoadAllEventsFrom(0, 0)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{
+			mock.SomethingHappened{}, mock.SomethingElseHappened{}, mock.SomethingElseHappened{},
+		}, domain.EventsOf(got))
+		assert.Equals(t, []int64{1, 2, 3}, positionsOf(got))
+	})
+
+	t.Run("ItLoadsAtMostTheGivenNumberOfEventsFollowingThePosition", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		events := mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{}, mock.SomethingHappened{})
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg"), 0, events))
+
+		// act
+		got, err := es.LoadAllEventsFrom(1, 1)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []int64{2}, positionsOf(got))
+	})
+
+	t.Run("ItReturnsNoEventsIfThePositionIsUpToDate", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg"), 0, mock.Envelopes(mock.SomethingHappened{})))
+
+		// act
+		got, err := es.LoadAllEventsFrom(1, 0)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+func TestInMemoryEventStorePendingEvents(t *testing.T) {
+	t.Run("ItReturnsTheStoredEventsWhichHaveNotBeenPublished", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		events := mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{}, mock.SomethingHappened{})
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg"), 0, events))
+		assert.Ok(t, es.MarkPublished(1))
+
+		// act
+		got, err := es.PendingEvents(1)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []int64{2}, positionsOf(got))
+	})
+
+	t.Run("ItNeverMovesThePublishedPositionBack", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		events := mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{})
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg"), 0, events))
+		assert.Ok(t, es.MarkPublished(2))
+
+		// act
+		assert.Ok(t, es.MarkPublished(1))
+
+		// assert
+		got, err := es.PendingEvents(0)
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
 	})
 }
 
@@ -47,9 +217,90 @@ func TestInMemoryEventStoreStoreEventsFor(t *testing.T) {
 		es := eventstore.NewInInMemoryEventStore()
 
 		// act
-		err := es.StoreEventsFor(ID, 1, []domain.DomainEvent{mock.SomethingHappened{}})
+		err := es.StoreEventsFor(ID, 1, mock.Envelopes(mock.SomethingHappened{}))
 
 		// assert
 		assert.Equals(t, eventstore.ErrConcurrencyViolation, err)
 	})
+
+	t.Run("ItReturnsConcurrencyErrorIfTheStreamHasAlreadyBeenAppended", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := eventstore.NewInInMemoryEventStore()
+		assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(mock.SomethingHappened{})))
+
+		// act
+		err := es.StoreEventsFor(ID, 0, mock.Envelopes(mock.SomethingElseHappened{}))
+
+		// assert
+		assert.Equals(t, eventstore.ErrConcurrencyViolation, err)
+	})
+
+	t.Run("ItAssignsVersionsAndPositions", func(t *testing.T) {
+		// arrange
+		ID1 := mock.StringIdentifier("TestAgg1")
+		ID2 := mock.StringIdentifier("TestAgg2")
+		es := eventstore.NewInInMemoryEventStore()
+
+		first := mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{})
+		second := mock.Envelopes(mock.SomethingHappened{})
+
+		// act
+		assert.Ok(t, es.StoreEventsFor(ID1, 0, first))
+		assert.Ok(t, es.StoreEventsFor(ID2, 0, second))
+
+		// assert
+		assert.Equals(t, ID1.String(), first[1].AggregateID)
+		assert.Equals(t, 2, first[1].Version)
+		assert.Equals(t, int64(2), first[1].Position)
+		assert.Equals(t, 1, second[0].Version)
+		assert.Equals(t, int64(3), second[0].Position)
+
+		got, err := es.LoadEventsFor(ID1)
+		assert.Ok(t, err)
+		assert.Equals(t, first, got)
+	})
+
+	t.Run("OnlyOneConcurrentWriterWins", func(t *testing.T) {
+		// arrange
+		const writers = 100
+
+		ID := mock.StringIdentifier("TestAgg")
+		es := eventstore.NewInInMemoryEventStore()
+
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+
+		// act
+		wg.Add(writers)
+		for i := 0; i < writers; i++ {
+			go func() {
+				defer wg.Done()
+				err := es.StoreEventsFor(ID, 0, mock.Envelopes(mock.SomethingHappened{}))
+				if err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		// assert
+		got, err := es.LoadEventsFor(ID)
+		assert.Ok(t, err)
+		assert.Equals(t, 1, succeeded)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, domain.EventsOf(got))
+	})
+}
+
+func positionsOf(events []domain.Envelope) []int64 {
+	positions := make([]int64, 0, len(events))
+	for _, e := range events {
+		positions = append(positions, e.Position)
+	}
+	return positions
 }
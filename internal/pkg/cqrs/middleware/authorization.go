@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Authorizer decides whether the command may be handled, e.g. by the principal carried by the context.
+type Authorizer func(ctx context.Context, c domain.Command) error
+
+// Authorization rejects the commands which are not authorized.
+func Authorization(authorize Authorizer) Middleware {
+	if authorize == nil {
+		panic("authorize is required")
+	}
+
+	return func(next domain.ContextCommandHandler) domain.ContextCommandHandler {
+		return domain.ContextCommandHandlerFunc(func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+			if err := authorize(ctx, c); err != nil {
+				return nil, err
+			}
+
+			return next.HandleContext(ctx, c)
+		})
+	}
+}
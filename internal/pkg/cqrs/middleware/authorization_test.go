@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/middleware"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+var errAccessDenied = errors.New("access denied")
+
+func TestAuthorization(t *testing.T) {
+	t.Run("ItPanicsIfTheAuthorizerIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			middleware.Authorization(nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItRejectsAnUnauthorizedCommand", func(t *testing.T) {
+		// arrange
+		authorize := func(ctx context.Context, c domain.Command) error {
+			return errAccessDenied
+		}
+
+		// act
+		_, err := middleware.Authorization(authorize)(succeedingHandler()).
+			HandleContext(context.Background(), mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Equals(t, errAccessDenied, err)
+	})
+
+	t.Run("ItPassesAnAuthorizedCommandThrough", func(t *testing.T) {
+		// arrange
+		authorize := func(ctx context.Context, c domain.Command) error {
+			return nil
+		}
+
+		// act
+		events, err := middleware.Authorization(authorize)(succeedingHandler()).
+			HandleContext(context.Background(), mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+	})
+
+	t.Run("ItAuthorizesThePrincipalCarriedByTheContext", func(t *testing.T) {
+		// arrange
+		authorize := func(ctx context.Context, c domain.Command) error {
+			if principal, ok := domain.PrincipalFrom(ctx); !ok || principal != "admin" {
+				return errAccessDenied
+			}
+			return nil
+		}
+		h := middleware.Authorization(authorize)(succeedingHandler())
+
+		// act
+		_, denied := h.HandleContext(domain.WithPrincipal(context.Background(), "guest"), mock.MakeSomethingHappen{})
+		_, allowed := h.HandleContext(domain.WithPrincipal(context.Background(), "admin"), mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Equals(t, errAccessDenied, denied)
+		assert.Ok(t, allowed)
+	})
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Logger is satisfied by log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Logging logs the handled commands and their outcome.
+func Logging(logger Logger) Middleware {
+	if logger == nil {
+		panic("logger is required")
+	}
+
+	return func(next domain.ContextCommandHandler) domain.ContextCommandHandler {
+		return domain.ContextCommandHandlerFunc(func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+			events, err := next.HandleContext(ctx, c)
+			if err != nil {
+				logger.Printf("%s %s %s failed: %v", c.AggregateType(), c.AggregateID(), c.CommandType(), err)
+				return events, err
+			}
+
+			logger.Printf("%s %s %s handled: %d event(s)", c.AggregateType(), c.AggregateID(), c.CommandType(), len(events))
+			return events, nil
+		})
+	}
+}
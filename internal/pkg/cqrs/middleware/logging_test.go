@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/middleware"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+)
+
+func TestLogging(t *testing.T) {
+	t.Run("ItPanicsIfTheLoggerIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			middleware.Logging(nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItLogsTheHandledCommand", func(t *testing.T) {
+		// arrange
+		logger := &loggerMock{}
+		c := mock.MakeSomethingHappen{AggID: mock.StringIdentifier("TestAgg")}
+
+		// act
+		_, err := middleware.Logging(logger)(succeedingHandler()).HandleContext(context.Background(), c)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []string{"mock.TestAggregate TestAgg MakeSomethingHappen handled: 1 event(s)"}, logger.lines)
+	})
+
+	t.Run("ItLogsTheFailedCommand", func(t *testing.T) {
+		// arrange
+		logger := &loggerMock{}
+		c := mock.MakeSomethingHappen{AggID: mock.StringIdentifier("TestAgg")}
+
+		// act
+		_, err := middleware.Logging(logger)(failingHandler(mock.ErrItCanHappenOnceOnly)).
+			HandleContext(context.Background(), c)
+
+		// assert
+		assert.Equals(t, mock.ErrItCanHappenOnceOnly, err)
+		assert.Equals(t, []string{"mock.TestAggregate TestAgg MakeSomethingHappen failed: some business rule error occurred"}, logger.lines)
+	})
+}
+
+type loggerMock struct {
+	lines []string
+}
+
+func (l *loggerMock) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+// Middleware decorates a context-aware command handler.
+//
+// The context is passed through, so the handler still sees its deadline and values, e.g. the principal.
+type Middleware func(next domain.ContextCommandHandler) domain.ContextCommandHandler
+
+// Chain wraps the handler with the given middlewares.
+//
+// The first middleware is the outermost one, so it sees the command first and the result last.
+// Pass the chain through domain.CommandHandlerOf where a domain.CommandHandler is expected,
+// e.g. to drive the sagas or the scheduled commands.
+func Chain(handler domain.ContextCommandHandler, middlewares ...Middleware) domain.ContextCommandHandler {
+	if handler == nil {
+		panic("handler is required")
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/middleware"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestChain(t *testing.T) {
+	t.Run("ItPanicsIfTheHandlerIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			middleware.Chain(nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItCallsTheMiddlewaresInTheGivenOrder", func(t *testing.T) {
+		// arrange
+		var calls []string
+		handler := domain.ContextCommandHandlerFunc(
+			func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+				calls = append(calls, "handler")
+				return []domain.DomainEvent{mock.SomethingHappened{}}, nil
+			})
+
+		// act
+		events, err := middleware.Chain(handler, recordCall(&calls, "first"), recordCall(&calls, "second")).
+			HandleContext(context.Background(), mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+		assert.Equals(t, []string{"first", "second", "handler"}, calls)
+	})
+
+	t.Run("ItPassesTheContextThrough", func(t *testing.T) {
+		// arrange
+		var principal string
+		handler := domain.ContextCommandHandlerFunc(
+			func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+				principal, _ = domain.PrincipalFrom(ctx)
+				return nil, nil
+			})
+		ctx := domain.WithPrincipal(context.Background(), "player1@game.com")
+
+		// act
+		_, err := middleware.Chain(handler, middleware.Recovery(), middleware.Logging(&loggerMock{})).
+			HandleContext(ctx, mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, "player1@game.com", principal)
+	})
+}
+
+func recordCall(calls *[]string, name string) middleware.Middleware {
+	return func(next domain.ContextCommandHandler) domain.ContextCommandHandler {
+		return domain.ContextCommandHandlerFunc(func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+			*calls = append(*calls, name)
+			return next.HandleContext(ctx, c)
+		})
+	}
+}
+
+func succeedingHandler() domain.ContextCommandHandler {
+	return domain.ContextCommandHandlerFunc(func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+		return []domain.DomainEvent{mock.SomethingHappened{}}, nil
+	})
+}
+
+func failingHandler(err error) domain.ContextCommandHandler {
+	return domain.ContextCommandHandlerFunc(func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+		return nil, err
+	})
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// PanicError is returned when a command handler panics.
+type PanicError struct {
+	CommandType string
+	Value       interface{}
+}
+
+// Error implements error interface.
+func (e PanicError) Error() string {
+	return fmt.Sprintf("%s command handler panicked: %v", e.CommandType, e.Value)
+}
+
+// Recovery turns a panic in the command handler into a PanicError.
+func Recovery() Middleware {
+	return func(next domain.ContextCommandHandler) domain.ContextCommandHandler {
+		return domain.ContextCommandHandlerFunc(
+			func(ctx context.Context, c domain.Command) (events []domain.DomainEvent, err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						events, err = nil, PanicError{CommandType: c.CommandType(), Value: r}
+					}
+				}()
+
+				return next.HandleContext(ctx, c)
+			})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/middleware"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestRecovery(t *testing.T) {
+	t.Run("ItTurnsAPanicIntoAnError", func(t *testing.T) {
+		// arrange
+		handler := domain.ContextCommandHandlerFunc(
+			func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+				panic("boom")
+			})
+
+		// act
+		events, err := middleware.Recovery()(handler).HandleContext(context.Background(), mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Equals(t, 0, len(events))
+		assert.Equals(t, middleware.PanicError{CommandType: "MakeSomethingHappen", Value: "boom"}, err)
+		assert.Equals(t, "MakeSomethingHappen command handler panicked: boom", err.Error())
+	})
+
+	t.Run("ItPassesTheResultThrough", func(t *testing.T) {
+		// act
+		events, err := middleware.Recovery()(succeedingHandler()).
+			HandleContext(context.Background(), mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+	})
+}
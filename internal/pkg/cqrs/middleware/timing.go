@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Observer is told how long a command took to handle.
+type Observer func(c domain.Command, elapsed time.Duration, err error)
+
+// Timing measures how long the commands take to handle.
+func Timing(observe Observer) Middleware {
+	if observe == nil {
+		panic("observe is required")
+	}
+
+	return func(next domain.ContextCommandHandler) domain.ContextCommandHandler {
+		return domain.ContextCommandHandlerFunc(func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+			startedAt := time.Now()
+			events, err := next.HandleContext(ctx, c)
+			observe(c, time.Since(startedAt), err)
+
+			return events, err
+		})
+	}
+}
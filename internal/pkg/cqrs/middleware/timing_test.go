@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/middleware"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestTiming(t *testing.T) {
+	t.Run("ItPanicsIfTheObserverIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			middleware.Timing(nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItObservesHowLongTheCommandTook", func(t *testing.T) {
+		// arrange
+		handler := domain.ContextCommandHandlerFunc(
+			func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+				time.Sleep(10 * time.Millisecond)
+				return nil, mock.ErrItCanHappenOnceOnly
+			})
+
+		var (
+			observed domain.Command
+			elapsed  time.Duration
+			failure  error
+		)
+		observe := func(c domain.Command, d time.Duration, err error) {
+			observed, elapsed, failure = c, d, err
+		}
+
+		// act
+		_, err := middleware.Timing(observe)(handler).HandleContext(context.Background(), mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Equals(t, mock.ErrItCanHappenOnceOnly, err)
+		assert.Equals(t, mock.ErrItCanHappenOnceOnly, failure)
+		assert.Equals(t, mock.MakeSomethingHappen{}, observed)
+		assert.True(t, elapsed >= 10*time.Millisecond)
+	})
+}
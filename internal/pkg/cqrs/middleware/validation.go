@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Validator is a command which can check itself.
+type Validator interface {
+	Validate() error
+}
+
+// Validation rejects the commands which fail their own validation.
+//
+// The commands which don't implement Validator are passed through.
+func Validation() Middleware {
+	return func(next domain.ContextCommandHandler) domain.ContextCommandHandler {
+		return domain.ContextCommandHandlerFunc(func(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+			if v, ok := c.(Validator); ok {
+				if err := v.Validate(); err != nil {
+					return nil, err
+				}
+			}
+
+			return next.HandleContext(ctx, c)
+		})
+	}
+}
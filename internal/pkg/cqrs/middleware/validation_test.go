@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/middleware"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+var errInvalidCommand = errors.New("invalid command")
+
+func TestValidation(t *testing.T) {
+	t.Run("ItRejectsAnInvalidCommand", func(t *testing.T) {
+		// act
+		_, err := middleware.Validation()(succeedingHandler()).
+			HandleContext(context.Background(), validatedCommand{err: errInvalidCommand})
+
+		// assert
+		assert.Equals(t, errInvalidCommand, err)
+	})
+
+	t.Run("ItPassesAValidCommandThrough", func(t *testing.T) {
+		// act
+		events, err := middleware.Validation()(succeedingHandler()).
+			HandleContext(context.Background(), validatedCommand{})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, events)
+	})
+
+	t.Run("ItPassesACommandWithoutValidationThrough", func(t *testing.T) {
+		// act
+		_, err := middleware.Validation()(succeedingHandler()).
+			HandleContext(context.Background(), mock.MakeSomethingHappen{})
+
+		// assert
+		assert.Ok(t, err)
+	})
+}
+
+type validatedCommand struct {
+	mock.MakeSomethingHappen
+	err error
+}
+
+func (c validatedCommand) Validate() error {
+	return c.err
+}
@@ -0,0 +1,155 @@
+package outbox
+
+import (
+	"sync"
+	"time"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+const (
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+)
+
+// Option configures Relay.
+type Option func(*Relay)
+
+// WithBatchSize sets how many pending events are published at once.
+func WithBatchSize(size int) Option {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// WithPollInterval sets how often the outbox is checked for pending events if the relay hasn't been woken up.
+func WithPollInterval(interval time.Duration) Option {
+	return func(r *Relay) {
+		r.pollInterval = interval
+	}
+}
+
+// WithErrorHandler sets a function which is called when the events cannot be published in the background.
+func WithErrorHandler(onError func(error)) Option {
+	return func(r *Relay) {
+		r.onError = onError
+	}
+}
+
+// Relay publishes the events pending in the outbox and marks them as published.
+//
+// The events are published at least once: if publishing fails, they stay pending and are published again later.
+// The relay is also an event publisher: given to the dispatcher, it is woken up as soon as the events are stored,
+// so the command succeeds once the events are durable regardless of the subscribers.
+type Relay struct {
+	outbox       domain.Outbox
+	publisher    domain.EventPublisher
+	batchSize    int
+	pollInterval time.Duration
+	onError      func(error)
+
+	flushMu   sync.Mutex
+	wakeUp    chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewRelay creates a new instance of Relay.
+func NewRelay(outbox domain.Outbox, publisher domain.EventPublisher, opts ...Option) *Relay {
+	if outbox == nil {
+		panic("outbox is required")
+	}
+
+	if publisher == nil {
+		panic("publisher is required")
+	}
+
+	r := &Relay{
+		outbox:       outbox,
+		publisher:    publisher,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+		onError:      func(error) {},
+		wakeUp:       make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Publish implements domain.EventPublisher interface.
+//
+// It only wakes the relay up, the events themselves are read from the outbox.
+func (r *Relay) Publish(...domain.Envelope) error {
+	select {
+	case r.wakeUp <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Flush publishes all the pending events.
+func (r *Relay) Flush() error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	for {
+		events, err := r.outbox.PendingEvents(r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := r.publisher.Publish(events...); err != nil {
+			return err
+		}
+
+		if err := r.outbox.MarkPublished(events[len(events)-1].Position); err != nil {
+			return err
+		}
+	}
+}
+
+// Start starts publishing the pending events in the background.
+func (r *Relay) Start() {
+	r.startOnce.Do(func() {
+		r.wg.Add(1)
+		go r.run()
+	})
+}
+
+// Stop stops the relay and waits until the publishing in progress is finished.
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.done)
+	})
+	r.wg.Wait()
+}
+
+func (r *Relay) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Flush(); err != nil {
+			r.onError(err)
+		}
+
+		select {
+		case <-r.wakeUp:
+		case <-ticker.C:
+		case <-r.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package outbox_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/outbox"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that Relay implements domain.EventPublisher interface.
+var _ domain.EventPublisher = (*outbox.Relay)(nil)
+
+func TestNewRelay(t *testing.T) {
+	t.Run("ItPanicsIfOutboxIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			outbox.NewRelay(nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfPublisherIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			outbox.NewRelay(eventstore.NewInInMemoryEventStore(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestRelayFlush(t *testing.T) {
+	t.Run("ItPublishesThePendingEventsAndMarksThemPublished", func(t *testing.T) {
+		// arrange
+		es := createEventStore(t)
+		var published []domain.DomainEvent
+		r := outbox.NewRelay(es, &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				published = append(published, domain.EventsOf(e)...)
+				return nil
+			},
+		}, outbox.WithBatchSize(1))
+
+		// act
+		err := r.Flush()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}, mock.SomethingElseHappened{}}, published)
+
+		pending, err := es.PendingEvents(0)
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(pending))
+	})
+
+	t.Run("ItKeepsTheEventsPendingIfTheyCannotBePublished", func(t *testing.T) {
+		// arrange
+		es := createEventStore(t)
+		r := outbox.NewRelay(es, &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				return mock.ErrCannotPublishEvents
+			},
+		})
+
+		// act
+		err := r.Flush()
+
+		// assert
+		assert.Equals(t, mock.ErrCannotPublishEvents, err)
+
+		pending, err := es.PendingEvents(0)
+		assert.Ok(t, err)
+		assert.Equals(t, 2, len(pending))
+	})
+}
+
+func TestRelayStart(t *testing.T) {
+	t.Run("ItPublishesTheEventsWhenWokenUp", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		published := make(chan domain.Envelope, 1)
+		r := outbox.NewRelay(es, &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				for _, envelope := range e {
+					published <- envelope
+				}
+				return nil
+			},
+		}, outbox.WithPollInterval(time.Hour))
+
+		r.Start()
+		defer r.Stop()
+
+		// act
+		events := mock.Envelopes(mock.SomethingHappened{})
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg"), 0, events))
+		assert.Ok(t, r.Publish(events...))
+
+		// assert
+		assert.Equals(t, int64(1), (<-published).Position)
+	})
+
+	t.Run("ItReportsTheErrors", func(t *testing.T) {
+		// arrange
+		errs := make(chan error, 1)
+		r := outbox.NewRelay(createEventStore(t), &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				return mock.ErrCannotPublishEvents
+			},
+		}, outbox.WithErrorHandler(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}))
+
+		// act
+		r.Start()
+		defer r.Stop()
+
+		// assert
+		assert.Equals(t, mock.ErrCannotPublishEvents, <-errs)
+	})
+}
+
+func createEventStore(t *testing.T) *eventstore.InMemoryEventStore {
+	t.Helper()
+
+	es := eventstore.NewInInMemoryEventStore()
+	assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, mock.Envelopes(mock.SomethingHappened{})))
+	assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg2"), 0, mock.Envelopes(mock.SomethingElseHappened{})))
+	return es
+}
@@ -0,0 +1,195 @@
+package projection
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/subscription"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+var (
+	// ErrProjectionCannotBeReset happens if a projection which doesn't implement domain.Resetter is rebuilt.
+	ErrProjectionCannotBeReset = errors.New("projection cannot be reset")
+)
+
+// Runner runs a projection over the global event log keeping track of its checkpoint.
+//
+// The projection resumes from the stored checkpoint, so after a restart only the new events are processed.
+// The runner is also an event handler: being registered on an event bus it makes the projection follow
+// the live events without waiting for the next poll.
+type Runner struct {
+	name        string
+	eventLog    domain.EventLog
+	projector   domain.EventHandler
+	checkpoints domain.CheckpointStore
+	opts        []subscription.Option
+
+	current atomic.Value
+	running bool
+	mu      sync.Mutex
+}
+
+// NewRunner creates a new instance of Runner.
+//
+// The options configure the underlying subscription.
+func NewRunner(
+	name string, eventLog domain.EventLog, projector domain.EventHandler, checkpoints domain.CheckpointStore,
+	opts ...subscription.Option) *Runner {
+	if name == "" {
+		panic("name is required")
+	}
+
+	if eventLog == nil {
+		panic("eventLog is required")
+	}
+
+	if projector == nil {
+		panic("projector is required")
+	}
+
+	if checkpoints == nil {
+		panic("checkpoints is required")
+	}
+
+	return &Runner{
+		name:        name,
+		eventLog:    eventLog,
+		projector:   projector,
+		checkpoints: checkpoints,
+		opts:        opts,
+	}
+}
+
+// Position returns the global position of the last processed event.
+func (r *Runner) Position() int64 {
+	if s := r.subscription(); s != nil {
+		return s.Position()
+	}
+	return 0
+}
+
+// CatchUp processes all the events which are in the log at the moment.
+func (r *Runner) CatchUp() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.resume()
+	if err != nil {
+		return err
+	}
+	return s.CatchUp()
+}
+
+// Start keeps the projection up to date in the background.
+func (r *Runner) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.resume()
+	if err != nil {
+		return err
+	}
+
+	s.Start()
+	r.running = true
+	return nil
+}
+
+// Stop stops the projection, it can be started again later.
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stop()
+}
+
+// Rebuild drops the read model, resets the checkpoint and replays the whole log.
+//
+// A running projection is stopped for the time of the rebuild and started again afterwards.
+func (r *Runner) Rebuild() error {
+	resetter, ok := r.projector.(domain.Resetter)
+	if !ok {
+		return ErrProjectionCannotBeReset
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wasRunning := r.running
+	r.stop()
+
+	if err := resetter.Reset(); err != nil {
+		return err
+	}
+
+	if err := r.checkpoints.StoreCheckpoint(r.name, 0); err != nil {
+		return err
+	}
+
+	s := r.subscribe(0)
+	err := s.CatchUp()
+
+	if wasRunning {
+		s.Start()
+		r.running = true
+	}
+	return err
+}
+
+// SubscribedTo implements domain.EventHandler interface.
+func (r *Runner) SubscribedTo() domain.EventMatcher {
+	return domain.MatchAny()
+}
+
+// Handle implements domain.EventHandler interface.
+//
+// It only wakes the projection up, the event itself is read from the log.
+func (r *Runner) Handle(e domain.Envelope) error {
+	if s := r.subscription(); s != nil {
+		return s.Handle(e)
+	}
+	return nil
+}
+
+func (r *Runner) subscription() *subscription.Subscription {
+	s, _ := r.current.Load().(*subscription.Subscription)
+	return s
+}
+
+// resume creates the subscription from the stored checkpoint unless it already exists.
+func (r *Runner) resume() (*subscription.Subscription, error) {
+	if s := r.subscription(); s != nil {
+		return s, nil
+	}
+
+	position, err := r.checkpoints.LoadCheckpoint(r.name)
+	if err != nil {
+		return nil, err
+	}
+	return r.subscribe(position), nil
+}
+
+func (r *Runner) subscribe(position int64) *subscription.Subscription {
+	opts := append(r.opts[:len(r.opts):len(r.opts)],
+		subscription.FromPosition(position),
+		subscription.WithCheckpoint(func(position int64) error {
+			return r.checkpoints.StoreCheckpoint(r.name, position)
+		}),
+	)
+
+	s := subscription.New(r.eventLog, r.projector, opts...)
+	r.current.Store(s)
+	return s
+}
+
+// stop stops the running subscription and replaces it with an idle one, which continues from the same position.
+func (r *Runner) stop() {
+	if s := r.subscription(); s != nil && r.running {
+		s.Stop()
+		r.subscribe(s.Position())
+	}
+	r.running = false
+}
@@ -0,0 +1,201 @@
+package projection_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/checkpointstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventbus"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/projection"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/subscription"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that Runner implements domain.EventHandler interface.
+var _ domain.EventHandler = (*projection.Runner)(nil)
+
+func TestNewRunner(t *testing.T) {
+	t.Run("ItPanicsIfNameIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			projection.NewRunner("", nil, nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfEventLogIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			projection.NewRunner("Test", nil, nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfProjectorIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			projection.NewRunner("Test", eventstore.NewInInMemoryEventStore(), nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfCheckpointStoreIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			projection.NewRunner("Test", eventstore.NewInInMemoryEventStore(), &mock.EventHandlerMock{}, nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestRunnerCatchUp(t *testing.T) {
+	t.Run("ItResumesFromTheCheckpoint", func(t *testing.T) {
+		// arrange
+		checkpoints := checkpointstore.NewInMemoryCheckpointStore()
+		assert.Ok(t, checkpoints.StoreCheckpoint("Test", 1))
+
+		projector := &projectorMock{}
+		r := projection.NewRunner("Test", createEventStore(t), projector, checkpoints)
+
+		// act
+		err := r.CatchUp()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []int64{2, 3}, projector.positions())
+		assert.Equals(t, int64(3), r.Position())
+	})
+
+	t.Run("ItStoresTheCheckpoint", func(t *testing.T) {
+		// arrange
+		checkpoints := checkpointstore.NewInMemoryCheckpointStore()
+		r := projection.NewRunner("Test", createEventStore(t), &projectorMock{}, checkpoints)
+
+		// act
+		err := r.CatchUp()
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := checkpoints.LoadCheckpoint("Test")
+		assert.Ok(t, err)
+		assert.Equals(t, int64(3), got)
+	})
+}
+
+func TestRunnerRebuild(t *testing.T) {
+	t.Run("ItFailsIfTheProjectionCannotBeReset", func(t *testing.T) {
+		// arrange
+		r := projection.NewRunner("Test", createEventStore(t), &mock.EventHandlerMock{},
+			checkpointstore.NewInMemoryCheckpointStore())
+
+		// act
+		err := r.Rebuild()
+
+		// assert
+		assert.Equals(t, projection.ErrProjectionCannotBeReset, err)
+	})
+
+	t.Run("ItResetsTheProjectionAndReplaysTheWholeLog", func(t *testing.T) {
+		// arrange
+		checkpoints := checkpointstore.NewInMemoryCheckpointStore()
+		projector := &projectorMock{}
+		r := projection.NewRunner("Test", createEventStore(t), projector, checkpoints)
+		assert.Ok(t, r.CatchUp())
+
+		// act
+		err := r.Rebuild()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 1, projector.resets)
+		assert.Equals(t, []int64{1, 2, 3}, projector.positions())
+
+		got, err := checkpoints.LoadCheckpoint("Test")
+		assert.Ok(t, err)
+		assert.Equals(t, int64(3), got)
+	})
+}
+
+func TestRunnerStart(t *testing.T) {
+	t.Run("ItFollowsTheLiveEventsAfterRestart", func(t *testing.T) {
+		// arrange
+		es := createEventStore(t)
+		projector := &projectorMock{delivered: make(chan int64, 10)}
+		r := projection.NewRunner("Test", es, projector, checkpointstore.NewInMemoryCheckpointStore(),
+			subscription.WithPollInterval(time.Hour))
+
+		bus := eventbus.NewInMemoryEventBus()
+		bus.Register(r)
+
+		assert.Ok(t, r.Start())
+		for i := 0; i < 3; i++ {
+			<-projector.delivered
+		}
+		r.Stop()
+
+		// act
+		assert.Ok(t, r.Start())
+		defer r.Stop()
+
+		live := mock.Envelopes(mock.SomethingHappened{})
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg3"), 0, live))
+		assert.Ok(t, bus.Publish(live...))
+
+		// assert
+		assert.Equals(t, int64(4), <-projector.delivered)
+	})
+}
+
+type projectorMock struct {
+	handled   []domain.Envelope
+	resets    int
+	delivered chan int64
+	mu        sync.Mutex
+}
+
+func (p *projectorMock) SubscribedTo() domain.EventMatcher {
+	return domain.MatchAny()
+}
+
+func (p *projectorMock) Handle(e domain.Envelope) error {
+	p.mu.Lock()
+	p.handled = append(p.handled, e)
+	p.mu.Unlock()
+
+	if p.delivered != nil {
+		p.delivered <- e.Position
+	}
+	return nil
+}
+
+func (p *projectorMock) Reset() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.handled = nil
+	p.resets++
+	return nil
+}
+
+func (p *projectorMock) positions() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var positions []int64
+	for _, e := range p.handled {
+		positions = append(positions, e.Position)
+	}
+	return positions
+}
+
+func createEventStore(t *testing.T) *eventstore.InMemoryEventStore {
+	t.Helper()
+
+	es := eventstore.NewInInMemoryEventStore()
+	assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, mock.Envelopes(mock.SomethingHappened{})))
+	assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg2"), 0, mock.Envelopes(mock.SomethingElseHappened{})))
+	assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 1, mock.Envelopes(mock.SomethingHappened{})))
+	return es
+}
@@ -0,0 +1,45 @@
+package querydispatcher
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Dispatcher routes queries to the handlers registered for their types.
+type Dispatcher struct {
+	queryHandlers   map[string]domain.QueryHandler
+	queryHandlersMu sync.RWMutex
+}
+
+// NewDispatcher creates a new instance of Dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		queryHandlers: make(map[string]domain.QueryHandler),
+	}
+}
+
+// Register registers the handler for the queries of the given type, replacing the previous one.
+func (d *Dispatcher) Register(queryType string, h domain.QueryHandler) {
+	if h == nil {
+		panic("queryHandler is required")
+	}
+
+	d.queryHandlersMu.Lock()
+	defer d.queryHandlersMu.Unlock()
+
+	d.queryHandlers[queryType] = h
+}
+
+// Handle implements domain.QueryHandler interface.
+func (d *Dispatcher) Handle(q domain.Query) (interface{}, error) {
+	d.queryHandlersMu.RLock()
+	h, ok := d.queryHandlers[q.QueryType()]
+	d.queryHandlersMu.RUnlock()
+
+	if !ok {
+		return nil, errors.New(q.QueryType() + " query handler is not registered")
+	}
+	return h.Handle(q)
+}
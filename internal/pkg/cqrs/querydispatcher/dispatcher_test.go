@@ -0,0 +1,81 @@
+package querydispatcher_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/querydispatcher"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that dispatcher implements domain.QueryHandler interface.
+var _ domain.QueryHandler = (*querydispatcher.Dispatcher)(nil)
+
+var errCannotAnswerQuery = errors.New("cannot answer query")
+
+type getSomething struct {
+	ID string
+}
+
+func (q getSomething) QueryType() string {
+	return "GetSomething"
+}
+
+func TestNewDispatcher(t *testing.T) {
+	t.Run("ItCreatesDispatcher", func(t *testing.T) {
+		assert.True(t, querydispatcher.NewDispatcher() != nil)
+	})
+}
+
+func TestDispatcherRegister(t *testing.T) {
+	t.Run("ItPanicsIfQueryHandlerIsNotGiven", func(t *testing.T) {
+		register := func() {
+			querydispatcher.NewDispatcher().Register("GetSomething", nil)
+		}
+		assert.Panic(t, register)
+	})
+}
+
+func TestDispatcherHandle(t *testing.T) {
+	t.Run("ItFailsIfTheQueryHandlerIsNotRegistered", func(t *testing.T) {
+		// arrange
+		d := querydispatcher.NewDispatcher()
+
+		// act
+		_, err := d.Handle(getSomething{ID: "s1"})
+
+		// assert
+		assert.Equals(t, errors.New("GetSomething query handler is not registered"), err)
+	})
+
+	t.Run("ItReturnsAnErrorIfTheQueryHandlerFails", func(t *testing.T) {
+		// arrange
+		d := querydispatcher.NewDispatcher()
+		d.Register("GetSomething", domain.QueryHandlerFunc(func(domain.Query) (interface{}, error) {
+			return nil, errCannotAnswerQuery
+		}))
+
+		// act
+		_, err := d.Handle(getSomething{ID: "s1"})
+
+		// assert
+		assert.Equals(t, errCannotAnswerQuery, err)
+	})
+
+	t.Run("ItRoutesTheQueryToTheRegisteredHandler", func(t *testing.T) {
+		// arrange
+		d := querydispatcher.NewDispatcher()
+		d.Register("GetSomething", domain.QueryHandlerFunc(func(q domain.Query) (interface{}, error) {
+			return "something " + q.(getSomething).ID, nil
+		}))
+
+		// act
+		got, err := d.Handle(getSomething{ID: "s1"})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, "something s1", got)
+	})
+}
@@ -0,0 +1,76 @@
+package readmodel
+
+import (
+	"sort"
+	"sync"
+)
+
+// InMemoryRepository stores read models in memory.
+//
+// The read models are stored as they are given, so they should be values rather than pointers
+// to prevent them from being changed behind the repository's back.
+type InMemoryRepository struct {
+	readModels   map[string]interface{}
+	readModelsMu sync.RWMutex
+}
+
+// NewInMemoryRepository creates a new instance of InMemoryRepository.
+func NewInMemoryRepository() *InMemoryRepository {
+	return &InMemoryRepository{
+		readModels: make(map[string]interface{}),
+	}
+}
+
+// Find implements domain.ReadModelRepository interface.
+func (r *InMemoryRepository) Find(ID string) (interface{}, bool, error) {
+	r.readModelsMu.RLock()
+	defer r.readModelsMu.RUnlock()
+
+	readModel, ok := r.readModels[ID]
+	return readModel, ok, nil
+}
+
+// FindAll implements domain.ReadModelRepository interface.
+func (r *InMemoryRepository) FindAll() ([]interface{}, error) {
+	r.readModelsMu.RLock()
+	defer r.readModelsMu.RUnlock()
+
+	IDs := make([]string, 0, len(r.readModels))
+	for ID := range r.readModels {
+		IDs = append(IDs, ID)
+	}
+	sort.Strings(IDs)
+
+	readModels := make([]interface{}, 0, len(IDs))
+	for _, ID := range IDs {
+		readModels = append(readModels, r.readModels[ID])
+	}
+	return readModels, nil
+}
+
+// Save implements domain.ReadModelRepository interface.
+func (r *InMemoryRepository) Save(ID string, readModel interface{}) error {
+	r.readModelsMu.Lock()
+	defer r.readModelsMu.Unlock()
+
+	r.readModels[ID] = readModel
+	return nil
+}
+
+// Remove implements domain.ReadModelRepository interface.
+func (r *InMemoryRepository) Remove(ID string) error {
+	r.readModelsMu.Lock()
+	defer r.readModelsMu.Unlock()
+
+	delete(r.readModels, ID)
+	return nil
+}
+
+// Clear implements domain.ReadModelRepository interface.
+func (r *InMemoryRepository) Clear() error {
+	r.readModelsMu.Lock()
+	defer r.readModelsMu.Unlock()
+
+	r.readModels = make(map[string]interface{})
+	return nil
+}
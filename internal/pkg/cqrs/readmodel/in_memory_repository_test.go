@@ -0,0 +1,106 @@
+package readmodel_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/readmodel"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that repository implements domain.ReadModelRepository interface.
+var _ domain.ReadModelRepository = (*readmodel.InMemoryRepository)(nil)
+
+type testReadModel struct {
+	ID    string
+	Count int
+}
+
+func TestNewInMemoryRepository(t *testing.T) {
+	t.Run("ItCreatesRepository", func(t *testing.T) {
+		assert.True(t, readmodel.NewInMemoryRepository() != nil)
+	})
+}
+
+func TestInMemoryRepositoryFind(t *testing.T) {
+	t.Run("ItReportsAnUnknownReadModel", func(t *testing.T) {
+		// arrange
+		r := readmodel.NewInMemoryRepository()
+
+		// act
+		_, ok, err := r.Find("r1")
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, !ok)
+	})
+
+	t.Run("ItFindsTheSavedReadModel", func(t *testing.T) {
+		// arrange
+		r := readmodel.NewInMemoryRepository()
+		assert.Ok(t, r.Save("r1", testReadModel{ID: "r1", Count: 1}))
+		assert.Ok(t, r.Save("r1", testReadModel{ID: "r1", Count: 2}))
+
+		// act
+		got, ok, err := r.Find("r1")
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, ok)
+		assert.Equals(t, testReadModel{ID: "r1", Count: 2}, got)
+	})
+}
+
+func TestInMemoryRepositoryFindAll(t *testing.T) {
+	t.Run("ItFindsAllTheReadModelsOrderedByID", func(t *testing.T) {
+		// arrange
+		r := readmodel.NewInMemoryRepository()
+		assert.Ok(t, r.Save("r2", testReadModel{ID: "r2"}))
+		assert.Ok(t, r.Save("r1", testReadModel{ID: "r1"}))
+
+		// act
+		got, err := r.FindAll()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []interface{}{testReadModel{ID: "r1"}, testReadModel{ID: "r2"}}, got)
+	})
+}
+
+func TestInMemoryRepositoryRemove(t *testing.T) {
+	t.Run("ItRemovesTheReadModel", func(t *testing.T) {
+		// arrange
+		r := readmodel.NewInMemoryRepository()
+		assert.Ok(t, r.Save("r1", testReadModel{ID: "r1"}))
+		assert.Ok(t, r.Save("r2", testReadModel{ID: "r2"}))
+
+		// act
+		err := r.Remove("r1")
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := r.FindAll()
+		assert.Ok(t, err)
+		assert.Equals(t, []interface{}{testReadModel{ID: "r2"}}, got)
+	})
+}
+
+func TestInMemoryRepositoryClear(t *testing.T) {
+	t.Run("ItRemovesAllTheReadModels", func(t *testing.T) {
+		// arrange
+		r := readmodel.NewInMemoryRepository()
+		assert.Ok(t, r.Save("r1", testReadModel{ID: "r1"}))
+
+		// act
+		err := r.Clear()
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := r.FindAll()
+		assert.Ok(t, err)
+		assert.Equals(t, []interface{}{}, got)
+	})
+}
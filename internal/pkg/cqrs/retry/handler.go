@@ -0,0 +1,81 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/deadletter"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// HandlerOption configures Handler.
+type HandlerOption func(*Handler)
+
+// WithDeadLetters makes the handler record the events it has failed to handle instead of returning the error.
+//
+// The wrapped handler is registered in the queue, so that the dead letters can be replayed.
+func WithDeadLetters(deadLetters *deadletter.Queue) HandlerOption {
+	if deadLetters == nil {
+		panic("deadLetters is required")
+	}
+
+	return func(h *Handler) {
+		h.deadLetters = deadLetters
+		deadLetters.RegisterHandler(h.name, h.handler)
+	}
+}
+
+// Handler retries the wrapped event handler according to the policy.
+type Handler struct {
+	name        string
+	handler     domain.EventHandler
+	policy      Policy
+	deadLetters *deadletter.Queue
+}
+
+// NewHandler creates a new instance of Handler.
+func NewHandler(name string, handler domain.EventHandler, policy Policy, opts ...HandlerOption) *Handler {
+	if name == "" {
+		panic("name is required")
+	}
+
+	if handler == nil {
+		panic("handler is required")
+	}
+
+	h := &Handler{
+		name:    name,
+		handler: handler,
+		policy:  policy,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// SubscribedTo implements domain.EventHandler interface.
+func (h *Handler) SubscribedTo() domain.EventMatcher {
+	return h.handler.SubscribedTo()
+}
+
+// Handle implements domain.EventHandler interface.
+func (h *Handler) Handle(e domain.Envelope) error {
+	return h.HandleContext(context.Background(), e)
+}
+
+// HandleContext implements domain.ContextEventHandler interface.
+//
+// The retries stop once the context is done, such an event is not recorded as a dead letter.
+func (h *Handler) HandleContext(ctx context.Context, e domain.Envelope) error {
+	handler := domain.ContextEventHandlerOf(h.handler)
+	attempts, err := h.policy.DoContext(ctx, func(ctx context.Context) error {
+		return handler.HandleContext(ctx, e)
+	})
+	if err == nil || h.deadLetters == nil || ctx.Err() != nil {
+		return err
+	}
+
+	return h.deadLetters.Add(h.name, e, err, attempts)
+}
@@ -0,0 +1,197 @@
+package retry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/deadletter"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/retry"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that Handler implements domain.EventHandler interface.
+var _ domain.EventHandler = (*retry.Handler)(nil)
+
+// ensure that Handler implements domain.ContextEventHandler interface.
+var _ domain.ContextEventHandler = (*retry.Handler)(nil)
+
+func TestNewHandler(t *testing.T) {
+	t.Run("ItPanicsIfNameIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			retry.NewHandler("", nil, retry.DefaultPolicy())
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfHandlerIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			retry.NewHandler("Test", nil, retry.DefaultPolicy())
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfDeadLettersAreNotGiven", func(t *testing.T) {
+		factory := func() {
+			retry.WithDeadLetters(nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestHandlerSubscribedTo(t *testing.T) {
+	t.Run("ItSubscribesToTheEventsOfTheWrappedHandler", func(t *testing.T) {
+		h := retry.NewHandler("Test", &mock.EventHandlerMock{Matcher: domain.MatchEvent("SomethingHappened")}, retry.Policy{})
+
+		matcher := h.SubscribedTo()
+
+		assert.True(t, matcher(mock.SomethingHappened{}))
+		assert.True(t, !matcher(mock.SomethingElseHappened{}))
+	})
+}
+
+func TestHandlerHandle(t *testing.T) {
+	t.Run("ItRetriesTheFailedHandler", func(t *testing.T) {
+		// arrange
+		wrapped := &flakyHandler{failures: 2}
+		h := retry.NewHandler("Test", wrapped, retry.Policy{MaxAttempts: 3})
+
+		// act
+		err := h.Handle(mock.Envelopes(mock.SomethingHappened{})[0])
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 3, wrapped.calls)
+	})
+
+	t.Run("ItReturnsTheErrorIfThereAreNoDeadLetters", func(t *testing.T) {
+		// arrange
+		h := retry.NewHandler("Test", &flakyHandler{failures: 5}, retry.Policy{MaxAttempts: 2})
+
+		// act
+		err := h.Handle(mock.Envelopes(mock.SomethingHappened{})[0])
+
+		// assert
+		assert.Equals(t, mock.ErrCannotHandleEvent, err)
+	})
+
+	t.Run("ItRecordsADeadLetterWhenTheAttemptsAreExhausted", func(t *testing.T) {
+		// arrange
+		deadLetters := deadletter.NewQueue(deadletter.NewInMemoryStore())
+		h := retry.NewHandler("Test", &flakyHandler{failures: 5}, retry.Policy{MaxAttempts: 2},
+			retry.WithDeadLetters(deadLetters))
+
+		e := domain.Envelope{ID: "e1", Event: mock.SomethingHappened{}}
+
+		// act
+		err := h.Handle(e)
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := deadLetters.List()
+		assert.Ok(t, err)
+		assert.Equals(t, 1, len(got))
+		assert.Equals(t, "Test", got[0].Handler)
+		assert.Equals(t, e, got[0].Envelope)
+		assert.Equals(t, mock.ErrCannotHandleEvent.Error(), got[0].Error)
+		assert.Equals(t, 2, got[0].Attempts)
+	})
+
+	t.Run("ItLetsTheDeadLettersBeReplayed", func(t *testing.T) {
+		// arrange
+		deadLetters := deadletter.NewQueue(deadletter.NewInMemoryStore())
+		wrapped := &flakyHandler{failures: 2}
+		h := retry.NewHandler("Test", wrapped, retry.Policy{MaxAttempts: 2}, retry.WithDeadLetters(deadLetters))
+		assert.Ok(t, h.Handle(mock.Envelopes(mock.SomethingHappened{})[0]))
+
+		failed, err := deadLetters.List()
+		assert.Ok(t, err)
+
+		// act
+		err = deadLetters.Replay(failed[0].ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 3, wrapped.calls)
+	})
+}
+
+func TestHandlerHandleContext(t *testing.T) {
+	t.Run("ItPassesTheContextToTheWrappedHandler", func(t *testing.T) {
+		// arrange
+		wrapped := &contextHandler{}
+		h := retry.NewHandler("Test", wrapped, retry.Policy{MaxAttempts: 1})
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+		// act
+		err := h.HandleContext(ctx, mock.Envelopes(mock.SomethingHappened{})[0])
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, "value", wrapped.value)
+	})
+
+	t.Run("ItStopsRetryingOnceTheContextIsDone", func(t *testing.T) {
+		// arrange
+		deadLetters := deadletter.NewQueue(deadletter.NewInMemoryStore())
+		wrapped := &flakyHandler{failures: 5}
+		h := retry.NewHandler("Test", wrapped, retry.Policy{MaxAttempts: 3, InitialDelay: time.Hour},
+			retry.WithDeadLetters(deadLetters))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wrapped.onHandle = cancel
+
+		// act
+		err := h.HandleContext(ctx, mock.Envelopes(mock.SomethingHappened{})[0])
+
+		// assert
+		assert.Equals(t, context.Canceled, err)
+		assert.Equals(t, 1, wrapped.calls)
+
+		got, err := deadLetters.List()
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+type flakyHandler struct {
+	failures int
+	calls    int
+	onHandle func()
+}
+
+func (h *flakyHandler) SubscribedTo() domain.EventMatcher {
+	return domain.MatchAny()
+}
+
+func (h *flakyHandler) Handle(domain.Envelope) error {
+	h.calls++
+	if h.onHandle != nil {
+		h.onHandle()
+	}
+	if h.calls <= h.failures {
+		return mock.ErrCannotHandleEvent
+	}
+	return nil
+}
+
+type contextHandler struct {
+	value interface{}
+}
+
+func (h *contextHandler) SubscribedTo() domain.EventMatcher {
+	return domain.MatchAny()
+}
+
+func (h *contextHandler) Handle(e domain.Envelope) error {
+	return h.HandleContext(context.Background(), e)
+}
+
+func (h *contextHandler) HandleContext(ctx context.Context, _ domain.Envelope) error {
+	h.value = ctx.Value(ctxKey{})
+	return nil
+}
@@ -0,0 +1,89 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Policy defines how many times and how often a failed operation is retried.
+//
+// The delay grows exponentially from InitialDelay by Multiplier up to MaxDelay.
+type Policy struct {
+	// MaxAttempts is the number of attempts including the first one.
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	// Jitter is the fraction of the delay which is randomized, from 0 to 1.
+	Jitter float64
+}
+
+// DefaultPolicy returns a policy which makes up to 5 attempts starting with a 50ms delay.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:  5,
+		InitialDelay: 50 * time.Millisecond,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// Delay returns how long to wait before the given retry, the first retry is 1.
+func (p Policy) Delay(retry int) time.Duration {
+	if retry < 1 {
+		return 0
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(math.Max(p.Multiplier, 1), float64(retry-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// Do calls the given function until it succeeds or the attempts are exhausted.
+//
+// It returns the number of the attempts made and the last error.
+func (p Policy) Do(fn func() error) (int, error) {
+	return p.DoContext(context.Background(), func(context.Context) error {
+		return fn()
+	})
+}
+
+// DoContext calls the given function until it succeeds, the attempts are exhausted or the context is done.
+//
+// It returns the number of the attempts made and the last error, or the context error if it is done while waiting.
+func (p Policy) DoContext(ctx context.Context, fn func(ctx context.Context) error) (int, error) {
+	attempt := 1
+	for {
+		err := fn(ctx)
+		if err == nil || attempt >= p.MaxAttempts {
+			return attempt, err
+		}
+
+		if err := wait(ctx, p.Delay(attempt)); err != nil {
+			return attempt, err
+		}
+		attempt++
+	}
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
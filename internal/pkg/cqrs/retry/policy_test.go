@@ -0,0 +1,123 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/retry"
+)
+
+var errTemporary = errors.New("temporary error")
+
+func TestDefaultPolicy(t *testing.T) {
+	t.Run("ItRetriesSeveralTimes", func(t *testing.T) {
+		assert.True(t, retry.DefaultPolicy().MaxAttempts > 1)
+	})
+}
+
+func TestPolicyDelay(t *testing.T) {
+	t.Run("ItGrowsExponentially", func(t *testing.T) {
+		p := retry.Policy{InitialDelay: time.Millisecond, Multiplier: 2}
+
+		assert.Equals(t, time.Duration(0), p.Delay(0))
+		assert.Equals(t, time.Millisecond, p.Delay(1))
+		assert.Equals(t, 2*time.Millisecond, p.Delay(2))
+		assert.Equals(t, 4*time.Millisecond, p.Delay(3))
+	})
+
+	t.Run("ItIsCappedByMaxDelay", func(t *testing.T) {
+		p := retry.Policy{InitialDelay: time.Millisecond, MaxDelay: 3 * time.Millisecond, Multiplier: 2}
+
+		assert.Equals(t, 3*time.Millisecond, p.Delay(10))
+	})
+
+	t.Run("ItAddsJitter", func(t *testing.T) {
+		p := retry.Policy{InitialDelay: 100 * time.Millisecond, Multiplier: 1, Jitter: 0.5}
+
+		for i := 0; i < 100; i++ {
+			delay := p.Delay(1)
+			assert.True(t, delay >= 50*time.Millisecond && delay <= 150*time.Millisecond)
+		}
+	})
+}
+
+func TestPolicyDo(t *testing.T) {
+	t.Run("ItStopsOnSuccess", func(t *testing.T) {
+		// arrange
+		p := retry.Policy{MaxAttempts: 5}
+		calls := 0
+
+		// act
+		attempts, err := p.Do(func() error {
+			calls++
+			if calls < 3 {
+				return errTemporary
+			}
+			return nil
+		})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 3, attempts)
+	})
+
+	t.Run("ItReturnsTheLastErrorWhenTheAttemptsAreExhausted", func(t *testing.T) {
+		// arrange
+		p := retry.Policy{MaxAttempts: 3, InitialDelay: time.Microsecond}
+		calls := 0
+
+		// act
+		attempts, err := p.Do(func() error {
+			calls++
+			return errTemporary
+		})
+
+		// assert
+		assert.Equals(t, errTemporary, err)
+		assert.Equals(t, 3, attempts)
+		assert.Equals(t, 3, calls)
+	})
+}
+
+func TestPolicyDoContext(t *testing.T) {
+	t.Run("ItPassesTheContextThrough", func(t *testing.T) {
+		// arrange
+		p := retry.Policy{MaxAttempts: 1}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		var got interface{}
+
+		// act
+		_, err := p.DoContext(ctx, func(ctx context.Context) error {
+			got = ctx.Value(ctxKey{})
+			return nil
+		})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, "value", got)
+	})
+
+	t.Run("ItStopsWaitingOnceTheContextIsDone", func(t *testing.T) {
+		// arrange
+		p := retry.Policy{MaxAttempts: 3, InitialDelay: time.Hour}
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+
+		// act
+		attempts, err := p.DoContext(ctx, func(context.Context) error {
+			calls++
+			cancel()
+			return errTemporary
+		})
+
+		// assert
+		assert.Equals(t, context.Canceled, err)
+		assert.Equals(t, 1, attempts)
+		assert.Equals(t, 1, calls)
+	})
+}
+
+type ctxKey struct{}
@@ -0,0 +1,176 @@
+package saga
+
+import (
+	"context"
+	"sync"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Factory creates a saga in its initial state.
+type Factory func(sagaID string) domain.Saga
+
+// Correlator tells which saga the event belongs to, an empty ID means none.
+type Correlator func(e domain.Envelope) string
+
+// Option configures Manager.
+type Option func(*Manager)
+
+// WithCorrelator sets the function which routes the events to the sagas.
+//
+// By default the events are routed by their correlation IDs.
+func WithCorrelator(correlate Correlator) Option {
+	if correlate == nil {
+		panic("correlate is required")
+	}
+
+	return func(m *Manager) {
+		m.correlate = correlate
+	}
+}
+
+// Manager runs the sagas of the given type.
+//
+// It is an event handler, so it is registered in an event bus. Every event is routed to its saga instance,
+// which is created if it is not running yet. The saga state is stored before the commands are dispatched,
+// so that the events they produce find the saga up to date even if they are published synchronously.
+//
+// The commands are dispatched with the saga ID as the correlation ID and the event ID as the causation ID.
+// By default the events they produce are routed back to the same saga.
+type Manager struct {
+	sagaType       string
+	factory        Factory
+	matcher        domain.EventMatcher
+	sagaStore      domain.SagaStore
+	commandHandler domain.ContextCommandHandler
+	correlate      Correlator
+
+	sagasMu sync.Mutex
+}
+
+// NewManager creates a new instance of Manager.
+func NewManager(
+	sagaType string,
+	factory Factory,
+	matcher domain.EventMatcher,
+	sagaStore domain.SagaStore,
+	commandHandler domain.CommandHandler,
+	opts ...Option,
+) *Manager {
+	if sagaType == "" {
+		panic("sagaType is required")
+	}
+
+	if factory == nil {
+		panic("factory is required")
+	}
+
+	if matcher == nil {
+		panic("matcher is required")
+	}
+
+	if sagaStore == nil {
+		panic("sagaStore is required")
+	}
+
+	if commandHandler == nil {
+		panic("commandHandler is required")
+	}
+
+	m := &Manager{
+		sagaType:       sagaType,
+		factory:        factory,
+		matcher:        matcher,
+		sagaStore:      sagaStore,
+		commandHandler: domain.ContextCommandHandlerOf(commandHandler),
+		correlate: func(e domain.Envelope) string {
+			return e.CorrelationID
+		},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// SubscribedTo implements domain.EventHandler interface.
+func (m *Manager) SubscribedTo() domain.EventMatcher {
+	return m.matcher
+}
+
+// Handle implements domain.EventHandler interface.
+func (m *Manager) Handle(e domain.Envelope) error {
+	return m.HandleContext(context.Background(), e)
+}
+
+// HandleContext implements domain.ContextEventHandler interface.
+//
+// The events which don't belong to any saga are ignored.
+func (m *Manager) HandleContext(ctx context.Context, e domain.Envelope) error {
+	sagaID := m.correlate(e)
+	if sagaID == "" {
+		return nil
+	}
+
+	commands, err := m.advance(sagaID, e)
+	if err != nil {
+		return err
+	}
+
+	ctx = domain.WithCorrelationID(ctx, sagaID)
+	ctx = domain.WithCausationID(ctx, e.ID)
+	for _, c := range commands {
+		if _, err := m.commandHandler.HandleContext(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance lets the saga handle the event and stores its new state.
+func (m *Manager) advance(sagaID string, e domain.Envelope) ([]domain.Command, error) {
+	m.sagasMu.Lock()
+	defer m.sagasMu.Unlock()
+
+	saga, err := m.load(sagaID)
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := saga.Handle(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.store(sagaID, saga); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (m *Manager) load(sagaID string) (domain.Saga, error) {
+	saga := m.factory(sagaID)
+
+	state, ok, err := m.sagaStore.LoadSagaState(m.sagaType, sagaID)
+	if err != nil || !ok {
+		return saga, err
+	}
+
+	if err := saga.RestoreState(state); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+func (m *Manager) store(sagaID string, saga domain.Saga) error {
+	if completer, ok := saga.(domain.SagaCompleter); ok && completer.Completed() {
+		return m.sagaStore.DeleteSagaState(m.sagaType, sagaID)
+	}
+
+	state, err := saga.SnapshotState()
+	if err != nil {
+		return err
+	}
+	return m.sagaStore.StoreSagaState(m.sagaType, sagaID, state)
+}
@@ -0,0 +1,260 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/saga"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/sagastore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+const sagaType = "saga_test.countingSaga"
+
+var errSomethingElseIsNotExpected = errors.New("something else is not expected")
+
+// ensure that Manager implements domain.EventHandler interface.
+var _ domain.EventHandler = (*saga.Manager)(nil)
+
+// ensure that Manager implements domain.ContextEventHandler interface.
+var _ domain.ContextEventHandler = (*saga.Manager)(nil)
+
+func TestNewManager(t *testing.T) {
+	t.Run("ItPanicsIfSagaTypeIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			saga.NewManager("", nil, nil, nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfFactoryIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			saga.NewManager(sagaType, nil, nil, nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfMatcherIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			saga.NewManager(sagaType, newCountingSaga, nil, nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfSagaStoreIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			saga.NewManager(sagaType, newCountingSaga, domain.MatchAny(), nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfCommandHandlerIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			saga.NewManager(sagaType, newCountingSaga, domain.MatchAny(), sagastore.NewInMemorySagaStore(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfCorrelatorIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			saga.WithCorrelator(nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestManagerSubscribedTo(t *testing.T) {
+	t.Run("ItMatchesTheGivenEvents", func(t *testing.T) {
+		// arrange
+		m := createManager(sagastore.NewInMemorySagaStore(), &commandRecorder{})
+
+		// act
+		matches := m.SubscribedTo()
+
+		// assert
+		assert.True(t, matches(mock.SomethingHappened{}))
+		assert.True(t, !matches(mock.SomethingChanged{}))
+	})
+}
+
+func TestManagerHandle(t *testing.T) {
+	t.Run("ItRoutesTheEventsToTheSagasByTheirCorrelationIDs", func(t *testing.T) {
+		// arrange
+		sagas := sagastore.NewInMemorySagaStore()
+		m := createManager(sagas, &commandRecorder{})
+
+		// act
+		assert.Ok(t, m.Handle(domain.Envelope{CorrelationID: "s1", Event: mock.SomethingHappened{}}))
+		assert.Ok(t, m.Handle(domain.Envelope{CorrelationID: "s2", Event: mock.SomethingHappened{}}))
+		assert.Ok(t, m.Handle(domain.Envelope{CorrelationID: "s1", Event: mock.SomethingHappened{}}))
+
+		// assert
+		assertSagaState(t, sagas, "s1", 2)
+		assertSagaState(t, sagas, "s2", 1)
+	})
+
+	t.Run("ItDispatchesTheCommandsWithinTheSagaCorrelation", func(t *testing.T) {
+		// arrange
+		commands := &commandRecorder{}
+		m := createManager(sagastore.NewInMemorySagaStore(), commands)
+
+		// act
+		err := m.Handle(domain.Envelope{ID: "e1", CorrelationID: "s1", Event: mock.SomethingHappened{}})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.Command{mock.MakeSomethingHappen{AggID: mock.StringIdentifier("s1")}}, commands.handled)
+		assert.Equals(t, "s1", commands.correlationID)
+		assert.Equals(t, "e1", commands.causationID)
+	})
+
+	t.Run("ItIgnoresTheEventsWhichDoNotBelongToAnySaga", func(t *testing.T) {
+		// arrange
+		commands := &commandRecorder{}
+		m := createManager(sagastore.NewInMemorySagaStore(), commands)
+
+		// act
+		err := m.Handle(domain.Envelope{Event: mock.SomethingHappened{}})
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(commands.handled))
+	})
+
+	t.Run("ItRoutesTheEventsWithTheGivenCorrelator", func(t *testing.T) {
+		// arrange
+		sagas := sagastore.NewInMemorySagaStore()
+		m := saga.NewManager(
+			sagaType,
+			newCountingSaga,
+			domain.MatchEvent("SomethingHappened"),
+			sagas,
+			&commandRecorder{},
+			saga.WithCorrelator(func(e domain.Envelope) string {
+				return e.AggregateID
+			}),
+		)
+
+		// act
+		err := m.Handle(domain.Envelope{AggregateID: "a1", CorrelationID: "s1", Event: mock.SomethingHappened{}})
+
+		// assert
+		assert.Ok(t, err)
+		assertSagaState(t, sagas, "a1", 1)
+	})
+
+	t.Run("ItDeletesTheStateOfACompletedSaga", func(t *testing.T) {
+		// arrange
+		sagas := sagastore.NewInMemorySagaStore()
+		assert.Ok(t, sagas.StoreSagaState(sagaType, "s1", 2))
+		m := createManager(sagas, &commandRecorder{})
+
+		// act
+		err := m.Handle(domain.Envelope{CorrelationID: "s1", Event: mock.SomethingHappened{}})
+
+		// assert
+		assert.Ok(t, err)
+		_, ok, _ := sagas.LoadSagaState(sagaType, "s1")
+		assert.True(t, !ok)
+	})
+
+	t.Run("ItKeepsTheStateIfTheSagaFails", func(t *testing.T) {
+		// arrange
+		sagas := sagastore.NewInMemorySagaStore()
+		assert.Ok(t, sagas.StoreSagaState(sagaType, "s1", 1))
+		m := createManager(sagas, &commandRecorder{})
+
+		// act
+		err := m.Handle(domain.Envelope{CorrelationID: "s1", Event: mock.SomethingElseHappened{}})
+
+		// assert
+		assert.Equals(t, errSomethingElseIsNotExpected, err)
+		assertSagaState(t, sagas, "s1", 1)
+	})
+
+	t.Run("ItFailsIfItCannotDispatchACommand", func(t *testing.T) {
+		// arrange
+		sagas := sagastore.NewInMemorySagaStore()
+		m := createManager(sagas, &commandRecorder{err: mock.ErrItCanHappenOnceOnly})
+
+		// act
+		err := m.Handle(domain.Envelope{CorrelationID: "s1", Event: mock.SomethingHappened{}})
+
+		// assert
+		assert.Equals(t, mock.ErrItCanHappenOnceOnly, err)
+		assertSagaState(t, sagas, "s1", 1)
+	})
+}
+
+func createManager(sagas domain.SagaStore, commands domain.CommandHandler) *saga.Manager {
+	return saga.NewManager(
+		sagaType,
+		newCountingSaga,
+		domain.MatchAnyEventOf("SomethingHappened", "SomethingElseHappened"),
+		sagas,
+		commands,
+	)
+}
+
+func assertSagaState(t *testing.T, sagas domain.SagaStore, sagaID string, want int) {
+	t.Helper()
+
+	got, ok, err := sagas.LoadSagaState(sagaType, sagaID)
+	assert.Ok(t, err)
+	assert.True(t, ok)
+	assert.Equals(t, want, got)
+}
+
+// countingSaga makes something happen every time something happens until it has happened three times.
+type countingSaga struct {
+	ID       string
+	happened int
+}
+
+func newCountingSaga(sagaID string) domain.Saga {
+	return &countingSaga{ID: sagaID}
+}
+
+func (s *countingSaga) Handle(e domain.Envelope) ([]domain.Command, error) {
+	if _, ok := e.Event.(mock.SomethingElseHappened); ok {
+		return nil, errSomethingElseIsNotExpected
+	}
+
+	s.happened++
+	return []domain.Command{mock.MakeSomethingHappen{AggID: mock.StringIdentifier(s.ID)}}, nil
+}
+
+func (s *countingSaga) Completed() bool {
+	return s.happened >= 3
+}
+
+func (s *countingSaga) SnapshotState() (interface{}, error) {
+	return s.happened, nil
+}
+
+func (s *countingSaga) RestoreState(state interface{}) error {
+	s.happened = state.(int)
+	return nil
+}
+
+type commandRecorder struct {
+	err           error
+	handled       []domain.Command
+	correlationID string
+	causationID   string
+}
+
+func (r *commandRecorder) Handle(c domain.Command) ([]domain.DomainEvent, error) {
+	return r.HandleContext(context.Background(), c)
+}
+
+func (r *commandRecorder) HandleContext(ctx context.Context, c domain.Command) ([]domain.DomainEvent, error) {
+	r.handled = append(r.handled, c)
+	r.correlationID, _ = domain.CorrelationIDFrom(ctx)
+	r.causationID, _ = domain.CausationIDFrom(ctx)
+	return nil, r.err
+}
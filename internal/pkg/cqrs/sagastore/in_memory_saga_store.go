@@ -0,0 +1,47 @@
+package sagastore
+
+import "sync"
+
+// InMemorySagaStore stores the state of the running sagas in memory.
+type InMemorySagaStore struct {
+	states   map[string]interface{}
+	statesMu sync.RWMutex
+}
+
+// NewInMemorySagaStore creates a new instance of InMemorySagaStore.
+func NewInMemorySagaStore() *InMemorySagaStore {
+	return &InMemorySagaStore{
+		states: make(map[string]interface{}),
+	}
+}
+
+// LoadSagaState implements domain.SagaStore interface.
+func (s *InMemorySagaStore) LoadSagaState(sagaType, sagaID string) (interface{}, bool, error) {
+	s.statesMu.RLock()
+	defer s.statesMu.RUnlock()
+
+	state, ok := s.states[key(sagaType, sagaID)]
+	return state, ok, nil
+}
+
+// StoreSagaState implements domain.SagaStore interface.
+func (s *InMemorySagaStore) StoreSagaState(sagaType, sagaID string, state interface{}) error {
+	s.statesMu.Lock()
+	defer s.statesMu.Unlock()
+
+	s.states[key(sagaType, sagaID)] = state
+	return nil
+}
+
+// DeleteSagaState implements domain.SagaStore interface.
+func (s *InMemorySagaStore) DeleteSagaState(sagaType, sagaID string) error {
+	s.statesMu.Lock()
+	defer s.statesMu.Unlock()
+
+	delete(s.states, key(sagaType, sagaID))
+	return nil
+}
+
+func key(sagaType, sagaID string) string {
+	return sagaType + "/" + sagaID
+}
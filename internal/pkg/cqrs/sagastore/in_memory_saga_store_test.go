@@ -0,0 +1,64 @@
+package sagastore_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/sagastore"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that saga store implements domain.SagaStore interface.
+var _ domain.SagaStore = (*sagastore.InMemorySagaStore)(nil)
+
+func TestNewInMemorySagaStore(t *testing.T) {
+	t.Run("ItCreatesSagaStore", func(t *testing.T) {
+		assert.True(t, sagastore.NewInMemorySagaStore() != nil)
+	})
+}
+
+func TestInMemorySagaStoreLoadSagaState(t *testing.T) {
+	t.Run("ItReportsAnUnknownSaga", func(t *testing.T) {
+		// arrange
+		s := sagastore.NewInMemorySagaStore()
+
+		// act
+		_, ok, err := s.LoadSagaState("TestSaga", "s1")
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, !ok)
+	})
+
+	t.Run("ItLoadsTheStoredState", func(t *testing.T) {
+		// arrange
+		s := sagastore.NewInMemorySagaStore()
+		assert.Ok(t, s.StoreSagaState("TestSaga", "s1", 42))
+		assert.Ok(t, s.StoreSagaState("OtherSaga", "s1", 7))
+
+		// act
+		got, ok, err := s.LoadSagaState("TestSaga", "s1")
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, ok)
+		assert.Equals(t, 42, got)
+	})
+}
+
+func TestInMemorySagaStoreDeleteSagaState(t *testing.T) {
+	t.Run("ItDeletesTheState", func(t *testing.T) {
+		// arrange
+		s := sagastore.NewInMemorySagaStore()
+		assert.Ok(t, s.StoreSagaState("TestSaga", "s1", 42))
+
+		// act
+		err := s.DeleteSagaState("TestSaga", "s1")
+
+		// assert
+		assert.Ok(t, err)
+		_, ok, _ := s.LoadSagaState("TestSaga", "s1")
+		assert.True(t, !ok)
+	})
+}
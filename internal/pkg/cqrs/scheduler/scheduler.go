@@ -0,0 +1,288 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/retry"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+const defaultPollInterval = time.Second
+
+var (
+	// ErrKeyIsRequired happens if a command is scheduled without a key.
+	ErrKeyIsRequired = errors.New("schedule key is required")
+)
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is a function that can be used as a clock.
+type ClockFunc func() time.Time
+
+// Now implements Clock interface.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// DeliveryError is reported when a due command fails.
+//
+// Schedule.Attempts counts the failures before this one.
+// Dropped tells that the attempts are exhausted, so the command is removed from the store.
+type DeliveryError struct {
+	Schedule domain.ScheduledCommand
+	Err      error
+	Dropped  bool
+}
+
+// Error implements error interface.
+func (e DeliveryError) Error() string {
+	return fmt.Sprintf("%s command scheduled as %s failed: %v", e.Schedule.Command.CommandType(), e.Schedule.Key, e.Err)
+}
+
+// Option configures Scheduler.
+type Option func(*Scheduler)
+
+// WithClock sets the clock which tells when the commands are due.
+func WithClock(clock Clock) Option {
+	if clock == nil {
+		panic("clock is required")
+	}
+
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+// WithPollInterval sets how often the due commands are checked in the background.
+func WithPollInterval(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		s.pollInterval = interval
+	}
+}
+
+// WithRetryPolicy sets how many times and how often a failed command is delivered again.
+//
+// The failed command is rescheduled after the delay given by the policy,
+// once the attempts are exhausted it is dropped. By default retry.DefaultPolicy is used.
+func WithRetryPolicy(policy retry.Policy) Option {
+	return func(s *Scheduler) {
+		s.retryPolicy = policy
+	}
+}
+
+// WithErrorHandler sets a function which is called when a due command fails, it is reported as DeliveryError.
+//
+// When the commands are delivered in the background, it is also called if the store fails.
+func WithErrorHandler(onError func(error)) Option {
+	return func(s *Scheduler) {
+		s.onError = onError
+	}
+}
+
+// Scheduler delivers the scheduled commands to the command handler once they are due.
+//
+// The pending commands are kept in the schedule store, so they survive restarts given a persistent store.
+// A due command is removed from the store once it has been delivered, so it is delivered at least once:
+// a command which is interrupted by a restart is delivered again on the next poll,
+// a command which fails is delivered again according to the retry policy.
+type Scheduler struct {
+	scheduleStore  domain.ScheduleStore
+	commandHandler domain.ContextCommandHandler
+	clock          Clock
+	pollInterval   time.Duration
+	retryPolicy    retry.Policy
+	onError        func(error)
+
+	schedulesMu sync.Mutex
+	delivering  map[string]bool // tells whether the schedule being delivered has been replaced or cancelled meanwhile
+	done        chan struct{}
+	startOnce   sync.Once
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+}
+
+// New creates a new instance of Scheduler.
+func New(scheduleStore domain.ScheduleStore, commandHandler domain.CommandHandler, opts ...Option) *Scheduler {
+	if scheduleStore == nil {
+		panic("scheduleStore is required")
+	}
+
+	if commandHandler == nil {
+		panic("commandHandler is required")
+	}
+
+	s := &Scheduler{
+		scheduleStore:  scheduleStore,
+		commandHandler: domain.ContextCommandHandlerOf(commandHandler),
+		clock:          ClockFunc(time.Now),
+		pollInterval:   defaultPollInterval,
+		retryPolicy:    retry.DefaultPolicy(),
+		onError:        func(error) {},
+		delivering:     make(map[string]bool),
+		done:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Schedule schedules the command to be delivered at the given time.
+//
+// A command scheduled with the same key earlier is replaced.
+func (s *Scheduler) Schedule(key string, c domain.Command, dueAt time.Time) error {
+	if key == "" {
+		return ErrKeyIsRequired
+	}
+
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	s.markReplaced(key)
+	return s.scheduleStore.StoreSchedule(domain.ScheduledCommand{Key: key, Command: c, DueAt: dueAt})
+}
+
+// ScheduleAfter schedules the command to be delivered once the given delay has passed.
+func (s *Scheduler) ScheduleAfter(key string, c domain.Command, delay time.Duration) error {
+	return s.Schedule(key, c, s.clock.Now().Add(delay))
+}
+
+// Cancel cancels the command scheduled with the given key, if any.
+func (s *Scheduler) Cancel(key string) error {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	s.markReplaced(key)
+	return s.scheduleStore.DeleteSchedule(key)
+}
+
+// DeliverDue delivers the commands which are due by now.
+//
+// It returns the error if the store fails, the failed commands are reported to the error handler
+// and are delivered again according to the retry policy.
+func (s *Scheduler) DeliverDue() error {
+	due, err := s.takeDue()
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range due {
+		var retried *domain.ScheduledCommand
+		if _, deliveryErr := s.commandHandler.HandleContext(context.Background(), schedule.Command); deliveryErr != nil {
+			retried = s.retry(schedule, deliveryErr)
+		}
+
+		if releaseErr := s.release(schedule.Key, retried); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}
+	return err
+}
+
+// Start starts delivering the due commands in the background.
+func (s *Scheduler) Start() {
+	s.startOnce.Do(func() {
+		s.wg.Add(1)
+		go s.run()
+	})
+}
+
+// Stop stops the scheduler and waits until the delivery in progress is finished.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
+
+// takeDue returns the due commands and marks them as being delivered.
+//
+// The commands which are being delivered by an overlapping call are skipped.
+func (s *Scheduler) takeDue() ([]domain.ScheduledCommand, error) {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	due, err := s.scheduleStore.DueSchedules(s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	taken := due[:0]
+	for _, schedule := range due {
+		if _, ok := s.delivering[schedule.Key]; ok {
+			continue
+		}
+
+		s.delivering[schedule.Key] = false
+		taken = append(taken, schedule)
+	}
+	return taken, nil
+}
+
+// retry reports the failed command and returns its next attempt, if the attempts are not exhausted yet.
+func (s *Scheduler) retry(schedule domain.ScheduledCommand, deliveryErr error) *domain.ScheduledCommand {
+	retried := schedule
+	retried.Attempts++
+
+	dropped := retried.Attempts >= s.retryPolicy.MaxAttempts
+	s.onError(DeliveryError{Schedule: schedule, Err: deliveryErr, Dropped: dropped})
+	if dropped {
+		return nil
+	}
+
+	retried.DueAt = s.clock.Now().Add(s.retryPolicy.Delay(retried.Attempts))
+	return &retried
+}
+
+// release removes the delivered or dropped command from the store, or stores its next attempt,
+// unless it has been replaced or cancelled meanwhile.
+func (s *Scheduler) release(key string, retried *domain.ScheduledCommand) error {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	replaced := s.delivering[key]
+	delete(s.delivering, key)
+
+	switch {
+	case replaced:
+		return nil
+	case retried != nil:
+		return s.scheduleStore.StoreSchedule(*retried)
+	default:
+		return s.scheduleStore.DeleteSchedule(key)
+	}
+}
+
+func (s *Scheduler) markReplaced(key string) {
+	if _, ok := s.delivering[key]; ok {
+		s.delivering[key] = true
+	}
+}
+
+func (s *Scheduler) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DeliverDue(); err != nil {
+			s.onError(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
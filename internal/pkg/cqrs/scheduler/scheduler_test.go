@@ -0,0 +1,356 @@
+package scheduler_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/retry"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/scheduler"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/schedulestore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+var startedAt = time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	t.Run("ItPanicsIfScheduleStoreIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			scheduler.New(nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfCommandHandlerIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			scheduler.New(schedulestore.NewInMemoryScheduleStore(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfClockIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			scheduler.WithClock(nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestSchedulerSchedule(t *testing.T) {
+	t.Run("ItFailsIfTheKeyIsNotGiven", func(t *testing.T) {
+		// arrange
+		s := scheduler.New(schedulestore.NewInMemoryScheduleStore(), &commandRecorder{})
+
+		// act
+		err := s.Schedule("", mock.MakeSomethingHappen{}, startedAt)
+
+		// assert
+		assert.Equals(t, scheduler.ErrKeyIsRequired, err)
+	})
+
+	t.Run("ItReplacesTheCommandScheduledWithTheSameKey", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+		commands := &commandRecorder{}
+		s := scheduler.New(schedulestore.NewInMemoryScheduleStore(), commands, scheduler.WithClock(clock))
+
+		assert.Ok(t, s.ScheduleAfter("timeout", mock.MakeSomethingHappen{ID: "first"}, time.Second))
+		assert.Ok(t, s.ScheduleAfter("timeout", mock.MakeSomethingHappen{ID: "second"}, 2*time.Second))
+
+		// act
+		clock.Advance(time.Second)
+		assert.Ok(t, s.DeliverDue())
+		clock.Advance(time.Second)
+		assert.Ok(t, s.DeliverDue())
+
+		// assert
+		assert.Equals(t, []domain.Command{mock.MakeSomethingHappen{ID: "second"}}, commands.handled)
+	})
+}
+
+func TestSchedulerDeliverDue(t *testing.T) {
+	t.Run("ItDeliversTheDueCommandsInOrderOnce", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+		commands := &commandRecorder{}
+		s := scheduler.New(schedulestore.NewInMemoryScheduleStore(), commands, scheduler.WithClock(clock))
+
+		assert.Ok(t, s.ScheduleAfter("late", mock.MakeSomethingHappen{ID: "late"}, 2*time.Second))
+		assert.Ok(t, s.ScheduleAfter("early", mock.MakeSomethingHappen{ID: "early"}, time.Second))
+		assert.Ok(t, s.ScheduleAfter("future", mock.MakeSomethingHappen{ID: "future"}, time.Minute))
+
+		// act
+		assert.Ok(t, s.DeliverDue())
+		clock.Advance(2 * time.Second)
+		assert.Ok(t, s.DeliverDue())
+		assert.Ok(t, s.DeliverDue())
+
+		// assert
+		assert.Equals(t, []domain.Command{
+			mock.MakeSomethingHappen{ID: "early"},
+			mock.MakeSomethingHappen{ID: "late"},
+		}, commands.handled)
+	})
+
+	t.Run("ItReportsTheFailedCommands", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+
+		var errs []error
+		s := scheduler.New(
+			schedulestore.NewInMemoryScheduleStore(),
+			&commandRecorder{err: mock.ErrItCanHappenOnceOnly},
+			scheduler.WithClock(clock),
+			scheduler.WithErrorHandler(func(err error) {
+				errs = append(errs, err)
+			}),
+		)
+		assert.Ok(t, s.Schedule("timeout", mock.MakeSomethingHappen{}, startedAt))
+
+		// act
+		err := s.DeliverDue()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []error{scheduler.DeliveryError{
+			Schedule: domain.ScheduledCommand{Key: "timeout", Command: mock.MakeSomethingHappen{}, DueAt: startedAt},
+			Err:      mock.ErrItCanHappenOnceOnly,
+		}}, errs)
+		assert.Equals(t, "MakeSomethingHappen command scheduled as timeout failed: some business rule error occurred",
+			errs[0].Error())
+	})
+
+	t.Run("ItDeliversTheFailedCommandAgain", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+		commands := &commandRecorder{err: mock.ErrItCanHappenOnceOnly}
+		s := scheduler.New(
+			schedulestore.NewInMemoryScheduleStore(),
+			commands,
+			scheduler.WithClock(clock),
+			scheduler.WithRetryPolicy(retry.Policy{MaxAttempts: 3}),
+		)
+
+		assert.Ok(t, s.Schedule("timeout", mock.MakeSomethingHappen{ID: "timeout"}, startedAt))
+		assert.Ok(t, s.DeliverDue())
+		commands.err = nil
+
+		// act
+		assert.Ok(t, s.DeliverDue())
+		assert.Ok(t, s.DeliverDue())
+
+		// assert
+		assert.Equals(t, []domain.Command{
+			mock.MakeSomethingHappen{ID: "timeout"},
+			mock.MakeSomethingHappen{ID: "timeout"},
+		}, commands.handled)
+	})
+
+	t.Run("ItDelaysTheNextAttemptAccordingToTheRetryPolicy", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+		commands := &commandRecorder{err: mock.ErrItCanHappenOnceOnly}
+		s := scheduler.New(
+			schedulestore.NewInMemoryScheduleStore(),
+			commands,
+			scheduler.WithClock(clock),
+			scheduler.WithRetryPolicy(retry.Policy{MaxAttempts: 3, InitialDelay: time.Second}),
+		)
+
+		assert.Ok(t, s.Schedule("timeout", mock.MakeSomethingHappen{ID: "timeout"}, startedAt))
+		assert.Ok(t, s.DeliverDue())
+
+		// act
+		assert.Ok(t, s.DeliverDue())
+		clock.Advance(time.Second)
+		assert.Ok(t, s.DeliverDue())
+
+		// assert
+		assert.Equals(t, []domain.Command{
+			mock.MakeSomethingHappen{ID: "timeout"},
+			mock.MakeSomethingHappen{ID: "timeout"},
+		}, commands.handled)
+	})
+
+	t.Run("ItDropsTheCommandOnceTheAttemptsAreExhausted", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+		commands := &commandRecorder{err: mock.ErrItCanHappenOnceOnly}
+
+		var errs []error
+		s := scheduler.New(
+			schedulestore.NewInMemoryScheduleStore(),
+			commands,
+			scheduler.WithClock(clock),
+			scheduler.WithRetryPolicy(retry.Policy{MaxAttempts: 2}),
+			scheduler.WithErrorHandler(func(err error) {
+				errs = append(errs, err)
+			}),
+		)
+		assert.Ok(t, s.Schedule("timeout", mock.MakeSomethingHappen{}, startedAt))
+
+		// act
+		assert.Ok(t, s.DeliverDue())
+		assert.Ok(t, s.DeliverDue())
+		assert.Ok(t, s.DeliverDue())
+
+		// assert
+		assert.Equals(t, 2, len(commands.handled))
+		assert.Equals(t, []error{
+			scheduler.DeliveryError{
+				Schedule: domain.ScheduledCommand{Key: "timeout", Command: mock.MakeSomethingHappen{}, DueAt: startedAt},
+				Err:      mock.ErrItCanHappenOnceOnly,
+			},
+			scheduler.DeliveryError{
+				Schedule: domain.ScheduledCommand{
+					Key: "timeout", Command: mock.MakeSomethingHappen{}, DueAt: startedAt, Attempts: 1},
+				Err:     mock.ErrItCanHappenOnceOnly,
+				Dropped: true,
+			},
+		}, errs)
+	})
+
+	t.Run("ItKeepsTheCommandRescheduledWhileItIsDelivered", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+		commands := &commandRecorder{}
+		s := scheduler.New(schedulestore.NewInMemoryScheduleStore(), commands, scheduler.WithClock(clock))
+
+		commands.onHandle = func(c domain.Command) {
+			if c == (mock.MakeSomethingHappen{ID: "first"}) {
+				assert.Ok(t, s.ScheduleAfter("timeout", mock.MakeSomethingHappen{ID: "second"}, time.Second))
+			}
+		}
+		assert.Ok(t, s.Schedule("timeout", mock.MakeSomethingHappen{ID: "first"}, startedAt))
+
+		// act
+		assert.Ok(t, s.DeliverDue())
+		clock.Advance(time.Second)
+		assert.Ok(t, s.DeliverDue())
+
+		// assert
+		assert.Equals(t, []domain.Command{
+			mock.MakeSomethingHappen{ID: "first"},
+			mock.MakeSomethingHappen{ID: "second"},
+		}, commands.handled)
+	})
+
+	t.Run("ItDoesNotDeliverTheCommandBeingDeliveredByAnOverlappingCall", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+		commands := &commandRecorder{}
+		s := scheduler.New(schedulestore.NewInMemoryScheduleStore(), commands, scheduler.WithClock(clock))
+
+		overlapped := false
+		commands.onHandle = func(c domain.Command) {
+			if overlapped {
+				return
+			}
+			overlapped = true
+
+			delivered := make(chan error)
+			go func() {
+				delivered <- s.DeliverDue()
+			}()
+			assert.Ok(t, <-delivered)
+
+			assert.Ok(t, s.ScheduleAfter("timeout", mock.MakeSomethingHappen{ID: "second"}, time.Second))
+		}
+		assert.Ok(t, s.Schedule("timeout", mock.MakeSomethingHappen{ID: "first"}, startedAt))
+
+		// act
+		assert.Ok(t, s.DeliverDue())
+		clock.Advance(time.Second)
+		assert.Ok(t, s.DeliverDue())
+
+		// assert
+		assert.Equals(t, []domain.Command{
+			mock.MakeSomethingHappen{ID: "first"},
+			mock.MakeSomethingHappen{ID: "second"},
+		}, commands.handled)
+	})
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	t.Run("ItCancelsTheCommandScheduledWithTheGivenKey", func(t *testing.T) {
+		// arrange
+		clock := &manualClock{now: startedAt}
+		commands := &commandRecorder{}
+		s := scheduler.New(schedulestore.NewInMemoryScheduleStore(), commands, scheduler.WithClock(clock))
+
+		assert.Ok(t, s.ScheduleAfter("cancelled", mock.MakeSomethingHappen{ID: "cancelled"}, time.Second))
+		assert.Ok(t, s.ScheduleAfter("kept", mock.MakeSomethingHappen{ID: "kept"}, time.Second))
+
+		// act
+		err := s.Cancel("cancelled")
+
+		// assert
+		assert.Ok(t, err)
+
+		clock.Advance(time.Second)
+		assert.Ok(t, s.DeliverDue())
+		assert.Equals(t, []domain.Command{mock.MakeSomethingHappen{ID: "kept"}}, commands.handled)
+	})
+}
+
+func TestSchedulerStart(t *testing.T) {
+	t.Run("ItDeliversTheDueCommandsInTheBackground", func(t *testing.T) {
+		// arrange
+		commands := &commandRecorder{delivered: make(chan struct{})}
+		s := scheduler.New(
+			schedulestore.NewInMemoryScheduleStore(),
+			commands,
+			scheduler.WithPollInterval(time.Millisecond),
+		)
+		assert.Ok(t, s.ScheduleAfter("timeout", mock.MakeSomethingHappen{}, 10*time.Millisecond))
+
+		// act
+		s.Start()
+		defer s.Stop()
+
+		// assert
+		select {
+		case <-commands.delivered:
+		case <-time.After(time.Second):
+			t.Fatal("the command has not been delivered")
+		}
+	})
+}
+
+type manualClock struct {
+	now   time.Time
+	nowMu sync.Mutex
+}
+
+func (c *manualClock) Now() time.Time {
+	c.nowMu.Lock()
+	defer c.nowMu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.nowMu.Lock()
+	defer c.nowMu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type commandRecorder struct {
+	err       error
+	handled   []domain.Command
+	delivered chan struct{}
+	onHandle  func(c domain.Command)
+}
+
+func (r *commandRecorder) Handle(c domain.Command) ([]domain.DomainEvent, error) {
+	r.handled = append(r.handled, c)
+	if r.onHandle != nil {
+		r.onHandle(c)
+	}
+	if r.delivered != nil {
+		close(r.delivered)
+	}
+	return nil, r.err
+}
@@ -0,0 +1,109 @@
+package schedulestore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/fsutil"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// FileScheduleStore keeps the scheduled commands in a gob file.
+//
+// The commands are stored as interface values, so their concrete types, as well as the types
+// of their identifiers, must be registered with gob.Register.
+// The file is replaced atomically on every change, so a crash leaves either the old or the new schedules.
+type FileScheduleStore struct {
+	path        string
+	schedules   map[string]domain.ScheduledCommand
+	schedulesMu sync.RWMutex
+}
+
+// NewFileScheduleStore creates a new instance of FileScheduleStore which keeps the schedules in the given file.
+func NewFileScheduleStore(path string) (*FileScheduleStore, error) {
+	if path == "" {
+		panic("path is required")
+	}
+
+	s := &FileScheduleStore{
+		path:      path,
+		schedules: make(map[string]domain.ScheduledCommand),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s.schedules); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// StoreSchedule implements domain.ScheduleStore interface.
+func (s *FileScheduleStore) StoreSchedule(schedule domain.ScheduledCommand) error {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	previous, existed := s.schedules[schedule.Key]
+	s.schedules[schedule.Key] = schedule
+
+	if err := s.write(); err != nil {
+		s.restore(schedule.Key, previous, existed)
+		return err
+	}
+	return nil
+}
+
+// DueSchedules implements domain.ScheduleStore interface.
+func (s *FileScheduleStore) DueSchedules(now time.Time) ([]domain.ScheduledCommand, error) {
+	s.schedulesMu.RLock()
+	defer s.schedulesMu.RUnlock()
+
+	return dueSchedules(s.schedules, now), nil
+}
+
+// DeleteSchedule implements domain.ScheduleStore interface.
+func (s *FileScheduleStore) DeleteSchedule(key string) error {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	previous, existed := s.schedules[key]
+	if !existed {
+		return nil
+	}
+	delete(s.schedules, key)
+
+	if err := s.write(); err != nil {
+		s.restore(key, previous, existed)
+		return err
+	}
+	return nil
+}
+
+func (s *FileScheduleStore) restore(key string, previous domain.ScheduledCommand, existed bool) {
+	if existed {
+		s.schedules[key] = previous
+	} else {
+		delete(s.schedules, key)
+	}
+}
+
+func (s *FileScheduleStore) write() error {
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(s.schedules); err != nil {
+		return err
+	}
+
+	return fsutil.ReplaceFile(s.path, data.Bytes(), true)
+}
@@ -0,0 +1,130 @@
+package schedulestore_test
+
+import (
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/schedulestore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that file schedule store implements domain.ScheduleStore interface.
+var _ domain.ScheduleStore = (*schedulestore.FileScheduleStore)(nil)
+
+var testDir string
+
+func TestMain(m *testing.M) {
+	gob.Register(mock.MakeSomethingHappen{})
+	gob.Register(mock.StringIdentifier(""))
+
+	dir, err := ioutil.TempDir("", "schedulestore")
+	if err != nil {
+		panic(err)
+	}
+	testDir = dir
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestNewFileScheduleStore(t *testing.T) {
+	t.Run("ItPanicsIfPathIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			_, _ = schedulestore.NewFileScheduleStore("")
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItFailsIfTheFileIsCorrupted", func(t *testing.T) {
+		// arrange
+		path := schedulesPath(t)
+		assert.Ok(t, ioutil.WriteFile(path, []byte("invalid"), 0644))
+
+		// act
+		_, err := schedulestore.NewFileScheduleStore(path)
+
+		// assert
+		assert.True(t, err != nil)
+	})
+}
+
+func TestFileScheduleStoreDueSchedules(t *testing.T) {
+	t.Run("ItLoadsTheSchedulesAfterReopening", func(t *testing.T) {
+		// arrange
+		path := schedulesPath(t)
+		s := createFileScheduleStore(t, path)
+		want := schedule("s1", now)
+		assert.Ok(t, s.StoreSchedule(want))
+		assert.Ok(t, s.StoreSchedule(schedule("s2", now.Add(time.Second))))
+
+		// act
+		s = createFileScheduleStore(t, path)
+		got, err := s.DueSchedules(now)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 1, len(got))
+		assert.Equals(t, want.Key, got[0].Key)
+		assert.Equals(t, want.Command, got[0].Command)
+		assert.True(t, want.DueAt.Equal(got[0].DueAt))
+	})
+}
+
+func TestFileScheduleStoreStoreSchedule(t *testing.T) {
+	t.Run("ItFailsIfTheFileCannotBeWritten", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(schedulesPath(t), "missing", "schedules.gob")
+		s := createFileScheduleStore(t, path)
+
+		// act
+		err := s.StoreSchedule(schedule("s1", now))
+
+		// assert
+		assert.True(t, err != nil)
+
+		got, err := s.DueSchedules(now)
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+func TestFileScheduleStoreDeleteSchedule(t *testing.T) {
+	t.Run("ItDeletesTheScheduleForGood", func(t *testing.T) {
+		// arrange
+		path := schedulesPath(t)
+		s := createFileScheduleStore(t, path)
+		assert.Ok(t, s.StoreSchedule(schedule("s1", now)))
+
+		// act
+		err := s.DeleteSchedule("s1")
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := createFileScheduleStore(t, path).DueSchedules(now)
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+func createFileScheduleStore(t *testing.T, path string) *schedulestore.FileScheduleStore {
+	t.Helper()
+	s, err := schedulestore.NewFileScheduleStore(path)
+	assert.Ok(t, err)
+	return s
+}
+
+func schedulesPath(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir(testDir, "")
+	assert.Ok(t, err)
+	return filepath.Join(dir, "schedules.gob")
+}
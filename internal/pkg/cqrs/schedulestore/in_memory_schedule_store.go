@@ -0,0 +1,66 @@
+package schedulestore
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// InMemoryScheduleStore keeps the scheduled commands in memory.
+type InMemoryScheduleStore struct {
+	schedules   map[string]domain.ScheduledCommand
+	schedulesMu sync.RWMutex
+}
+
+// NewInMemoryScheduleStore creates a new instance of InMemoryScheduleStore.
+func NewInMemoryScheduleStore() *InMemoryScheduleStore {
+	return &InMemoryScheduleStore{
+		schedules: make(map[string]domain.ScheduledCommand),
+	}
+}
+
+// StoreSchedule implements domain.ScheduleStore interface.
+func (s *InMemoryScheduleStore) StoreSchedule(schedule domain.ScheduledCommand) error {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	s.schedules[schedule.Key] = schedule
+	return nil
+}
+
+// DueSchedules implements domain.ScheduleStore interface.
+func (s *InMemoryScheduleStore) DueSchedules(now time.Time) ([]domain.ScheduledCommand, error) {
+	s.schedulesMu.RLock()
+	defer s.schedulesMu.RUnlock()
+
+	return dueSchedules(s.schedules, now), nil
+}
+
+// DeleteSchedule implements domain.ScheduleStore interface.
+func (s *InMemoryScheduleStore) DeleteSchedule(key string) error {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	delete(s.schedules, key)
+	return nil
+}
+
+// dueSchedules returns the schedules due by the given time ordered by their due times and keys.
+func dueSchedules(schedules map[string]domain.ScheduledCommand, now time.Time) []domain.ScheduledCommand {
+	var due []domain.ScheduledCommand
+	for _, schedule := range schedules {
+		if !schedule.DueAt.After(now) {
+			due = append(due, schedule)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].DueAt.Equal(due[j].DueAt) {
+			return due[i].Key < due[j].Key
+		}
+		return due[i].DueAt.Before(due[j].DueAt)
+	})
+	return due
+}
@@ -0,0 +1,84 @@
+package schedulestore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/schedulestore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that schedule store implements domain.ScheduleStore interface.
+var _ domain.ScheduleStore = (*schedulestore.InMemoryScheduleStore)(nil)
+
+var now = time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNewInMemoryScheduleStore(t *testing.T) {
+	t.Run("ItCreatesScheduleStore", func(t *testing.T) {
+		assert.True(t, schedulestore.NewInMemoryScheduleStore() != nil)
+	})
+}
+
+func TestInMemoryScheduleStoreDueSchedules(t *testing.T) {
+	t.Run("ItReturnsTheDueSchedulesInOrder", func(t *testing.T) {
+		// arrange
+		s := schedulestore.NewInMemoryScheduleStore()
+		later := schedule("later", now)
+		earlier := schedule("earlier", now.Add(-time.Second))
+		assert.Ok(t, s.StoreSchedule(later))
+		assert.Ok(t, s.StoreSchedule(earlier))
+		assert.Ok(t, s.StoreSchedule(schedule("future", now.Add(time.Second))))
+
+		// act
+		got, err := s.DueSchedules(now)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.ScheduledCommand{earlier, later}, got)
+	})
+}
+
+func TestInMemoryScheduleStoreStoreSchedule(t *testing.T) {
+	t.Run("ItReplacesTheScheduleWithTheSameKey", func(t *testing.T) {
+		// arrange
+		s := schedulestore.NewInMemoryScheduleStore()
+		assert.Ok(t, s.StoreSchedule(schedule("s1", now)))
+
+		// act
+		err := s.StoreSchedule(schedule("s1", now.Add(time.Second)))
+
+		// assert
+		assert.Ok(t, err)
+		got, err := s.DueSchedules(now)
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+func TestInMemoryScheduleStoreDeleteSchedule(t *testing.T) {
+	t.Run("ItDeletesTheSchedule", func(t *testing.T) {
+		// arrange
+		s := schedulestore.NewInMemoryScheduleStore()
+		assert.Ok(t, s.StoreSchedule(schedule("s1", now)))
+
+		// act
+		err := s.DeleteSchedule("s1")
+
+		// assert
+		assert.Ok(t, err)
+		got, err := s.DueSchedules(now)
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(got))
+	})
+}
+
+func schedule(key string, dueAt time.Time) domain.ScheduledCommand {
+	return domain.ScheduledCommand{
+		Key:     key,
+		Command: mock.MakeSomethingHappen{ID: key, AggID: mock.StringIdentifier("TestAgg")},
+		DueAt:   dueAt,
+	}
+}
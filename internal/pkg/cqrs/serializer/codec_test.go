@@ -0,0 +1,48 @@
+package serializer_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/serializer"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+)
+
+// ensure that codecs implement serializer.Codec interface.
+var (
+	_ serializer.Codec = serializer.JSONCodec{}
+	_ serializer.Codec = serializer.GobCodec{}
+)
+
+func TestCodecs(t *testing.T) {
+	codecs := map[string]serializer.Codec{
+		"JSON": serializer.JSONCodec{},
+		"Gob":  serializer.GobCodec{},
+	}
+
+	for name, codec := range codecs {
+		codec := codec
+		t.Run(name+"ItRoundTripsAValue", func(t *testing.T) {
+			// arrange
+			want := mock.SomethingChanged{Value: "test"}
+
+			// act
+			data, err := codec.Marshal(want)
+			assert.Ok(t, err)
+
+			var got mock.SomethingChanged
+			err = codec.Unmarshal(data, &got)
+
+			// assert
+			assert.Ok(t, err)
+			assert.Equals(t, want, got)
+		})
+
+		t.Run(name+"ItFailsToUnmarshalInvalidData", func(t *testing.T) {
+			var got mock.SomethingChanged
+			err := codec.Unmarshal([]byte("invalid"), &got)
+
+			assert.True(t, err != nil)
+		})
+	}
+}
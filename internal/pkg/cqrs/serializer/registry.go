@@ -0,0 +1,53 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+type schema struct {
+	eventType string
+	version   int
+}
+
+// Registry maps event types and their schema versions to Go types.
+type Registry struct {
+	types   map[schema]reflect.Type
+	typesMu sync.RWMutex
+}
+
+// NewRegistry creates a new instance of Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		types: make(map[schema]reflect.Type),
+	}
+}
+
+// Register registers the given events by their types and schema versions.
+//
+// Events may be registered either as values or as pointers.
+// The older schema versions of an event are registered as separate Go types, so that they can be upcasted.
+func (r *Registry) Register(events ...domain.DomainEvent) {
+	r.typesMu.Lock()
+	defer r.typesMu.Unlock()
+
+	for _, e := range events {
+		r.types[schema{eventType: e.EventType(), version: domain.SchemaVersionOf(e)}] = reflect.TypeOf(e)
+	}
+}
+
+// TypeOf returns the Go type which has been registered for the given event type and schema version.
+func (r *Registry) TypeOf(eventType string, schemaVersion int) (reflect.Type, error) {
+	r.typesMu.RLock()
+	defer r.typesMu.RUnlock()
+
+	t, ok := r.types[schema{eventType: eventType, version: schemaVersion}]
+	if !ok {
+		return nil, errors.New(eventType + " event v" + strconv.Itoa(schemaVersion) + " is not registered")
+	}
+	return t, nil
+}
@@ -0,0 +1,61 @@
+package serializer_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/serializer"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+)
+
+func TestNewRegistry(t *testing.T) {
+	t.Run("ItCreatesNewInstance", func(t *testing.T) {
+		assert.True(t, serializer.NewRegistry() != nil)
+	})
+}
+
+func TestRegistryTypeOf(t *testing.T) {
+	t.Run("ItFailsIfTheEventIsNotRegistered", func(t *testing.T) {
+		// arrange
+		r := serializer.NewRegistry()
+
+		// act
+		_, err := r.TypeOf("SomethingChanged", 1)
+
+		// assert
+		assert.Equals(t, errors.New("SomethingChanged event v1 is not registered"), err)
+	})
+
+	t.Run("ItReturnsTheRegisteredType", func(t *testing.T) {
+		// arrange
+		r := serializer.NewRegistry()
+		r.Register(mock.SomethingHappened{}, &mock.SomethingChanged{})
+
+		// act
+		valueType, valueErr := r.TypeOf("SomethingHappened", 1)
+		ptrType, ptrErr := r.TypeOf("SomethingChanged", 2)
+
+		// assert
+		assert.Ok(t, valueErr)
+		assert.Ok(t, ptrErr)
+		assert.Equals(t, reflect.TypeOf(mock.SomethingHappened{}), valueType)
+		assert.Equals(t, reflect.TypeOf(&mock.SomethingChanged{}), ptrType)
+	})
+	t.Run("ItDistinguishesSchemaVersions", func(t *testing.T) {
+		// arrange
+		r := serializer.NewRegistry()
+		r.Register(mock.SomethingChangedV1{}, mock.SomethingChanged{})
+
+		// act
+		v1Type, v1Err := r.TypeOf("SomethingChanged", 1)
+		v2Type, v2Err := r.TypeOf("SomethingChanged", 2)
+
+		// assert
+		assert.Ok(t, v1Err)
+		assert.Ok(t, v2Err)
+		assert.Equals(t, reflect.TypeOf(mock.SomethingChangedV1{}), v1Type)
+		assert.Equals(t, reflect.TypeOf(mock.SomethingChanged{}), v2Type)
+	})
+}
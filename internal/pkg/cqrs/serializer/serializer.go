@@ -0,0 +1,59 @@
+package serializer
+
+import (
+	"reflect"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Serializer marshals and unmarshals events using the registered event types.
+type Serializer struct {
+	registry *Registry
+	codec    Codec
+}
+
+// NewSerializer creates a new instance of Serializer.
+func NewSerializer(registry *Registry, codec Codec) *Serializer {
+	if registry == nil {
+		panic("registry is required")
+	}
+
+	if codec == nil {
+		panic("codec is required")
+	}
+
+	return &Serializer{
+		registry: registry,
+		codec:    codec,
+	}
+}
+
+// MarshalEvent implements domain.EventSerializer interface.
+func (s *Serializer) MarshalEvent(e domain.DomainEvent) ([]byte, error) {
+	return s.codec.Marshal(e)
+}
+
+// UnmarshalEvent implements domain.EventSerializer interface.
+//
+// The event is returned in the same form it has been registered, either as a value or as a pointer.
+func (s *Serializer) UnmarshalEvent(eventType string, schemaVersion int, data []byte) (domain.DomainEvent, error) {
+	t, err := s.registry.TypeOf(eventType, schemaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+
+	ptr := reflect.New(t)
+	if err := s.codec.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, err
+	}
+
+	if isPtr {
+		return ptr.Interface().(domain.DomainEvent), nil
+	}
+	return ptr.Elem().Interface().(domain.DomainEvent), nil
+}
@@ -0,0 +1,109 @@
+package serializer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/serializer"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that Serializer implements domain.EventSerializer interface.
+var _ domain.EventSerializer = (*serializer.Serializer)(nil)
+
+func TestNewSerializer(t *testing.T) {
+	t.Run("ItPanicsIfRegistryIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			serializer.NewSerializer(nil, nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfCodecIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			serializer.NewSerializer(serializer.NewRegistry(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestSerializerUnmarshalEvent(t *testing.T) {
+	t.Run("ItFailsIfTheEventIsNotRegistered", func(t *testing.T) {
+		// arrange
+		s := serializer.NewSerializer(serializer.NewRegistry(), serializer.JSONCodec{})
+
+		// act
+		_, err := s.UnmarshalEvent("SomethingChanged", 1, []byte("{}"))
+
+		// assert
+		assert.Equals(t, errors.New("SomethingChanged event v1 is not registered"), err)
+	})
+
+	t.Run("ItFailsIfTheDataCannotBeDecoded", func(t *testing.T) {
+		// arrange
+		s := createSerializer(mock.SomethingChanged{})
+
+		// act
+		_, err := s.UnmarshalEvent("SomethingChanged", 1, []byte("invalid"))
+
+		// assert
+		assert.True(t, err != nil)
+	})
+
+	t.Run("ItRestoresEventsRegisteredAsValues", func(t *testing.T) {
+		// arrange
+		s := createSerializer(mock.SomethingChanged{})
+		want := mock.SomethingChanged{Value: "test"}
+
+		data, err := s.MarshalEvent(want)
+		assert.Ok(t, err)
+
+		// act
+		got, err := s.UnmarshalEvent(want.EventType(), domain.SchemaVersionOf(want), data)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, got)
+	})
+
+	t.Run("ItRestoresEventsRegisteredAsPointers", func(t *testing.T) {
+		// arrange
+		s := createSerializer(&mock.SomethingChanged{})
+		want := &mock.SomethingChanged{Value: "test"}
+
+		data, err := s.MarshalEvent(want)
+		assert.Ok(t, err)
+
+		// act
+		got, err := s.UnmarshalEvent(want.EventType(), domain.SchemaVersionOf(want), data)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, got)
+	})
+
+	t.Run("ItRestoresTheGivenSchemaVersion", func(t *testing.T) {
+		// arrange
+		s := createSerializer(mock.SomethingChangedV1{}, mock.SomethingChanged{})
+		want := mock.SomethingChangedV1{Text: "test"}
+
+		data, err := s.MarshalEvent(want)
+		assert.Ok(t, err)
+
+		// act
+		got, err := s.UnmarshalEvent(want.EventType(), domain.SchemaVersionOf(want), data)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, got)
+	})
+}
+
+func createSerializer(events ...domain.DomainEvent) *serializer.Serializer {
+	r := serializer.NewRegistry()
+	r.Register(events...)
+	return serializer.NewSerializer(r, serializer.JSONCodec{})
+}
@@ -0,0 +1,47 @@
+package snapshotstore
+
+import (
+	"sync"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// InMemorySnapshotStore stores and loads the latest aggregate snapshots from memory.
+type InMemorySnapshotStore struct {
+	snapshots   map[string]domain.Snapshot
+	snapshotsMu sync.RWMutex
+}
+
+// NewInMemorySnapshotStore creates a new instance of InMemorySnapshotStore.
+func NewInMemorySnapshotStore() *InMemorySnapshotStore {
+	return &InMemorySnapshotStore{
+		snapshots: make(map[string]domain.Snapshot),
+	}
+}
+
+// LoadSnapshot implements domain.SnapshotStore interface.
+func (s *InMemorySnapshotStore) LoadSnapshot(aggregateID domain.Identifier) (*domain.Snapshot, error) {
+	s.snapshotsMu.RLock()
+	defer s.snapshotsMu.RUnlock()
+
+	snapshot, ok := s.snapshots[aggregateID.String()]
+	if !ok {
+		return nil, nil
+	}
+	return &snapshot, nil
+}
+
+// StoreSnapshot implements domain.SnapshotStore interface.
+//
+// A snapshot older than the stored one is ignored.
+func (s *InMemorySnapshotStore) StoreSnapshot(snapshot domain.Snapshot) error {
+	s.snapshotsMu.Lock()
+	defer s.snapshotsMu.Unlock()
+
+	if stored, ok := s.snapshots[snapshot.AggregateID]; ok && stored.Version >= snapshot.Version {
+		return nil
+	}
+
+	s.snapshots[snapshot.AggregateID] = snapshot
+	return nil
+}
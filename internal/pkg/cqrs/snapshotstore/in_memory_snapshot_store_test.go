@@ -0,0 +1,65 @@
+package snapshotstore_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/snapshotstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that snapshot store implements domain.SnapshotStore interface.
+var _ domain.SnapshotStore = (*snapshotstore.InMemorySnapshotStore)(nil)
+
+func TestNewInMemorySnapshotStore(t *testing.T) {
+	t.Run("ItCreatesSnapshotStore", func(t *testing.T) {
+		assert.True(t, snapshotstore.NewInMemorySnapshotStore() != nil)
+	})
+}
+
+func TestInMemorySnapshotStoreLoadSnapshot(t *testing.T) {
+	t.Run("ItReturnsNilIfThereIsNoSnapshot", func(t *testing.T) {
+		// arrange
+		s := snapshotstore.NewInMemorySnapshotStore()
+
+		// act
+		got, err := s.LoadSnapshot(mock.StringIdentifier("TestAgg"))
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, got == nil)
+	})
+
+	t.Run("ItLoadsTheStoredSnapshot", func(t *testing.T) {
+		// arrange
+		s := snapshotstore.NewInMemorySnapshotStore()
+		want := domain.Snapshot{AggregateID: "TestAgg", Version: 3, State: mock.TestAggregateSnapshot{}}
+		assert.Ok(t, s.StoreSnapshot(want))
+
+		// act
+		got, err := s.LoadSnapshot(mock.StringIdentifier("TestAgg"))
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, &want, got)
+	})
+}
+
+func TestInMemorySnapshotStoreStoreSnapshot(t *testing.T) {
+	t.Run("ItKeepsTheLatestSnapshot", func(t *testing.T) {
+		// arrange
+		s := snapshotstore.NewInMemorySnapshotStore()
+		latest := domain.Snapshot{AggregateID: "TestAgg", Version: 5}
+
+		// act
+		assert.Ok(t, s.StoreSnapshot(latest))
+		assert.Ok(t, s.StoreSnapshot(domain.Snapshot{AggregateID: "TestAgg", Version: 3}))
+
+		// assert
+		got, err := s.LoadSnapshot(mock.StringIdentifier("TestAgg"))
+		assert.Ok(t, err)
+		assert.Equals(t, &latest, got)
+	})
+}
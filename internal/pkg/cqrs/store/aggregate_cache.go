@@ -0,0 +1,78 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// aggregateCache keeps the least recently used aggregates.
+//
+// The aggregates are checked out of the cache while they are in use, so an instance is never shared.
+type aggregateCache struct {
+	size      int
+	entries   map[string]*list.Element
+	recency   *list.List
+	entriesMu sync.Mutex
+}
+
+type cacheEntry struct {
+	key string
+	agg domain.AdvancedAggregate
+}
+
+func newAggregateCache(size int) *aggregateCache {
+	return &aggregateCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		recency: list.New(),
+	}
+}
+
+// checkOut removes the aggregate from the cache and returns it.
+func (c *aggregateCache) checkOut(key string) (domain.AdvancedAggregate, bool) {
+	c.entriesMu.Lock()
+	defer c.entriesMu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.remove(el)
+	return el.Value.(*cacheEntry).agg, true
+}
+
+// checkIn puts the aggregate into the cache unless a newer version of it is cached already.
+func (c *aggregateCache) checkIn(key string, agg domain.AdvancedAggregate) {
+	c.entriesMu.Lock()
+	defer c.entriesMu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		if el.Value.(*cacheEntry).agg.Version() > agg.Version() {
+			return
+		}
+		c.remove(el)
+	}
+
+	c.entries[key] = c.recency.PushFront(&cacheEntry{key: key, agg: agg})
+	for c.recency.Len() > c.size {
+		c.remove(c.recency.Back())
+	}
+}
+
+// evict removes the aggregate from the cache.
+func (c *aggregateCache) evict(key string) {
+	c.entriesMu.Lock()
+	defer c.entriesMu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+}
+
+func (c *aggregateCache) remove(el *list.Element) {
+	c.recency.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
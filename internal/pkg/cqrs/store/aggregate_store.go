@@ -1,15 +1,60 @@
 package store
 
-import "github.com/screwyprof/roshambo/pkg/domain"
+import (
+	"context"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Option configures AggregateStore.
+type Option func(*AggregateStore)
+
+// WithSnapshots makes the store take snapshots according to the given policy and rehydrate aggregates from them.
+//
+// Only the aggregates which implement domain.SnapshotAggregate interface are snapshotted.
+func WithSnapshots(snapshotStore domain.SnapshotStore, policy SnapshotPolicy) Option {
+	if snapshotStore == nil {
+		panic("snapshotStore is required")
+	}
+
+	if policy == nil {
+		panic("policy is required")
+	}
+
+	return func(s *AggregateStore) {
+		s.snapshotStore = snapshotStore
+		s.snapshotPolicy = policy
+	}
+}
+
+// WithCache makes the store keep up to size recently stored aggregates in memory.
+//
+// A cached aggregate is only caught up with the events which have been stored after it.
+// Only the aggregates which implement domain.Committer interface are cached.
+// An aggregate is evicted once storing its events fails, e.g. because of a concurrency conflict.
+func WithCache(size int) Option {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+
+	return func(s *AggregateStore) {
+		s.cache = newAggregateCache(size)
+	}
+}
 
 // AggregateStore loads and stores aggregates.
 type AggregateStore struct {
 	aggregateFactory domain.AggregateFactory
-	eventStore       domain.EventStore
+	eventStore       domain.ContextEventStore
+
+	snapshotStore  domain.SnapshotStore
+	snapshotPolicy SnapshotPolicy
+
+	cache *aggregateCache
 }
 
 // NewStore creates a new instance of AggregateStore.
-func NewStore(eventStore domain.EventStore, aggregateFactory domain.AggregateFactory) *AggregateStore {
+func NewStore(eventStore domain.EventStore, aggregateFactory domain.AggregateFactory, opts ...Option) *AggregateStore {
 	if eventStore == nil {
 		panic("eventStore is required")
 	}
@@ -18,25 +63,38 @@ func NewStore(eventStore domain.EventStore, aggregateFactory domain.AggregateFac
 		panic("aggregateFactory is required")
 	}
 
-	return &AggregateStore{
-		eventStore:       eventStore,
+	s := &AggregateStore{
+		eventStore:       domain.ContextEventStoreOf(eventStore),
 		aggregateFactory: aggregateFactory,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 // Load implements domain.AggregateStore interface.
+//
+// If the aggregate is cached or there is a snapshot of it, only the events which follow it are replayed.
 func (s *AggregateStore) Load(aggregateID domain.Identifier, aggregateType string) (domain.AdvancedAggregate, error) {
-	loadedEvents, err := s.eventStore.LoadEventsFor(aggregateID)
+	return s.LoadContext(context.Background(), aggregateID, aggregateType)
+}
+
+// LoadContext implements domain.ContextAggregateStore interface.
+func (s *AggregateStore) LoadContext(
+	ctx context.Context, aggregateID domain.Identifier, aggregateType string) (domain.AdvancedAggregate, error) {
+	agg, version, err := s.checkOut(aggregateID, aggregateType)
 	if err != nil {
 		return nil, err
 	}
 
-	agg, err := s.aggregateFactory.CreateAggregate(aggregateType, aggregateID)
+	loadedEvents, err := s.eventStore.LoadEventsFromContext(ctx, aggregateID, version)
 	if err != nil {
 		return nil, err
 	}
 
-	err = agg.Apply(loadedEvents...)
+	err = s.rehydrate(agg, loadedEvents)
 	if err != nil {
 		return nil, err
 	}
@@ -45,6 +103,103 @@ func (s *AggregateStore) Load(aggregateID domain.Identifier, aggregateType strin
 }
 
 // Store implements domain.AggregateStore interface.
-func (s *AggregateStore) Store(agg domain.AdvancedAggregate, events ...domain.DomainEvent) error {
-	return s.eventStore.StoreEventsFor(agg.AggregateID(), agg.Version(), events)
+//
+// Snapshots are an optimisation, so failing to take one doesn't fail storing the events.
+func (s *AggregateStore) Store(agg domain.AdvancedAggregate, events ...domain.Envelope) error {
+	return s.StoreContext(context.Background(), agg, events...)
+}
+
+// StoreContext implements domain.ContextAggregateStore interface.
+func (s *AggregateStore) StoreContext(ctx context.Context, agg domain.AdvancedAggregate, events ...domain.Envelope) error {
+	err := s.eventStore.StoreEventsForContext(ctx, agg.AggregateID(), agg.Version(), events)
+	if err != nil {
+		s.evict(agg)
+		return err
+	}
+
+	s.takeSnapshot(agg, len(events))
+	s.checkIn(agg)
+	return nil
+}
+
+// checkOut takes the aggregate from the cache or creates a new one, and returns the version it is at.
+func (s *AggregateStore) checkOut(
+	aggregateID domain.Identifier, aggregateType string) (domain.AdvancedAggregate, int, error) {
+	if s.cache != nil {
+		if agg, ok := s.cache.checkOut(cacheKey(aggregateID, aggregateType)); ok {
+			return agg, agg.Version(), nil
+		}
+	}
+
+	agg, err := s.aggregateFactory.CreateAggregate(aggregateType, aggregateID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	version, err := s.restoreSnapshot(agg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return agg, version, nil
+}
+
+// checkIn commits the stored events and puts the aggregate into the cache.
+func (s *AggregateStore) checkIn(agg domain.AdvancedAggregate) {
+	committer, ok := agg.(domain.Committer)
+	if s.cache == nil || !ok {
+		return
+	}
+
+	committer.MarkCommitted()
+	s.cache.checkIn(cacheKey(agg.AggregateID(), agg.AggregateType()), agg)
+}
+
+func (s *AggregateStore) evict(agg domain.AdvancedAggregate) {
+	if s.cache != nil {
+		s.cache.evict(cacheKey(agg.AggregateID(), agg.AggregateType()))
+	}
+}
+
+func cacheKey(aggregateID domain.Identifier, aggregateType string) string {
+	return aggregateType + "/" + aggregateID.String()
+}
+
+func (s *AggregateStore) rehydrate(agg domain.AdvancedAggregate, envelopes []domain.Envelope) error {
+	if rehydrator, ok := agg.(domain.Rehydrator); ok {
+		return rehydrator.Rehydrate(envelopes...)
+	}
+	return agg.Apply(domain.EventsOf(envelopes)...)
+}
+
+func (s *AggregateStore) restoreSnapshot(agg domain.AdvancedAggregate) (int, error) {
+	snapshotAgg, ok := agg.(domain.SnapshotAggregate)
+	if s.snapshotStore == nil || !ok {
+		return 0, nil
+	}
+
+	snapshot, err := s.snapshotStore.LoadSnapshot(agg.AggregateID())
+	if err != nil || snapshot == nil {
+		return 0, err
+	}
+
+	if err := snapshotAgg.Restore(*snapshot); err != nil {
+		return 0, err
+	}
+
+	return snapshot.Version, nil
+}
+
+func (s *AggregateStore) takeSnapshot(agg domain.AdvancedAggregate, stored int) {
+	snapshotAgg, ok := agg.(domain.SnapshotAggregate)
+	if s.snapshotStore == nil || !ok || !s.snapshotPolicy(agg.Version(), agg.Version()+stored) {
+		return
+	}
+
+	snapshot, err := snapshotAgg.Snapshot()
+	if err != nil {
+		return
+	}
+
+	_ = s.snapshotStore.StoreSnapshot(snapshot)
 }
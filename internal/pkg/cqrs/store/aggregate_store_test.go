@@ -1,12 +1,15 @@
 package store_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/segmentio/ksuid"
 
 	"github.com/screwyprof/roshambo/internal/pkg/assert"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/aggregate"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/snapshotstore"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
 
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/store"
@@ -16,6 +19,9 @@ import (
 // ensure that AggregateStore implements domain.AggregateStore interface.
 var _ domain.AggregateStore = (*store.AggregateStore)(nil)
 
+// ensure that AggregateStore implements domain.ContextAggregateStore interface.
+var _ domain.ContextAggregateStore = (*store.AggregateStore)(nil)
+
 func TestNewStore(t *testing.T) {
 	t.Run("ItPanicsIfEventStoreIsNotGiven", func(t *testing.T) {
 		factory := func() {
@@ -88,6 +94,27 @@ func TestAggregateStoreLoad(t *testing.T) {
 		assert.Ok(t, err)
 		assert.True(t, nil != got)
 	})
+
+	t.Run("ItCountsTheEventsSplitByUpcastersOnce", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		eventStore := &mock.EventStoreMock{
+			Loader: func(aggregateID domain.Identifier) ([]domain.Envelope, error) {
+				return []domain.Envelope{
+					{Version: 1, Event: mock.SomethingHappened{}},
+					{Version: 1, Event: mock.SomethingElseHappened{}},
+				}, nil
+			},
+		}
+		s := store.NewStore(eventStore, createFreshAggFactory())
+
+		// act
+		got, err := s.Load(ID, mock.TestAggregateType)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 1, got.Version())
+	})
 }
 
 func TestAggregateStoreStore(t *testing.T) {
@@ -98,14 +125,289 @@ func TestAggregateStoreStore(t *testing.T) {
 		agg := createAgg(ID)
 
 		// act
-		err := s.Store(agg, nil)
+		err := s.Store(agg, mock.Envelopes(mock.SomethingHappened{})...)
 
 		// assert
 		assert.Equals(t, mock.ErrEventStoreCannotStoreEvents, err)
 	})
 }
 
-func createAgg(ID ksuid.KSUID) *aggregate.Advanced {
+func TestAggregateStoreContext(t *testing.T) {
+	t.Run("ItDoesNotLoadEventsOnceTheContextIsDone", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		s := createAggregateStore(ID)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		_, err := s.LoadContext(ctx, ID, mock.TestAggregateType)
+
+		// assert
+		assert.Equals(t, context.Canceled, err)
+	})
+
+	t.Run("ItDoesNotStoreEventsOnceTheContextIsDone", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		s := createAggregateStore(ID)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		err := s.StoreContext(ctx, createAgg(ID), mock.Envelopes(mock.SomethingHappened{})...)
+
+		// assert
+		assert.Equals(t, context.Canceled, err)
+	})
+}
+
+func TestAggregateStoreWithSnapshots(t *testing.T) {
+	t.Run("ItPanicsIfSnapshotStoreIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			store.WithSnapshots(nil, store.SnapshotEvery(1))
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfSnapshotPolicyIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			store.WithSnapshots(snapshotstore.NewInMemorySnapshotStore(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItReplaysOnlyTheEventsWhichFollowTheSnapshot", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		snapshots := snapshotstore.NewInMemorySnapshotStore()
+		assert.Ok(t, snapshots.StoreSnapshot(domain.Snapshot{
+			AggregateID: ID.String(),
+			Version:     1,
+			State:       mock.TestAggregateSnapshot{AlreadyHappened: true},
+		}))
+
+		var loadedFrom int
+		eventStore := &mock.EventStoreMock{
+			Loader: func(aggregateID domain.Identifier) ([]domain.Envelope, error) {
+				return mock.Envelopes(mock.SomethingHappened{}, mock.SomethingElseHappened{}), nil
+			},
+		}
+		s := store.NewStore(
+			&loadFromRecorder{EventStoreMock: eventStore, loadedFrom: &loadedFrom},
+			createFreshAggFactory(),
+			store.WithSnapshots(snapshots, store.SnapshotEvery(1)),
+		)
+
+		// act
+		agg, err := s.Load(ID, mock.TestAggregateType)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 1, loadedFrom)
+		assert.Equals(t, 2, agg.Version())
+
+		_, err = agg.Handle(mock.MakeSomethingHappen{AggID: ID})
+		assert.Equals(t, mock.ErrItCanHappenOnceOnly, err)
+	})
+
+	t.Run("ItFailsIfItCannotRestoreTheSnapshot", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		snapshots := snapshotstore.NewInMemorySnapshotStore()
+		assert.Ok(t, snapshots.StoreSnapshot(domain.Snapshot{AggregateID: ID.String(), Version: 1, State: "invalid"}))
+
+		s := store.NewStore(
+			createEventStoreMock(nil, nil, nil),
+			createFreshAggFactory(),
+			store.WithSnapshots(snapshots, store.SnapshotEvery(1)),
+		)
+
+		// act
+		_, err := s.Load(ID, mock.TestAggregateType)
+
+		// assert
+		assert.Equals(t, mock.ErrInvalidSnapshot, err)
+	})
+
+	t.Run("ItTakesSnapshotsAccordingToThePolicy", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		snapshots := snapshotstore.NewInMemorySnapshotStore()
+		s := store.NewStore(
+			createEventStoreMock(nil, nil, nil),
+			createFreshAggFactory(),
+			store.WithSnapshots(snapshots, store.SnapshotEvery(2)),
+		)
+
+		agg, err := s.Load(ID, mock.TestAggregateType)
+		assert.Ok(t, err)
+		assert.Ok(t, agg.Apply(mock.SomethingElseHappened{}))
+
+		events, err := agg.Handle(mock.MakeSomethingHappen{AggID: ID})
+		assert.Ok(t, err)
+
+		// act
+		err = s.Store(agg, mock.Envelopes(events...)...)
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := snapshots.LoadSnapshot(ID)
+		assert.Ok(t, err)
+		assert.Equals(t, &domain.Snapshot{
+			AggregateID:   ID.String(),
+			AggregateType: mock.TestAggregateType,
+			Version:       2,
+			State:         mock.TestAggregateSnapshot{AlreadyHappened: true},
+		}, got)
+	})
+
+	t.Run("ItDoesNotTakeSnapshotsUnlessThePolicyAllowsIt", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		snapshots := snapshotstore.NewInMemorySnapshotStore()
+		s := store.NewStore(
+			createEventStoreMock(nil, nil, nil),
+			createFreshAggFactory(),
+			store.WithSnapshots(snapshots, store.SnapshotEvery(2)),
+		)
+
+		agg, err := s.Load(ID, mock.TestAggregateType)
+		assert.Ok(t, err)
+
+		events, err := agg.Handle(mock.MakeSomethingHappen{AggID: ID})
+		assert.Ok(t, err)
+
+		// act
+		err = s.Store(agg, mock.Envelopes(events...)...)
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := snapshots.LoadSnapshot(ID)
+		assert.Ok(t, err)
+		assert.True(t, got == nil)
+	})
+}
+
+func TestAggregateStoreWithCache(t *testing.T) {
+	t.Run("ItPanicsIfTheSizeIsNotPositive", func(t *testing.T) {
+		factory := func() {
+			store.WithCache(0)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItReusesTheStoredAggregate", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		s := store.NewStore(eventstore.NewInInMemoryEventStore(), createFreshAggFactory(), store.WithCache(2))
+		want := loadHandleAndStore(t, s, ID)
+
+		// act
+		got, err := s.Load(ID, mock.TestAggregateType)
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, want == got)
+		assert.Equals(t, 1, got.Version())
+	})
+
+	t.Run("ItCatchesTheCachedAggregateUpWithTheNewerEvents", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		es := eventstore.NewInInMemoryEventStore()
+		s := store.NewStore(es, createFreshAggFactory(), store.WithCache(2))
+		want := loadHandleAndStore(t, s, ID)
+
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(mock.SomethingElseHappened{})))
+
+		// act
+		got, err := s.Load(ID, mock.TestAggregateType)
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, want == got)
+		assert.Equals(t, 2, got.Version())
+	})
+
+	t.Run("ItEvictsTheAggregateIfItCannotBeStored", func(t *testing.T) {
+		// arrange
+		ID := ksuid.New()
+		es := eventstore.NewInInMemoryEventStore()
+		s := store.NewStore(es, createFreshAggFactory(), store.WithCache(2))
+		loadHandleAndStore(t, s, ID)
+
+		stale, err := s.Load(ID, mock.TestAggregateType)
+		assert.Ok(t, err)
+		assert.Ok(t, es.StoreEventsFor(ID, 1, mock.Envelopes(mock.SomethingElseHappened{})))
+
+		err = s.Store(stale, mock.Envelopes(mock.SomethingElseHappened{})...)
+		assert.Equals(t, domain.ErrConcurrencyViolation, err)
+
+		// act
+		got, err := s.Load(ID, mock.TestAggregateType)
+
+		// assert
+		assert.Ok(t, err)
+		assert.True(t, stale != got)
+		assert.Equals(t, 2, got.Version())
+	})
+
+	t.Run("ItEvictsTheLeastRecentlyUsedAggregate", func(t *testing.T) {
+		// arrange
+		first, second := ksuid.New(), ksuid.New()
+		s := store.NewStore(eventstore.NewInInMemoryEventStore(), createFreshAggFactory(), store.WithCache(1))
+		evicted := loadHandleAndStore(t, s, first)
+		cached := loadHandleAndStore(t, s, second)
+
+		// act
+		gotFirst, err := s.Load(first, mock.TestAggregateType)
+		assert.Ok(t, err)
+		gotSecond, err := s.Load(second, mock.TestAggregateType)
+		assert.Ok(t, err)
+
+		// assert
+		assert.True(t, evicted != gotFirst)
+		assert.True(t, cached == gotSecond)
+	})
+}
+
+func loadHandleAndStore(t *testing.T, s *store.AggregateStore, ID domain.Identifier) domain.AdvancedAggregate {
+	t.Helper()
+
+	agg, err := s.Load(ID, mock.TestAggregateType)
+	assert.Ok(t, err)
+
+	events, err := agg.Handle(mock.MakeSomethingHappen{AggID: ID})
+	assert.Ok(t, err)
+	assert.Ok(t, s.Store(agg, mock.Envelopes(events...)...))
+
+	return agg
+}
+
+type loadFromRecorder struct {
+	*mock.EventStoreMock
+	loadedFrom *int
+}
+
+func (r *loadFromRecorder) LoadEventsFrom(aggregateID domain.Identifier, version int) ([]domain.Envelope, error) {
+	*r.loadedFrom = version
+	return r.EventStoreMock.LoadEventsFrom(aggregateID, version)
+}
+
+func createFreshAggFactory() *aggregate.Factory {
+	f := aggregate.NewFactory()
+	f.RegisterAggregate(func(ID domain.Identifier) domain.AdvancedAggregate {
+		return createAgg(ID)
+	})
+	return f
+}
+
+func createAgg(ID domain.Identifier) *aggregate.Advanced {
 	pureAgg := mock.NewTestAggregate(ID)
 
 	commandHandler := aggregate.NewCommandHandler()
@@ -199,10 +501,10 @@ func createAggFactory(agg *aggregate.Advanced, empty bool) *aggregate.Factory {
 
 func createEventStoreMock(want []domain.DomainEvent, loadErr error, storeErr error) *mock.EventStoreMock {
 	eventStore := &mock.EventStoreMock{
-		Loader: func(aggregateID domain.Identifier) ([]domain.DomainEvent, error) {
-			return want, loadErr
+		Loader: func(aggregateID domain.Identifier) ([]domain.Envelope, error) {
+			return mock.Envelopes(want...), loadErr
 		},
-		Saver: func(aggregateID domain.Identifier, version int, events []domain.DomainEvent) error {
+		Saver: func(aggregateID domain.Identifier, version int, events []domain.Envelope) error {
 			return storeErr
 		},
 	}
@@ -0,0 +1,16 @@
+package store
+
+// SnapshotPolicy decides whether a snapshot should be taken
+// after an aggregate has moved from one version to another.
+type SnapshotPolicy func(from, to int) bool
+
+// SnapshotEvery takes a snapshot each time n more events have been stored.
+func SnapshotEvery(n int) SnapshotPolicy {
+	if n <= 0 {
+		panic("n must be positive")
+	}
+
+	return func(from, to int) bool {
+		return from/n != to/n
+	}
+}
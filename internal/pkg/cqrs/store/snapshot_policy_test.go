@@ -0,0 +1,27 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/store"
+)
+
+func TestSnapshotEvery(t *testing.T) {
+	t.Run("ItPanicsIfNIsNotPositive", func(t *testing.T) {
+		factory := func() {
+			store.SnapshotEvery(0)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItAllowsASnapshotWhenTheVersionCrossesAMultipleOfN", func(t *testing.T) {
+		policy := store.SnapshotEvery(3)
+
+		assert.True(t, !policy(0, 2))
+		assert.True(t, policy(2, 3))
+		assert.True(t, policy(2, 4))
+		assert.True(t, !policy(3, 5))
+		assert.True(t, policy(5, 9))
+	})
+}
@@ -0,0 +1,215 @@
+package subscription
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+const (
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+)
+
+// Option configures Subscription.
+type Option func(*Subscription)
+
+// FromPosition makes the subscription deliver the events which follow the given global position.
+func FromPosition(position int64) Option {
+	return func(s *Subscription) {
+		s.position = position
+	}
+}
+
+// WithBatchSize sets how many events are read from the log at once.
+func WithBatchSize(size int) Option {
+	return func(s *Subscription) {
+		s.batchSize = size
+	}
+}
+
+// WithPollInterval sets how often the log is checked for new events if the subscription hasn't been woken up.
+func WithPollInterval(interval time.Duration) Option {
+	return func(s *Subscription) {
+		s.pollInterval = interval
+	}
+}
+
+// WithErrorHandler sets a function which is called when the events cannot be delivered in the background.
+func WithErrorHandler(onError func(error)) Option {
+	return func(s *Subscription) {
+		s.onError = onError
+	}
+}
+
+// WithCheckpoint sets a function which is given the position every time it is advanced by a batch of events.
+//
+// The function is called even if the batch has been delivered partially, so the progress is not lost.
+func WithCheckpoint(checkpoint func(position int64) error) Option {
+	return func(s *Subscription) {
+		s.checkpoint = checkpoint
+	}
+}
+
+// Subscription delivers the events of the global event log to an event handler in their global order.
+//
+// It catches up with the history starting from the given position and then keeps following the log.
+// The subscription is also an event handler: being registered on an event bus it is woken up by the live events,
+// otherwise it polls the log. Either way the events are read from the log, so they are delivered exactly in order.
+//
+// If the handler fails, the position is not advanced and the event is delivered again next time.
+type Subscription struct {
+	eventLog     domain.EventLog
+	handler      domain.EventHandler
+	batchSize    int
+	pollInterval time.Duration
+	onError      func(error)
+	checkpoint   func(position int64) error
+
+	position  int64
+	deliverMu sync.Mutex
+
+	wakeUp    chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+// New creates a new instance of Subscription.
+func New(eventLog domain.EventLog, handler domain.EventHandler, opts ...Option) *Subscription {
+	if eventLog == nil {
+		panic("eventLog is required")
+	}
+
+	if handler == nil {
+		panic("handler is required")
+	}
+
+	s := &Subscription{
+		eventLog:     eventLog,
+		handler:      handler,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+		onError:      func(error) {},
+		checkpoint:   func(int64) error { return nil },
+		wakeUp:       make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Position returns the global position of the last delivered event.
+func (s *Subscription) Position() int64 {
+	return atomic.LoadInt64(&s.position)
+}
+
+// CatchUp delivers all the events which are in the log at the moment.
+func (s *Subscription) CatchUp() error {
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
+
+	for {
+		events, err := s.eventLog.LoadAllEventsFrom(s.Position(), s.batchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := s.deliverBatch(events); err != nil {
+			return err
+		}
+	}
+}
+
+// Start starts following the log in the background.
+func (s *Subscription) Start() {
+	s.startOnce.Do(func() {
+		s.wg.Add(1)
+		go s.run()
+	})
+}
+
+// Stop stops following the log and waits until the delivery in progress is finished.
+func (s *Subscription) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
+
+// SubscribedTo implements domain.EventHandler interface.
+func (s *Subscription) SubscribedTo() domain.EventMatcher {
+	return domain.MatchAny()
+}
+
+// Handle implements domain.EventHandler interface.
+//
+// It only wakes the subscription up, the event itself is read from the log.
+func (s *Subscription) Handle(domain.Envelope) error {
+	select {
+	case s.wakeUp <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *Subscription) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.CatchUp(); err != nil {
+			s.onError(err)
+		}
+
+		select {
+		case <-s.wakeUp:
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Subscription) deliverBatch(events []domain.Envelope) error {
+	from := s.Position()
+	deliveryErr := s.deliver(events)
+
+	if position := s.Position(); position != from {
+		if err := s.checkpoint(position); err != nil {
+			return err
+		}
+	}
+	return deliveryErr
+}
+
+// deliver hands the events to the handler advancing the position.
+//
+// The events split by upcasters share a position, so it is advanced once all of them are handled.
+func (s *Subscription) deliver(events []domain.Envelope) error {
+	matches := s.handler.SubscribedTo()
+	for i, e := range events {
+		if matches(e.Event) {
+			if err := s.handler.Handle(e); err != nil {
+				return err
+			}
+		}
+
+		if i == len(events)-1 || events[i+1].Position != e.Position {
+			atomic.StoreInt64(&s.position, e.Position)
+		}
+	}
+	return nil
+}
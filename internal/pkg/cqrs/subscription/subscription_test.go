@@ -0,0 +1,279 @@
+package subscription_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventbus"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/subscription"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that Subscription implements domain.EventHandler interface.
+var _ domain.EventHandler = (*subscription.Subscription)(nil)
+
+var (
+	errCannotLoadEvents      = errors.New("cannot load events")
+	errCannotStoreCheckpoint = errors.New("cannot store checkpoint")
+)
+
+func TestNew(t *testing.T) {
+	t.Run("ItPanicsIfEventLogIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			subscription.New(nil, &mock.EventHandlerMock{})
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfHandlerIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			subscription.New(eventstore.NewInInMemoryEventStore(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestSubscriptionCatchUp(t *testing.T) {
+	t.Run("ItDeliversTheHistoryInTheGlobalOrder", func(t *testing.T) {
+		// arrange
+		es := createEventStore(t)
+		handler := &mock.EventHandlerMock{}
+		s := subscription.New(es, handler, subscription.WithBatchSize(1))
+
+		// act
+		err := s.CatchUp()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{
+			mock.SomethingHappened{},
+			mock.SomethingElseHappened{},
+			mock.SomethingHappened{},
+		}, handler.Happened)
+		assert.Equals(t, int64(4), s.Position())
+	})
+
+	t.Run("ItStartsFromTheGivenPosition", func(t *testing.T) {
+		// arrange
+		es := createEventStore(t)
+		handler := &mock.EventHandlerMock{}
+		s := subscription.New(es, handler, subscription.FromPosition(2))
+
+		// act
+		err := s.CatchUp()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingHappened{}}, handler.Happened)
+	})
+
+	t.Run("ItFailsIfTheEventsCannotBeLoaded", func(t *testing.T) {
+		// arrange
+		s := subscription.New(eventLogFunc(func(position int64, limit int) ([]domain.Envelope, error) {
+			return nil, errCannotLoadEvents
+		}), &mock.EventHandlerMock{})
+
+		// act
+		err := s.CatchUp()
+
+		// assert
+		assert.Equals(t, errCannotLoadEvents, err)
+	})
+
+	t.Run("ItDoesNotAdvanceThePositionIfTheHandlerFails", func(t *testing.T) {
+		// arrange
+		es := createEventStore(t)
+		handler := &mock.EventHandlerMock{Err: mock.ErrCannotHandleEvent}
+		s := subscription.New(es, handler)
+
+		// act
+		err := s.CatchUp()
+
+		// assert
+		assert.Equals(t, mock.ErrCannotHandleEvent, err)
+		assert.Equals(t, int64(0), s.Position())
+	})
+
+	t.Run("ItAdvancesThePositionOnceForTheSplitEvents", func(t *testing.T) {
+		// arrange
+		var delivered int
+		s := subscription.New(eventLogFunc(func(position int64, limit int) ([]domain.Envelope, error) {
+			if position > 0 {
+				return nil, nil
+			}
+			return []domain.Envelope{
+				{Position: 1, Event: mock.SomethingHappened{}},
+				{Position: 1, Event: mock.SomethingElseHappened{}},
+			}, nil
+		}), eventHandlerFunc(func(e domain.Envelope) error {
+			delivered++
+			if delivered == 2 {
+				return mock.ErrCannotHandleEvent
+			}
+			return nil
+		}))
+
+		// act
+		err := s.CatchUp()
+
+		// assert
+		assert.Equals(t, mock.ErrCannotHandleEvent, err)
+		assert.Equals(t, int64(0), s.Position())
+	})
+}
+
+func TestSubscriptionWithCheckpoint(t *testing.T) {
+	t.Run("ItCheckpointsEveryBatch", func(t *testing.T) {
+		// arrange
+		var checkpoints []int64
+		s := subscription.New(createEventStore(t), &mock.EventHandlerMock{},
+			subscription.WithBatchSize(3),
+			subscription.WithCheckpoint(func(position int64) error {
+				checkpoints = append(checkpoints, position)
+				return nil
+			}),
+		)
+
+		// act
+		err := s.CatchUp()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []int64{3, 4}, checkpoints)
+	})
+
+	t.Run("ItCheckpointsThePartiallyDeliveredBatch", func(t *testing.T) {
+		// arrange
+		var checkpoints []int64
+		handler := &mock.EventHandlerMock{Matcher: domain.MatchEvent("SomethingElseHappened"), Err: mock.ErrCannotHandleEvent}
+		s := subscription.New(createEventStore(t), handler,
+			subscription.WithCheckpoint(func(position int64) error {
+				checkpoints = append(checkpoints, position)
+				return nil
+			}),
+		)
+
+		// act
+		err := s.CatchUp()
+
+		// assert
+		assert.Equals(t, mock.ErrCannotHandleEvent, err)
+		assert.Equals(t, []int64{1}, checkpoints)
+	})
+
+	t.Run("ItFailsIfTheCheckpointCannotBeStored", func(t *testing.T) {
+		// arrange
+		s := subscription.New(createEventStore(t), &mock.EventHandlerMock{},
+			subscription.WithCheckpoint(func(position int64) error {
+				return errCannotStoreCheckpoint
+			}),
+		)
+
+		// act
+		err := s.CatchUp()
+
+		// assert
+		assert.Equals(t, errCannotStoreCheckpoint, err)
+	})
+}
+
+func TestSubscriptionStart(t *testing.T) {
+	t.Run("ItSwitchesToTheLiveEvents", func(t *testing.T) {
+		// arrange
+		es := createEventStore(t)
+		delivered := make(chan domain.Envelope, 10)
+
+		s := subscription.New(es, eventHandlerFunc(func(e domain.Envelope) error {
+			delivered <- e
+			return nil
+		}), subscription.WithPollInterval(time.Hour))
+
+		bus := eventbus.NewInMemoryEventBus()
+		bus.Register(s)
+
+		// act
+		s.Start()
+		defer s.Stop()
+
+		live := mock.Envelopes(mock.SomethingElseHappened{})
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg3"), 0, live))
+		assert.Ok(t, bus.Publish(live...))
+
+		// assert
+		var positions []int64
+		for i := 0; i < 5; i++ {
+			positions = append(positions, (<-delivered).Position)
+		}
+		assert.Equals(t, []int64{1, 2, 3, 4, 5}, positions)
+	})
+
+	t.Run("ItPollsTheLog", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		delivered := make(chan domain.Envelope, 1)
+
+		s := subscription.New(es, eventHandlerFunc(func(e domain.Envelope) error {
+			delivered <- e
+			return nil
+		}), subscription.WithPollInterval(time.Millisecond))
+
+		// act
+		s.Start()
+		defer s.Stop()
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg"), 0, mock.Envelopes(mock.SomethingHappened{})))
+
+		// assert
+		assert.Equals(t, int64(1), (<-delivered).Position)
+	})
+
+	t.Run("ItReportsTheErrors", func(t *testing.T) {
+		// arrange
+		errs := make(chan error, 1)
+		s := subscription.New(eventLogFunc(func(position int64, limit int) ([]domain.Envelope, error) {
+			return nil, errCannotLoadEvents
+		}), &mock.EventHandlerMock{}, subscription.WithErrorHandler(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}))
+
+		// act
+		s.Start()
+		defer s.Stop()
+
+		// assert
+		assert.Equals(t, errCannotLoadEvents, <-errs)
+	})
+}
+
+type eventLogFunc func(position int64, limit int) ([]domain.Envelope, error)
+
+func (f eventLogFunc) LoadAllEventsFrom(position int64, limit int) ([]domain.Envelope, error) {
+	return f(position, limit)
+}
+
+type eventHandlerFunc func(e domain.Envelope) error
+
+func (f eventHandlerFunc) SubscribedTo() domain.EventMatcher {
+	return domain.MatchAny()
+}
+
+func (f eventHandlerFunc) Handle(e domain.Envelope) error {
+	return f(e)
+}
+
+func createEventStore(t *testing.T) *eventstore.InMemoryEventStore {
+	t.Helper()
+
+	es := eventstore.NewInInMemoryEventStore()
+	assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, mock.Envelopes(mock.SomethingHappened{})))
+	assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg2"), 0, mock.Envelopes(mock.SomethingElseHappened{})))
+	assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 1, mock.Envelopes(mock.SomethingChanged{}, mock.SomethingHappened{})))
+	return es
+}
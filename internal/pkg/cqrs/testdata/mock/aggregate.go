@@ -10,6 +10,7 @@ var (
 	ErrItCanHappenOnceOnly  = errors.New("some business rule error occurred")
 	ErrMakeSomethingHandlerNotFound  = errors.New("handler for MakeSomethingHappen command is not found")
 	ErrOnSomethingHappenedApplierNotFound  = errors.New("event applier for OnSomethingHappened event is not found")
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
 
 	TestAggregateType = "mock.TestAggregate"
 )
@@ -43,6 +44,26 @@ func (a *TestAggregate) AggregateType() string {
 	return "mock.TestAggregate"
 }
 
+// TestAggregateSnapshot is an exported state of TestAggregate.
+type TestAggregateSnapshot struct {
+	AlreadyHappened bool
+}
+
+// SnapshotState implements domain.Snapshotter interface.
+func (a *TestAggregate) SnapshotState() (interface{}, error) {
+	return TestAggregateSnapshot{AlreadyHappened: a.alreadyHappened}, nil
+}
+
+// RestoreState implements domain.Snapshotter interface.
+func (a *TestAggregate) RestoreState(state interface{}) error {
+	snapshot, ok := state.(TestAggregateSnapshot)
+	if !ok {
+		return ErrInvalidSnapshot
+	}
+	a.alreadyHappened = snapshot.AlreadyHappened
+	return nil
+}
+
 func (a *TestAggregate) MakeSomethingHappen(c MakeSomethingHappen) ([]domain.DomainEvent, error) {
 	if a.alreadyHappened {
 		return nil, ErrItCanHappenOnceOnly
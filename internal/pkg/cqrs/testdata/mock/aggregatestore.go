@@ -16,7 +16,7 @@ var (
 // AggregateStoreMock mocks event store.
 type AggregateStoreMock struct {
 	Loader func(aggregateID domain.Identifier, aggregateType string) (domain.AdvancedAggregate, error)
-	Saver func(aggregate domain.AdvancedAggregate, events ...domain.DomainEvent) error
+	Saver func(aggregate domain.AdvancedAggregate, events ...domain.Envelope) error
 }
 
 // Load implements domain.AggregateStore interface.
@@ -26,6 +26,6 @@ func (m *AggregateStoreMock) Load(
 }
 
 // StoreEventsFor implements domain.AggregateStore interface.
-func (m *AggregateStoreMock) Store(aggregate domain.AdvancedAggregate, events ...domain.DomainEvent) error {
+func (m *AggregateStoreMock) Store(aggregate domain.AdvancedAggregate, events ...domain.Envelope) error {
 	return m.Saver(aggregate, events...)
 }
@@ -3,6 +3,7 @@ package mock
 import "github.com/screwyprof/roshambo/pkg/domain"
 
 type MakeSomethingHappen struct{
+	ID string
 	AggID domain.Identifier
 }
 
@@ -15,4 +16,7 @@ func (c MakeSomethingHappen) AggregateType() string {
 }
 func (c MakeSomethingHappen) CommandType() string {
 	return "MakeSomethingHappen"
+}
+func (c MakeSomethingHappen) CommandID() string {
+	return c.ID
 }
\ No newline at end of file
@@ -13,6 +13,7 @@ var (
 
 type TestEventHandler struct {
 	SomethingHappened string
+	SomethingChanged domain.Envelope
 }
 
 func (h *TestEventHandler) OnSomethingHappened(e SomethingHappened) error {
@@ -24,6 +25,17 @@ func (h *TestEventHandler) OnSomethingElseHappened(e SomethingElseHappened) erro
 	return ErrCannotHandleEvent
 }
 
+func (h *TestEventHandler) OnSomethingChanged(e SomethingChanged, envelope domain.Envelope) error {
+	h.SomethingChanged = envelope
+	return nil
+}
+
+func (h *TestEventHandler) Reset() error {
+	h.SomethingHappened = ""
+	h.SomethingChanged = domain.Envelope{}
+	return nil
+}
+
 func (h *TestEventHandler) SomeInvalidMethod() {
 
 }
@@ -41,11 +53,11 @@ func (h *EventHandlerMock) SubscribedTo() domain.EventMatcher {
 	return domain.MatchAnyEventOf("SomethingHappened", "SomethingElseHappened")
 }
 
-func (h *EventHandlerMock) Handle(event domain.DomainEvent) error {
+func (h *EventHandlerMock) Handle(envelope domain.Envelope) error {
 	if h.Err != nil {
 		return h.Err
 	}
-	switch e := event.(type) {
+	switch e := envelope.Event.(type) {
 	case SomethingHappened:
 		h.OnSomethingHappened(e)
 	case SomethingElseHappened:
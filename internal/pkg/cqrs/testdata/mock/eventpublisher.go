@@ -13,11 +13,11 @@ var (
 
 // EventPublisherMock mocks event store.
 type EventPublisherMock struct {
-	Publisher func(e ...domain.DomainEvent) error
+	Publisher func(e ...domain.Envelope) error
 }
 
 // Publish implements domain.EventPublisher interface.
-func (m *EventPublisherMock) Publish(e ...domain.DomainEvent) error {
+func (m *EventPublisherMock) Publish(e ...domain.Envelope) error {
 	return m.Publisher(e...)
 }
 
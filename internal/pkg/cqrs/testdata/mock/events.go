@@ -1,5 +1,7 @@
 package mock
 
+import "github.com/screwyprof/roshambo/pkg/domain"
+
 type SomethingHappened struct{}
 func (c SomethingHappened) EventType() string {
 	return "SomethingHappened"
@@ -8,4 +10,32 @@ func (c SomethingHappened) EventType() string {
 type SomethingElseHappened struct{}
 func (c SomethingElseHappened) EventType() string {
 	return "SomethingElseHappened"
-}
\ No newline at end of file
+}
+
+type SomethingChanged struct {
+	Value string
+}
+func (c SomethingChanged) EventType() string {
+	return "SomethingChanged"
+}
+func (c SomethingChanged) SchemaVersion() int {
+	return 2
+}
+
+// SomethingChangedV1 is the first schema version of SomethingChanged.
+type SomethingChangedV1 struct {
+	Text string
+}
+func (c SomethingChangedV1) EventType() string {
+	return "SomethingChanged"
+}
+
+
+// Envelopes wraps the given events into envelopes.
+func Envelopes(events ...domain.DomainEvent) []domain.Envelope {
+	envelopes := make([]domain.Envelope, 0, len(events))
+	for _, e := range events {
+		envelopes = append(envelopes, domain.Envelope{Event: e})
+	}
+	return envelopes
+}
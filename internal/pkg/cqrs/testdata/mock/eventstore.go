@@ -15,16 +15,25 @@ var (
 
 // EventStoreMock mocks event store.
 type EventStoreMock struct {
-	Loader func(aggregateID domain.Identifier) ([]domain.DomainEvent, error)
-	Saver  func(aggregateID domain.Identifier, version int, events []domain.DomainEvent) error
+	Loader func(aggregateID domain.Identifier) ([]domain.Envelope, error)
+	Saver  func(aggregateID domain.Identifier, version int, events []domain.Envelope) error
 }
 
 // LoadEventsFor implements domain.EventStore interface.
-func (m *EventStoreMock) LoadEventsFor(aggregateID domain.Identifier) ([]domain.DomainEvent, error) {
+func (m *EventStoreMock) LoadEventsFor(aggregateID domain.Identifier) ([]domain.Envelope, error) {
 	return m.Loader(aggregateID)
 }
 
+// LoadEventsFrom implements domain.EventStore interface.
+func (m *EventStoreMock) LoadEventsFrom(aggregateID domain.Identifier, version int) ([]domain.Envelope, error) {
+	events, err := m.Loader(aggregateID)
+	if err != nil || version >= len(events) {
+		return nil, err
+	}
+	return events[version:], nil
+}
+
 // StoreEventsFor implements domain.EventStore interface.
-func (m *EventStoreMock) StoreEventsFor(aggregateID domain.Identifier, version int, events []domain.DomainEvent) error {
+func (m *EventStoreMock) StoreEventsFor(aggregateID domain.Identifier, version int, events []domain.Envelope) error {
 	return m.Saver(aggregateID, version, events)
 }
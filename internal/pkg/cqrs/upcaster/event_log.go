@@ -0,0 +1,34 @@
+package upcaster
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+// EventLog upcasts the events loaded from the underlying global event log.
+type EventLog struct {
+	eventLog domain.EventLog
+	upcaster domain.Upcaster
+}
+
+// NewEventLog creates a new instance of EventLog.
+func NewEventLog(eventLog domain.EventLog, upcaster domain.Upcaster) *EventLog {
+	if eventLog == nil {
+		panic("eventLog is required")
+	}
+
+	if upcaster == nil {
+		panic("upcaster is required")
+	}
+
+	return &EventLog{
+		eventLog: eventLog,
+		upcaster: upcaster,
+	}
+}
+
+// LoadAllEventsFrom implements domain.EventLog interface.
+func (l *EventLog) LoadAllEventsFrom(position int64, limit int) ([]domain.Envelope, error) {
+	envelopes, err := l.eventLog.LoadAllEventsFrom(position, limit)
+	if err != nil {
+		return nil, err
+	}
+	return l.upcaster.Upcast(envelopes)
+}
@@ -0,0 +1,52 @@
+package upcaster_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/upcaster"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that EventLog implements domain.EventLog interface.
+var _ domain.EventLog = (*upcaster.EventLog)(nil)
+
+func TestNewEventLog(t *testing.T) {
+	t.Run("ItPanicsIfEventLogIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			upcaster.NewEventLog(nil, upcaster.NewChain())
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfUpcasterIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			upcaster.NewEventLog(eventstore.NewInInMemoryEventStore(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestEventLogLoadAllEventsFrom(t *testing.T) {
+	t.Run("ItUpcastsTheLoadedEvents", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, mock.Envelopes(mock.SomethingHappened{})))
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg2"), 0, mock.Envelopes(mock.SomethingChangedV1{Text: "1"})))
+
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, upcastSomethingChanged)
+		l := upcaster.NewEventLog(es, c)
+
+		// act
+		got, err := l.LoadAllEventsFrom(1, 0)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingChanged{Value: "1"}}, domain.EventsOf(got))
+		assert.Equals(t, int64(2), got[0].Position)
+	})
+}
@@ -0,0 +1,43 @@
+package upcaster
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+// EventStore upcasts the events loaded from the underlying event store.
+type EventStore struct {
+	domain.EventStore
+	upcaster domain.Upcaster
+}
+
+// NewEventStore creates a new instance of EventStore.
+func NewEventStore(eventStore domain.EventStore, upcaster domain.Upcaster) *EventStore {
+	if eventStore == nil {
+		panic("eventStore is required")
+	}
+
+	if upcaster == nil {
+		panic("upcaster is required")
+	}
+
+	return &EventStore{
+		EventStore: eventStore,
+		upcaster:   upcaster,
+	}
+}
+
+// LoadEventsFor implements domain.EventStore interface.
+func (s *EventStore) LoadEventsFor(aggregateID domain.Identifier) ([]domain.Envelope, error) {
+	envelopes, err := s.EventStore.LoadEventsFor(aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	return s.upcaster.Upcast(envelopes)
+}
+
+// LoadEventsFrom implements domain.EventStore interface.
+func (s *EventStore) LoadEventsFrom(aggregateID domain.Identifier, version int) ([]domain.Envelope, error) {
+	envelopes, err := s.EventStore.LoadEventsFrom(aggregateID, version)
+	if err != nil {
+		return nil, err
+	}
+	return s.upcaster.Upcast(envelopes)
+}
@@ -0,0 +1,92 @@
+package upcaster_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/upcaster"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that EventStore implements domain.EventStore interface.
+var _ domain.EventStore = (*upcaster.EventStore)(nil)
+
+func TestNewEventStore(t *testing.T) {
+	t.Run("ItPanicsIfEventStoreIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			upcaster.NewEventStore(nil, upcaster.NewChain())
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfUpcasterIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			upcaster.NewEventStore(eventstore.NewInInMemoryEventStore(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestEventStoreLoadEventsFor(t *testing.T) {
+	t.Run("ItFailsIfTheEventsCannotBeLoaded", func(t *testing.T) {
+		// arrange
+		es := upcaster.NewEventStore(&mock.EventStoreMock{
+			Loader: func(aggregateID domain.Identifier) ([]domain.Envelope, error) {
+				return nil, mock.ErrEventStoreCannotLoadEvents
+			},
+		}, upcaster.NewChain())
+
+		// act
+		_, err := es.LoadEventsFor(mock.StringIdentifier("TestAgg"))
+
+		// assert
+		assert.Equals(t, mock.ErrEventStoreCannotLoadEvents, err)
+	})
+
+	t.Run("ItUpcastsTheLoadedEvents", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := createEventStore(t, ID, mock.SomethingChangedV1{Text: "1"}, mock.SomethingChanged{Value: "2"})
+
+		// act
+		got, err := es.LoadEventsFor(ID)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{
+			mock.SomethingChanged{Value: "1"},
+			mock.SomethingChanged{Value: "2"},
+		}, domain.EventsOf(got))
+	})
+}
+
+func TestEventStoreLoadEventsFrom(t *testing.T) {
+	t.Run("ItUpcastsTheEventsFollowingTheGivenVersion", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("TestAgg")
+		es := createEventStore(t, ID, mock.SomethingHappened{}, mock.SomethingChangedV1{Text: "1"})
+
+		// act
+		got, err := es.LoadEventsFrom(ID, 1)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingChanged{Value: "1"}}, domain.EventsOf(got))
+		assert.Equals(t, 2, got[0].Version)
+	})
+}
+
+func createEventStore(t *testing.T, ID domain.Identifier, events ...domain.DomainEvent) *upcaster.EventStore {
+	t.Helper()
+
+	es := eventstore.NewInInMemoryEventStore()
+	assert.Ok(t, es.StoreEventsFor(ID, 0, mock.Envelopes(events...)))
+
+	c := upcaster.NewChain()
+	c.RegisterUpcaster("SomethingChanged", 1, upcastSomethingChanged)
+
+	return upcaster.NewEventStore(es, c)
+}
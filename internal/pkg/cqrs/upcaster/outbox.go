@@ -0,0 +1,41 @@
+package upcaster
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+// Outbox upcasts the events pending in the underlying outbox, so that the relay publishes their latest schemas.
+type Outbox struct {
+	outbox   domain.Outbox
+	upcaster domain.Upcaster
+}
+
+// NewOutbox creates a new instance of Outbox.
+func NewOutbox(outbox domain.Outbox, upcaster domain.Upcaster) *Outbox {
+	if outbox == nil {
+		panic("outbox is required")
+	}
+
+	if upcaster == nil {
+		panic("upcaster is required")
+	}
+
+	return &Outbox{
+		outbox:   outbox,
+		upcaster: upcaster,
+	}
+}
+
+// PendingEvents implements domain.Outbox interface.
+//
+// The events split from a single one share its position, so they are marked as published together.
+func (o *Outbox) PendingEvents(limit int) ([]domain.Envelope, error) {
+	envelopes, err := o.outbox.PendingEvents(limit)
+	if err != nil {
+		return nil, err
+	}
+	return o.upcaster.Upcast(envelopes)
+}
+
+// MarkPublished implements domain.Outbox interface.
+func (o *Outbox) MarkPublished(position int64) error {
+	return o.outbox.MarkPublished(position)
+}
@@ -0,0 +1,82 @@
+package upcaster_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/outbox"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/upcaster"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that Outbox implements domain.Outbox interface.
+var _ domain.Outbox = (*upcaster.Outbox)(nil)
+
+func TestNewOutbox(t *testing.T) {
+	t.Run("ItPanicsIfOutboxIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			upcaster.NewOutbox(nil, upcaster.NewChain())
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfUpcasterIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			upcaster.NewOutbox(eventstore.NewInInMemoryEventStore(), nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestOutboxPendingEvents(t *testing.T) {
+	t.Run("ItUpcastsThePendingEvents", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		events := mock.Envelopes(mock.SomethingChangedV1{Text: "1"})
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, events))
+
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, upcastSomethingChanged)
+		o := upcaster.NewOutbox(es, c)
+
+		// act
+		got, err := o.PendingEvents(0)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingChanged{Value: "1"}}, domain.EventsOf(got))
+		assert.Equals(t, int64(1), got[0].Position)
+	})
+
+	t.Run("TheRelayPublishesTheUpcastedEvents", func(t *testing.T) {
+		// arrange
+		es := eventstore.NewInInMemoryEventStore()
+		events := mock.Envelopes(mock.SomethingChangedV1{Text: "1"})
+		assert.Ok(t, es.StoreEventsFor(mock.StringIdentifier("TestAgg1"), 0, events))
+
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, upcastSomethingChanged)
+
+		var published []domain.DomainEvent
+		r := outbox.NewRelay(upcaster.NewOutbox(es, c), &mock.EventPublisherMock{
+			Publisher: func(e ...domain.Envelope) error {
+				published = append(published, domain.EventsOf(e)...)
+				return nil
+			},
+		})
+
+		// act
+		err := r.Flush()
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{mock.SomethingChanged{Value: "1"}}, published)
+
+		pending, err := es.PendingEvents(0)
+		assert.Ok(t, err)
+		assert.Equals(t, 0, len(pending))
+	})
+}
@@ -0,0 +1,98 @@
+package upcaster
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// Func upcasts an event to the next schema version.
+//
+// It may split the event into several ones.
+type Func func(e domain.DomainEvent) ([]domain.DomainEvent, error)
+
+type schema struct {
+	eventType string
+	version   int
+}
+
+// Chain upcasts events step by step until they reach their latest schema versions.
+type Chain struct {
+	upcasters   map[schema]Func
+	upcastersMu sync.RWMutex
+}
+
+// NewChain creates a new instance of Chain.
+func NewChain() *Chain {
+	return &Chain{
+		upcasters: make(map[schema]Func),
+	}
+}
+
+// RegisterUpcaster registers an upcaster for the given event type and its schema version.
+func (c *Chain) RegisterUpcaster(eventType string, fromVersion int, upcaster Func) {
+	c.upcastersMu.Lock()
+	defer c.upcastersMu.Unlock()
+	c.upcasters[schema{eventType: eventType, version: fromVersion}] = upcaster
+}
+
+// Upcast implements domain.Upcaster interface.
+//
+// The events split from a single one share its envelope.
+func (c *Chain) Upcast(envelopes []domain.Envelope) ([]domain.Envelope, error) {
+	if envelopes == nil {
+		return nil, nil
+	}
+
+	upcasted := make([]domain.Envelope, 0, len(envelopes))
+	for _, e := range envelopes {
+		events, err := c.upcast(e.Event)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			envelope := e
+			envelope.Event = event
+			upcasted = append(upcasted, envelope)
+		}
+	}
+	return upcasted, nil
+}
+
+func (c *Chain) upcast(e domain.DomainEvent) ([]domain.DomainEvent, error) {
+	from := schema{eventType: e.EventType(), version: domain.SchemaVersionOf(e)}
+
+	upcaster, ok := c.upcasterFor(from)
+	if !ok {
+		return []domain.DomainEvent{e}, nil
+	}
+
+	events, err := upcaster(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var upcasted []domain.DomainEvent
+	for _, event := range events {
+		if event.EventType() == from.eventType && domain.SchemaVersionOf(event) <= from.version {
+			return nil, fmt.Errorf("upcaster for %s event v%d didn't upgrade it", from.eventType, from.version)
+		}
+
+		next, err := c.upcast(event)
+		if err != nil {
+			return nil, err
+		}
+		upcasted = append(upcasted, next...)
+	}
+	return upcasted, nil
+}
+
+func (c *Chain) upcasterFor(from schema) (Func, bool) {
+	c.upcastersMu.RLock()
+	defer c.upcastersMu.RUnlock()
+
+	upcaster, ok := c.upcasters[from]
+	return upcaster, ok
+}
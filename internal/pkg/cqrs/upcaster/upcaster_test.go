@@ -0,0 +1,137 @@
+package upcaster_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/upcaster"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ensure that Chain implements domain.Upcaster interface.
+var _ domain.Upcaster = (*upcaster.Chain)(nil)
+
+var errCannotUpcast = errors.New("cannot upcast")
+
+func TestNewChain(t *testing.T) {
+	t.Run("ItCreatesNewInstance", func(t *testing.T) {
+		assert.True(t, upcaster.NewChain() != nil)
+	})
+}
+
+func TestChainUpcast(t *testing.T) {
+	t.Run("ItLeavesTheLatestVersionsAsIs", func(t *testing.T) {
+		// arrange
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, upcastSomethingChanged)
+
+		want := mock.Envelopes(mock.SomethingHappened{}, mock.SomethingChanged{Value: "test"})
+
+		// act
+		got, err := c.Upcast(want)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, want, got)
+	})
+
+	t.Run("ItUpcastsTheOlderVersions", func(t *testing.T) {
+		// arrange
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, upcastSomethingChanged)
+
+		envelopes := []domain.Envelope{{ID: "1", Version: 1, Event: mock.SomethingChangedV1{Text: "test"}}}
+
+		// act
+		got, err := c.Upcast(envelopes)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.Envelope{{ID: "1", Version: 1, Event: mock.SomethingChanged{Value: "test"}}}, got)
+	})
+
+	t.Run("ItSplitsEventsSharingTheirEnvelope", func(t *testing.T) {
+		// arrange
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, func(e domain.DomainEvent) ([]domain.DomainEvent, error) {
+			return []domain.DomainEvent{mock.SomethingHappened{}, mock.SomethingChanged{Value: "next"}}, nil
+		})
+
+		envelopes := []domain.Envelope{
+			{ID: "1", Version: 1, Event: mock.SomethingChangedV1{}},
+			{ID: "2", Version: 2, Event: mock.SomethingElseHappened{}},
+		}
+
+		// act
+		got, err := c.Upcast(envelopes)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.Envelope{
+			{ID: "1", Version: 1, Event: mock.SomethingHappened{}},
+			{ID: "1", Version: 1, Event: mock.SomethingChanged{Value: "next"}},
+			{ID: "2", Version: 2, Event: mock.SomethingElseHappened{}},
+		}, got)
+	})
+
+	t.Run("ItFailsIfTheUpcasterDoesNotUpgradeTheEvent", func(t *testing.T) {
+		// arrange
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, func(e domain.DomainEvent) ([]domain.DomainEvent, error) {
+			return []domain.DomainEvent{mock.SomethingHappened{}, mock.SomethingChangedV1{Text: "next"}}, nil
+		})
+
+		envelopes := []domain.Envelope{{ID: "1", Version: 1, Event: mock.SomethingChangedV1{}}}
+
+		// act
+		_, err := c.Upcast(envelopes)
+
+		// assert
+		assert.Equals(t, errors.New("upcaster for SomethingChanged event v1 didn't upgrade it"), err)
+	})
+
+	t.Run("ItAppliesTheUpcastersStepByStep", func(t *testing.T) {
+		// arrange
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, func(e domain.DomainEvent) ([]domain.DomainEvent, error) {
+			return []domain.DomainEvent{mock.SomethingHappened{}, mock.SomethingChanged{Value: "next"}}, nil
+		})
+		c.RegisterUpcaster("SomethingHappened", 1, func(e domain.DomainEvent) ([]domain.DomainEvent, error) {
+			return []domain.DomainEvent{mock.SomethingElseHappened{}}, nil
+		})
+
+		envelopes := []domain.Envelope{{ID: "1", Version: 1, Event: mock.SomethingChangedV1{}}}
+
+		// act
+		got, err := c.Upcast(envelopes)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.Envelope{
+			{ID: "1", Version: 1, Event: mock.SomethingElseHappened{}},
+			{ID: "1", Version: 1, Event: mock.SomethingChanged{Value: "next"}},
+		}, got)
+	})
+
+	t.Run("ItFailsIfTheUpcasterFails", func(t *testing.T) {
+		// arrange
+		c := upcaster.NewChain()
+		c.RegisterUpcaster("SomethingChanged", 1, func(e domain.DomainEvent) ([]domain.DomainEvent, error) {
+			return nil, errCannotUpcast
+		})
+
+		// act
+		_, err := c.Upcast(mock.Envelopes(mock.SomethingChangedV1{}))
+
+		// assert
+		assert.Equals(t, errCannotUpcast, err)
+	})
+}
+
+func upcastSomethingChanged(e domain.DomainEvent) ([]domain.DomainEvent, error) {
+	v1 := e.(mock.SomethingChangedV1)
+	return []domain.DomainEvent{mock.SomethingChanged{Value: v1.Text}}, nil
+}
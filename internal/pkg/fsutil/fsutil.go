@@ -0,0 +1,56 @@
+package fsutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ReplaceFile atomically replaces the file with the given data.
+//
+// The data is written to a temporary file next to the given one, which is then renamed over it.
+// If sync is set, the data and the rename are flushed to the disk before it returns,
+// so a crash leaves either the old or the new file.
+func ReplaceFile(path string, data []byte, sync bool) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if sync {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	if sync {
+		return SyncFile(filepath.Dir(path))
+	}
+	return nil
+}
+
+// SyncFile flushes the file or the directory at the given path to the disk.
+func SyncFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return f.Sync()
+}
@@ -0,0 +1,93 @@
+package fsutil_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/fsutil"
+)
+
+func TestReplaceFile(t *testing.T) {
+	t.Run("ItReplacesTheFileWithTheGivenData", func(t *testing.T) {
+		// arrange
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "data")
+		assert.Ok(t, ioutil.WriteFile(path, []byte("old"), 0644))
+
+		// act
+		err := fsutil.ReplaceFile(path, []byte("new"), true)
+
+		// assert
+		assert.Ok(t, err)
+
+		data, err := ioutil.ReadFile(path)
+		assert.Ok(t, err)
+		assert.Equals(t, "new", string(data))
+	})
+
+	t.Run("ItLeavesNoTemporaryFilesBehind", func(t *testing.T) {
+		// arrange
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		// act
+		err := fsutil.ReplaceFile(filepath.Join(dir, "data"), []byte("new"), false)
+
+		// assert
+		assert.Ok(t, err)
+
+		files, err := ioutil.ReadDir(dir)
+		assert.Ok(t, err)
+		assert.Equals(t, 1, len(files))
+		assert.Equals(t, "data", files[0].Name())
+	})
+
+	t.Run("ItFailsIfTheDirectoryDoesNotExist", func(t *testing.T) {
+		// arrange
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		// act
+		err := fsutil.ReplaceFile(filepath.Join(dir, "missing", "data"), []byte("new"), true)
+
+		// assert
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestSyncFile(t *testing.T) {
+	t.Run("ItSyncsTheDirectory", func(t *testing.T) {
+		// arrange
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		// act
+		err := fsutil.SyncFile(dir)
+
+		// assert
+		assert.Ok(t, err)
+	})
+
+	t.Run("ItFailsIfTheFileDoesNotExist", func(t *testing.T) {
+		// arrange
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+
+		// act
+		err := fsutil.SyncFile(filepath.Join(dir, "missing"))
+
+		// assert
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "fsutil")
+	assert.Ok(t, err)
+	return dir
+}
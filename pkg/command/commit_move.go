@@ -0,0 +1,38 @@
+package command
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+type CommitMove struct {
+	ID          string
+	GameID      domain.Identifier
+	PlayerEmail string
+	Commitment  string
+}
+
+func (c CommitMove) AggregateID() domain.Identifier {
+	return c.GameID
+}
+
+func (c CommitMove) AggregateType() string {
+	return "game.Aggregate"
+}
+
+func (c CommitMove) CommandType() string {
+	return "CommitMove"
+}
+
+func (c CommitMove) CommandID() string {
+	return c.ID
+}
+
+func (c CommitMove) Validate() error {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return err
+	}
+
+	if err := domain.ValidateEmail("PlayerEmail", c.PlayerEmail); err != nil {
+		return err
+	}
+
+	return domain.ValidateRequired("Commitment", c.Commitment)
+}
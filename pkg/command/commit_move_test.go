@@ -0,0 +1,47 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestCommitMoveAggregateID(t *testing.T) {
+	ID := ksuid.New()
+	assert.Equals(t, ID, command.CommitMove{GameID: ID}.AggregateID())
+}
+
+func TestCommitMoveAggregateType(t *testing.T) {
+	assert.Equals(t, "game.Aggregate", command.CommitMove{}.AggregateType())
+}
+
+func TestCommitMoveCommandType(t *testing.T) {
+	assert.Equals(t, "CommitMove", command.CommitMove{}.CommandType())
+}
+
+func TestCommitMoveCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.CommitMove{ID: "c1"}.CommandID())
+}
+
+func TestCommitMoveValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.CommitMove{GameID: ID, PlayerEmail: "tom@game.net", Commitment: "commitment"}.Validate())
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotGiven", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "PlayerEmail", Reason: "is required"},
+			command.CommitMove{GameID: ID, Commitment: "commitment"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheCommitmentIsNotGiven", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "Commitment", Reason: "is required"},
+			command.CommitMove{GameID: ID, PlayerEmail: "tom@game.net"}.Validate())
+	})
+}
@@ -3,8 +3,11 @@ package command
 import "github.com/screwyprof/roshambo/pkg/domain"
 
 type CreateNewGame struct {
-	GameID  domain.Identifier
-	Creator string
+	ID       string
+	GameID   domain.Identifier
+	Creator  string
+	RuleSet  string
+	MoveMode string
 }
 
 func (c CreateNewGame) AggregateID() domain.Identifier {
@@ -18,3 +21,15 @@ func (c CreateNewGame) AggregateType() string {
 func (c CreateNewGame) CommandType() string {
 	return "CreateNewGame"
 }
+
+func (c CreateNewGame) CommandID() string {
+	return c.ID
+}
+
+func (c CreateNewGame) Validate() error {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return err
+	}
+
+	return domain.ValidateEmail("Creator", c.Creator)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/screwyprof/roshambo/internal/pkg/assert"
 	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
 )
 
 func TestCreateNewGameAggregateID(t *testing.T) {
@@ -21,3 +22,25 @@ func TestCreateNewGameAggregateType(t *testing.T) {
 func TestCreateNewGameCommandType(t *testing.T) {
 	assert.Equals(t, "CreateNewGame", command.CreateNewGame{}.CommandType())
 }
+
+func TestCreateNewGameCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.CreateNewGame{ID: "c1"}.CommandID())
+}
+
+func TestCreateNewGameValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.CreateNewGame{GameID: ID, Creator: "tom@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheGameIDIsNotGiven", func(t *testing.T) {
+		assert.Equals(t, domain.ValidationError{Field: "GameID", Reason: "is required"},
+			command.CreateNewGame{Creator: "tom@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheCreatorIsNotAnEmail", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "Creator", Reason: "is not a valid email address"},
+			command.CreateNewGame{GameID: ID, Creator: "tom"}.Validate())
+	})
+}
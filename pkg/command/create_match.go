@@ -0,0 +1,47 @@
+package command
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+type CreateMatch struct {
+	ID           string
+	MatchID      domain.Identifier
+	FirstPlayer  string
+	SecondPlayer string
+	BestOf       int
+	RuleSet      string
+}
+
+func (c CreateMatch) AggregateID() domain.Identifier {
+	return c.MatchID
+}
+
+func (c CreateMatch) AggregateType() string {
+	return "match.Aggregate"
+}
+
+func (c CreateMatch) CommandType() string {
+	return "CreateMatch"
+}
+
+func (c CreateMatch) CommandID() string {
+	return c.ID
+}
+
+func (c CreateMatch) Validate() error {
+	if err := domain.ValidateIdentifier("MatchID", c.MatchID); err != nil {
+		return err
+	}
+
+	if err := domain.ValidateEmail("FirstPlayer", c.FirstPlayer); err != nil {
+		return err
+	}
+
+	if err := domain.ValidateEmail("SecondPlayer", c.SecondPlayer); err != nil {
+		return err
+	}
+
+	if c.FirstPlayer == c.SecondPlayer {
+		return domain.ValidationError{Field: "SecondPlayer", Reason: "must differ from the first player"}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestCreateMatchAggregateID(t *testing.T) {
+	ID := ksuid.New()
+	assert.Equals(t, ID, command.CreateMatch{MatchID: ID}.AggregateID())
+}
+
+func TestCreateMatchAggregateType(t *testing.T) {
+	assert.Equals(t, "match.Aggregate", command.CreateMatch{}.AggregateType())
+}
+
+func TestCreateMatchCommandType(t *testing.T) {
+	assert.Equals(t, "CreateMatch", command.CreateMatch{}.CommandType())
+}
+
+func TestCreateMatchCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.CreateMatch{ID: "c1"}.CommandID())
+}
+
+func TestCreateMatchValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.CreateMatch{MatchID: ID, FirstPlayer: "tom@game.net", SecondPlayer: "jerry@game.net", BestOf: 3}.Validate())
+	})
+
+	t.Run("ItFailsIfTheMatchIDIsNotGiven", func(t *testing.T) {
+		assert.Equals(t, domain.ValidationError{Field: "MatchID", Reason: "is required"},
+			command.CreateMatch{FirstPlayer: "tom@game.net", SecondPlayer: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotAnEmail", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "FirstPlayer", Reason: "is not a valid email address"},
+			command.CreateMatch{MatchID: ID, FirstPlayer: "tom", SecondPlayer: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfThePlayersAreTheSame", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "SecondPlayer", Reason: "must differ from the first player"},
+			command.CreateMatch{MatchID: ID, FirstPlayer: "tom@game.net", SecondPlayer: "tom@game.net"}.Validate())
+	})
+}
@@ -0,0 +1,33 @@
+package command
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+type DeclineInvitation struct {
+	ID          string
+	GameID      domain.Identifier
+	PlayerEmail string
+}
+
+func (c DeclineInvitation) AggregateID() domain.Identifier {
+	return c.GameID
+}
+
+func (c DeclineInvitation) AggregateType() string {
+	return "game.Aggregate"
+}
+
+func (c DeclineInvitation) CommandType() string {
+	return "DeclineInvitation"
+}
+
+func (c DeclineInvitation) CommandID() string {
+	return c.ID
+}
+
+func (c DeclineInvitation) Validate() error {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return err
+	}
+
+	return domain.ValidateEmail("PlayerEmail", c.PlayerEmail)
+}
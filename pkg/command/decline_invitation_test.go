@@ -0,0 +1,46 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestDeclineInvitationAggregateID(t *testing.T) {
+	ID := ksuid.New()
+	assert.Equals(t, ID, command.DeclineInvitation{GameID: ID}.AggregateID())
+}
+
+func TestDeclineInvitationAggregateType(t *testing.T) {
+	assert.Equals(t, "game.Aggregate", command.DeclineInvitation{}.AggregateType())
+}
+
+func TestDeclineInvitationCommandType(t *testing.T) {
+	assert.Equals(t, "DeclineInvitation", command.DeclineInvitation{}.CommandType())
+}
+
+func TestDeclineInvitationCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.DeclineInvitation{ID: "c1"}.CommandID())
+}
+
+func TestDeclineInvitationValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.DeclineInvitation{GameID: ID, PlayerEmail: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheGameIDIsNotGiven", func(t *testing.T) {
+		assert.Equals(t, domain.ValidationError{Field: "GameID", Reason: "is required"},
+			command.DeclineInvitation{PlayerEmail: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotGiven", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "PlayerEmail", Reason: "is required"},
+			command.DeclineInvitation{GameID: ID}.Validate())
+	})
+}
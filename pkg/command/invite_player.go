@@ -0,0 +1,38 @@
+package command
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+type InvitePlayer struct {
+	ID      string
+	GameID  domain.Identifier
+	Inviter string
+	Invitee string
+}
+
+func (c InvitePlayer) AggregateID() domain.Identifier {
+	return c.GameID
+}
+
+func (c InvitePlayer) AggregateType() string {
+	return "game.Aggregate"
+}
+
+func (c InvitePlayer) CommandType() string {
+	return "InvitePlayer"
+}
+
+func (c InvitePlayer) CommandID() string {
+	return c.ID
+}
+
+func (c InvitePlayer) Validate() error {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return err
+	}
+
+	if err := domain.ValidateEmail("Inviter", c.Inviter); err != nil {
+		return err
+	}
+
+	return domain.ValidateEmail("Invitee", c.Invitee)
+}
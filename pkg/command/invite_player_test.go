@@ -0,0 +1,52 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestInvitePlayerAggregateID(t *testing.T) {
+	ID := ksuid.New()
+	assert.Equals(t, ID, command.InvitePlayer{GameID: ID}.AggregateID())
+}
+
+func TestInvitePlayerAggregateType(t *testing.T) {
+	assert.Equals(t, "game.Aggregate", command.InvitePlayer{}.AggregateType())
+}
+
+func TestInvitePlayerCommandType(t *testing.T) {
+	assert.Equals(t, "InvitePlayer", command.InvitePlayer{}.CommandType())
+}
+
+func TestInvitePlayerCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.InvitePlayer{ID: "c1"}.CommandID())
+}
+
+func TestInvitePlayerValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.InvitePlayer{GameID: ID, Inviter: "tom@game.net", Invitee: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheGameIDIsNotGiven", func(t *testing.T) {
+		assert.Equals(t, domain.ValidationError{Field: "GameID", Reason: "is required"},
+			command.InvitePlayer{Inviter: "tom@game.net", Invitee: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheInviterIsNotGiven", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "Inviter", Reason: "is required"},
+			command.InvitePlayer{GameID: ID, Invitee: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheInviteeIsNotAnEmail", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "Invitee", Reason: "is not a valid email address"},
+			command.InvitePlayer{GameID: ID, Inviter: "tom@game.net", Invitee: "jerry"}.Validate())
+	})
+}
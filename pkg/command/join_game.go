@@ -0,0 +1,33 @@
+package command
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+type JoinGame struct {
+	ID          string
+	GameID      domain.Identifier
+	PlayerEmail string
+}
+
+func (c JoinGame) AggregateID() domain.Identifier {
+	return c.GameID
+}
+
+func (c JoinGame) AggregateType() string {
+	return "game.Aggregate"
+}
+
+func (c JoinGame) CommandType() string {
+	return "JoinGame"
+}
+
+func (c JoinGame) CommandID() string {
+	return c.ID
+}
+
+func (c JoinGame) Validate() error {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return err
+	}
+
+	return domain.ValidateEmail("PlayerEmail", c.PlayerEmail)
+}
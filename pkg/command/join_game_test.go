@@ -0,0 +1,46 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestJoinGameAggregateID(t *testing.T) {
+	ID := ksuid.New()
+	assert.Equals(t, ID, command.JoinGame{GameID: ID}.AggregateID())
+}
+
+func TestJoinGameAggregateType(t *testing.T) {
+	assert.Equals(t, "game.Aggregate", command.JoinGame{}.AggregateType())
+}
+
+func TestJoinGameCommandType(t *testing.T) {
+	assert.Equals(t, "JoinGame", command.JoinGame{}.CommandType())
+}
+
+func TestJoinGameCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.JoinGame{ID: "c1"}.CommandID())
+}
+
+func TestJoinGameValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.JoinGame{GameID: ID, PlayerEmail: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheGameIDIsNotGiven", func(t *testing.T) {
+		assert.Equals(t, domain.ValidationError{Field: "GameID", Reason: "is required"},
+			command.JoinGame{PlayerEmail: "jerry@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotAnEmail", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "PlayerEmail", Reason: "is not a valid email address"},
+			command.JoinGame{GameID: ID, PlayerEmail: "jerry"}.Validate())
+	})
+}
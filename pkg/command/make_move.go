@@ -3,6 +3,7 @@ package command
 import "github.com/screwyprof/roshambo/pkg/domain"
 
 type MakeMove struct {
+	ID          string
 	GameID      domain.Identifier
 	PlayerEmail string
 	Move        int
@@ -19,3 +20,15 @@ func (c MakeMove) AggregateType() string {
 func (c MakeMove) CommandType() string {
 	return "MakeMove"
 }
+
+func (c MakeMove) CommandID() string {
+	return c.ID
+}
+
+func (c MakeMove) Validate() error {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return err
+	}
+
+	return domain.ValidateEmail("PlayerEmail", c.PlayerEmail)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/screwyprof/roshambo/internal/pkg/assert"
 	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
 )
 
 func TestMakeMoveAggregateID(t *testing.T) {
@@ -21,3 +22,25 @@ func TestMakeMoveAggregateType(t *testing.T) {
 func TestMakeMoveCommandType(t *testing.T) {
 	assert.Equals(t, "MakeMove", command.MakeMove{}.CommandType())
 }
+
+func TestMakeMoveCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.MakeMove{ID: "c1"}.CommandID())
+}
+
+func TestMakeMoveValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.MakeMove{GameID: ID, PlayerEmail: "tom@game.net", Move: 1}.Validate())
+	})
+
+	t.Run("ItFailsIfTheGameIDIsNotGiven", func(t *testing.T) {
+		assert.Equals(t, domain.ValidationError{Field: "GameID", Reason: "is required"},
+			command.MakeMove{PlayerEmail: "tom@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotAnEmail", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "PlayerEmail", Reason: "is not a valid email address"},
+			command.MakeMove{GameID: ID, PlayerEmail: "Tom <tom@game.net>"}.Validate())
+	})
+}
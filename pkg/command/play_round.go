@@ -0,0 +1,34 @@
+package command
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+type PlayRound struct {
+	ID          string
+	MatchID     domain.Identifier
+	PlayerEmail string
+	Move        int
+}
+
+func (c PlayRound) AggregateID() domain.Identifier {
+	return c.MatchID
+}
+
+func (c PlayRound) AggregateType() string {
+	return "match.Aggregate"
+}
+
+func (c PlayRound) CommandType() string {
+	return "PlayRound"
+}
+
+func (c PlayRound) CommandID() string {
+	return c.ID
+}
+
+func (c PlayRound) Validate() error {
+	if err := domain.ValidateIdentifier("MatchID", c.MatchID); err != nil {
+		return err
+	}
+
+	return domain.ValidateEmail("PlayerEmail", c.PlayerEmail)
+}
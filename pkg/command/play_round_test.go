@@ -0,0 +1,46 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestPlayRoundAggregateID(t *testing.T) {
+	ID := ksuid.New()
+	assert.Equals(t, ID, command.PlayRound{MatchID: ID}.AggregateID())
+}
+
+func TestPlayRoundAggregateType(t *testing.T) {
+	assert.Equals(t, "match.Aggregate", command.PlayRound{}.AggregateType())
+}
+
+func TestPlayRoundCommandType(t *testing.T) {
+	assert.Equals(t, "PlayRound", command.PlayRound{}.CommandType())
+}
+
+func TestPlayRoundCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.PlayRound{ID: "c1"}.CommandID())
+}
+
+func TestPlayRoundValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.PlayRound{MatchID: ID, PlayerEmail: "tom@game.net", Move: 1}.Validate())
+	})
+
+	t.Run("ItFailsIfTheMatchIDIsNotGiven", func(t *testing.T) {
+		assert.Equals(t, domain.ValidationError{Field: "MatchID", Reason: "is required"},
+			command.PlayRound{PlayerEmail: "tom@game.net"}.Validate())
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotGiven", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "PlayerEmail", Reason: "is required"},
+			command.PlayRound{MatchID: ID}.Validate())
+	})
+}
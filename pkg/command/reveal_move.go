@@ -0,0 +1,39 @@
+package command
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+type RevealMove struct {
+	ID          string
+	GameID      domain.Identifier
+	PlayerEmail string
+	Move        int
+	Salt        string
+}
+
+func (c RevealMove) AggregateID() domain.Identifier {
+	return c.GameID
+}
+
+func (c RevealMove) AggregateType() string {
+	return "game.Aggregate"
+}
+
+func (c RevealMove) CommandType() string {
+	return "RevealMove"
+}
+
+func (c RevealMove) CommandID() string {
+	return c.ID
+}
+
+func (c RevealMove) Validate() error {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return err
+	}
+
+	if err := domain.ValidateEmail("PlayerEmail", c.PlayerEmail); err != nil {
+		return err
+	}
+
+	return domain.ValidateRequired("Salt", c.Salt)
+}
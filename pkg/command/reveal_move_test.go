@@ -0,0 +1,46 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+func TestRevealMoveAggregateID(t *testing.T) {
+	ID := ksuid.New()
+	assert.Equals(t, ID, command.RevealMove{GameID: ID}.AggregateID())
+}
+
+func TestRevealMoveAggregateType(t *testing.T) {
+	assert.Equals(t, "game.Aggregate", command.RevealMove{}.AggregateType())
+}
+
+func TestRevealMoveCommandType(t *testing.T) {
+	assert.Equals(t, "RevealMove", command.RevealMove{}.CommandType())
+}
+
+func TestRevealMoveCommandID(t *testing.T) {
+	assert.Equals(t, "c1", command.RevealMove{ID: "c1"}.CommandID())
+}
+
+func TestRevealMoveValidate(t *testing.T) {
+	t.Run("ItPassesAValidCommand", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Ok(t, command.RevealMove{GameID: ID, PlayerEmail: "tom@game.net", Move: 1, Salt: "salt"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheGameIDIsNotGiven", func(t *testing.T) {
+		assert.Equals(t, domain.ValidationError{Field: "GameID", Reason: "is required"},
+			command.RevealMove{PlayerEmail: "tom@game.net", Salt: "salt"}.Validate())
+	})
+
+	t.Run("ItFailsIfTheSaltIsNotGiven", func(t *testing.T) {
+		ID := ksuid.New()
+		assert.Equals(t, domain.ValidationError{Field: "Salt", Reason: "is required"},
+			command.RevealMove{GameID: ID, PlayerEmail: "tom@game.net"}.Validate())
+	})
+}
@@ -0,0 +1,226 @@
+package domain
+
+import "context"
+
+// ContextCommandHandler executes commands within the given context.
+type ContextCommandHandler interface {
+	HandleContext(ctx context.Context, c Command) ([]DomainEvent, error)
+}
+
+// ContextCommandHandlerFunc is a function that can be used as a context-aware command handler.
+type ContextCommandHandlerFunc func(context.Context, Command) ([]DomainEvent, error)
+
+// HandleContext implements ContextCommandHandler interface.
+func (f ContextCommandHandlerFunc) HandleContext(ctx context.Context, c Command) ([]DomainEvent, error) {
+	return f(ctx, c)
+}
+
+// ContextEventStore stores and loads events within the given context.
+type ContextEventStore interface {
+	LoadEventsForContext(ctx context.Context, aggregateID Identifier) ([]Envelope, error)
+	LoadEventsFromContext(ctx context.Context, aggregateID Identifier, version int) ([]Envelope, error)
+	StoreEventsForContext(ctx context.Context, aggregateID Identifier, version int, events []Envelope) error
+}
+
+// ContextAggregateStore loads and stores the aggregate within the given context.
+type ContextAggregateStore interface {
+	LoadContext(ctx context.Context, aggregateID Identifier, aggregateType string) (AdvancedAggregate, error)
+	StoreContext(ctx context.Context, aggregate AdvancedAggregate, events ...Envelope) error
+}
+
+// ContextEventPublisher publishes events within the given context.
+type ContextEventPublisher interface {
+	PublishContext(ctx context.Context, e ...Envelope) error
+}
+
+// ContextEventHandler handles events within the given context.
+type ContextEventHandler interface {
+	HandleContext(ctx context.Context, e Envelope) error
+}
+
+// ContextEventHandlerFunc is a function that can be used as a context-aware event handler.
+type ContextEventHandlerFunc func(context.Context, Envelope) error
+
+// ContextCommandHandlerOf returns the context-aware variant of the command handler.
+//
+// A handler which is not context-aware is only called if the context is not done yet.
+func ContextCommandHandlerOf(h CommandHandler) ContextCommandHandler {
+	if ch, ok := h.(ContextCommandHandler); ok {
+		return ch
+	}
+
+	return ContextCommandHandlerFunc(func(ctx context.Context, c Command) ([]DomainEvent, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return h.Handle(c)
+	})
+}
+
+// CommandHandlerOf returns the variant of the context-aware command handler which handles the commands
+// within the background context, e.g. to pass a chain of middlewares where a domain.CommandHandler is expected.
+//
+// The returned handler is still context-aware, so the callers which know the context keep passing it through.
+func CommandHandlerOf(h ContextCommandHandler) CommandHandler {
+	if ch, ok := h.(CommandHandler); ok {
+		return ch
+	}
+	return commandHandler{h}
+}
+
+// ContextEventStoreOf returns the context-aware variant of the event store.
+//
+// A store which is not context-aware is only called if the context is not done yet.
+func ContextEventStoreOf(s EventStore) ContextEventStore {
+	if cs, ok := s.(ContextEventStore); ok {
+		return cs
+	}
+	return contextEventStore{s}
+}
+
+// ContextAggregateStoreOf returns the context-aware variant of the aggregate store.
+//
+// A store which is not context-aware is only called if the context is not done yet.
+func ContextAggregateStoreOf(s AggregateStore) ContextAggregateStore {
+	if cs, ok := s.(ContextAggregateStore); ok {
+		return cs
+	}
+	return contextAggregateStore{s}
+}
+
+// ContextEventPublisherOf returns the context-aware variant of the event publisher.
+//
+// A publisher which is not context-aware is only called if the context is not done yet.
+func ContextEventPublisherOf(p EventPublisher) ContextEventPublisher {
+	if cp, ok := p.(ContextEventPublisher); ok {
+		return cp
+	}
+	return contextEventPublisher{p}
+}
+
+// ContextEventHandlerOf returns the context-aware variant of the event handler.
+//
+// A handler which is not context-aware is only called if the context is not done yet.
+func ContextEventHandlerOf(h EventHandler) ContextEventHandler {
+	if ch, ok := h.(ContextEventHandler); ok {
+		return ch
+	}
+	return contextEventHandler{h}
+}
+
+type commandHandler struct {
+	ContextCommandHandler
+}
+
+func (h commandHandler) Handle(c Command) ([]DomainEvent, error) {
+	return h.HandleContext(context.Background(), c)
+}
+
+type contextEventStore struct {
+	EventStore
+}
+
+func (s contextEventStore) LoadEventsForContext(ctx context.Context, aggregateID Identifier) ([]Envelope, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.LoadEventsFor(aggregateID)
+}
+
+func (s contextEventStore) LoadEventsFromContext(
+	ctx context.Context, aggregateID Identifier, version int) ([]Envelope, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.LoadEventsFrom(aggregateID, version)
+}
+
+func (s contextEventStore) StoreEventsForContext(
+	ctx context.Context, aggregateID Identifier, version int, events []Envelope) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.StoreEventsFor(aggregateID, version, events)
+}
+
+type contextAggregateStore struct {
+	AggregateStore
+}
+
+func (s contextAggregateStore) LoadContext(
+	ctx context.Context, aggregateID Identifier, aggregateType string) (AdvancedAggregate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Load(aggregateID, aggregateType)
+}
+
+func (s contextAggregateStore) StoreContext(ctx context.Context, aggregate AdvancedAggregate, events ...Envelope) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Store(aggregate, events...)
+}
+
+type contextEventPublisher struct {
+	EventPublisher
+}
+
+func (p contextEventPublisher) PublishContext(ctx context.Context, e ...Envelope) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.Publish(e...)
+}
+
+type contextEventHandler struct {
+	EventHandler
+}
+
+func (h contextEventHandler) HandleContext(ctx context.Context, e Envelope) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.Handle(e)
+}
+
+type contextKey int
+
+const (
+	principalKey contextKey = iota
+	correlationIDKey
+	causationIDKey
+)
+
+// WithPrincipal returns a copy of the context which carries the authenticated principal, e.g. the player's email.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFrom returns the authenticated principal carried by the context.
+func PrincipalFrom(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(principalKey).(string)
+	return principal, ok
+}
+
+// WithCorrelationID returns a copy of the context which carries the correlation ID.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, correlationID)
+}
+
+// CorrelationIDFrom returns the correlation ID carried by the context.
+func CorrelationIDFrom(ctx context.Context) (string, bool) {
+	correlationID, ok := ctx.Value(correlationIDKey).(string)
+	return correlationID, ok
+}
+
+// WithCausationID returns a copy of the context which carries the causation ID.
+func WithCausationID(ctx context.Context, causationID string) context.Context {
+	return context.WithValue(ctx, causationIDKey, causationID)
+}
+
+// CausationIDFrom returns the causation ID carried by the context.
+func CausationIDFrom(ctx context.Context) (string, bool) {
+	causationID, ok := ctx.Value(causationIDKey).(string)
+	return causationID, ok
+}
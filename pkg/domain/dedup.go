@@ -0,0 +1,24 @@
+package domain
+
+// IdentifiedCommand is a command which carries an ID assigned by the client.
+//
+// A command which is sent again with the same ID is not executed twice.
+type IdentifiedCommand interface {
+	CommandID() string
+}
+
+// CommandIDOf returns the ID of the given command, it is empty if the command doesn't carry one.
+func CommandIDOf(c Command) string {
+	if ic, ok := c.(IdentifiedCommand); ok {
+		return ic.CommandID()
+	}
+	return ""
+}
+
+// DedupStore remembers the events produced by the processed commands.
+//
+// LoadCommandResult returns false if the command hasn't been processed yet.
+type DedupStore interface {
+	LoadCommandResult(commandID string) ([]DomainEvent, bool, error)
+	StoreCommandResult(commandID string, events []DomainEvent) error
+}
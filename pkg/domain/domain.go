@@ -1,6 +1,9 @@
 package domain
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // Identifier an object identifier.
 type Identifier interface {
@@ -30,6 +33,11 @@ type CommandHandler interface {
 // CommandHandlerFunc is a function that can be used as a command handler.
 type CommandHandlerFunc func(Command) ([]DomainEvent, error)
 
+// Handle implements CommandHandler interface.
+func (f CommandHandlerFunc) Handle(c Command) ([]DomainEvent, error) {
+	return f(c)
+}
+
 // DomainEvent represents something that took place in the domain.
 //
 // Events are always named with a past-participle verb, such as OrderConfirmed.
@@ -72,10 +80,49 @@ type AdvancedAggregate interface {
 	EventApplier
 }
 
+// Rehydrator is an aggregate which is rehydrated from the stored envelopes.
+//
+// Its version follows the stream versions of the envelopes,
+// so an event which has been split into several ones by an upcaster is counted once.
+type Rehydrator interface {
+	Rehydrate(envelopes ...Envelope) error
+}
+
+// Committer is an aggregate which can be told that the events produced by the handled commands have been stored.
+//
+// Once committed, the events are counted in its version, so that it can handle more commands.
+type Committer interface {
+	MarkCommitted()
+}
+
+// ErrConcurrencyViolation happens if aggregate has been modified concurrently.
+var ErrConcurrencyViolation = errors.New("concurrency error: aggregate versions differ")
+
 // EventStore stores and loads events.
+//
+// LoadEventsFrom loads the events which follow the given version.
+// StoreEventsFor assigns the stream versions and the global positions to the given envelopes,
+// it returns ErrConcurrencyViolation if the stream has been changed since the given version.
 type EventStore interface {
-	LoadEventsFor(aggregateID Identifier) ([]DomainEvent, error)
-	StoreEventsFor(aggregateID Identifier, version int, events []DomainEvent) error
+	LoadEventsFor(aggregateID Identifier) ([]Envelope, error)
+	LoadEventsFrom(aggregateID Identifier, version int) ([]Envelope, error)
+	StoreEventsFor(aggregateID Identifier, version int, events []Envelope) error
+}
+
+// EventLog reads the global stream of all the stored events ordered by their positions.
+//
+// LoadAllEventsFrom loads at most limit events which follow the given position, a non-positive limit means no limit.
+type EventLog interface {
+	LoadAllEventsFrom(position int64, limit int) ([]Envelope, error)
+}
+
+// Outbox keeps track of the stored events which have not been published yet.
+//
+// The events become pending publication in the same step they are stored.
+// MarkPublished marks all the events up to the given global position as published.
+type Outbox interface {
+	PendingEvents(limit int) ([]Envelope, error)
+	MarkPublished(position int64) error
 }
 
 // FactoryFn aggregate factory function.
@@ -89,20 +136,28 @@ type AggregateFactory interface {
 
 // EventPublisher publishes events.
 type EventPublisher interface {
-	Publish(e ...DomainEvent) error
+	Publish(e ...Envelope) error
 }
 
 // EventHandler handles events that were published though EventPublisher.
 type EventHandler interface {
 	SubscribedTo() EventMatcher
-	Handle(DomainEvent) error
+	Handle(Envelope) error
 }
 
 // EventHandlerFunc is a function that can be used as an event handler.
-type EventHandlerFunc func(DomainEvent) error
+type EventHandlerFunc func(Envelope) error
 
 // AggregateStore loads and stores the aggregate.
 type AggregateStore interface {
 	Load(aggregateID Identifier, aggregateType string) (AdvancedAggregate, error)
-	Store(aggregate AdvancedAggregate, events ...DomainEvent) error
+	Store(aggregate AdvancedAggregate, events ...Envelope) error
+}
+
+// EventSerializer marshals and unmarshals events so that they can be persisted or sent over the wire.
+//
+// UnmarshalEvent restores the event of the given schema version.
+type EventSerializer interface {
+	MarshalEvent(e DomainEvent) ([]byte, error)
+	UnmarshalEvent(eventType string, schemaVersion int, data []byte) (DomainEvent, error)
 }
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Envelope wraps a domain event with its metadata.
+type Envelope struct {
+	// ID uniquely identifies the event.
+	ID string
+
+	AggregateID   string
+	AggregateType string
+
+	// Version is the position of the event in the aggregate stream starting from 1.
+	Version int
+	// Position is the position of the event in the global stream of all events starting from 1.
+	Position int64
+
+	RecordedAt time.Time
+
+	// CommandID identifies the command which produced the event.
+	CommandID string
+	// CausationID identifies the message which caused the event.
+	CausationID string
+	// CorrelationID identifies the whole conversation the event belongs to.
+	CorrelationID string
+
+	Metadata map[string]string
+
+	Event DomainEvent
+}
+
+// EventsOf unwraps the events from the given envelopes.
+func EventsOf(envelopes []Envelope) []DomainEvent {
+	if envelopes == nil {
+		return nil
+	}
+
+	events := make([]DomainEvent, 0, len(envelopes))
+	for _, e := range envelopes {
+		events = append(events, e.Event)
+	}
+	return events
+}
@@ -16,20 +16,57 @@ const (
 	waiting
 	tied
 	won
+	started
+	committing
+	revealing
 )
 
 var (
 	ErrGameIsAlreadyStarted            = errors.New("game is already started")
 	ErrPlayerIsTheSame                 = errors.New("the player is already in the game")
 	ErrTheGameHaveNotStartedOrFinished = errors.New("the game haven't started or finished")
+	ErrInvalidSnapshot                 = errors.New("invalid game snapshot")
+	ErrMoveIsNotInTheRuleSet           = domain.ValidationError{Field: "Move", Reason: "is not in the rule set"}
+	ErrPlayerIsNotAParticipant         = errors.New("the player is not a participant of the game")
+	ErrOnlyTheCreatorCanInvite         = errors.New("only the creator can invite players")
+	ErrPlayerIsNotInvited              = errors.New("the player is not invited")
+	ErrGameIsFull                      = errors.New("the game already has two players")
+	ErrMoveIsAlreadyCommitted          = errors.New("the player has already committed their move")
+	ErrNotAllMovesAreCommitted         = errors.New("the opponent hasn't committed their move yet")
+	ErrMoveIsAlreadyRevealed           = errors.New("the player has already revealed their move")
+	ErrMovesMustBeCommitted            = errors.New("the moves must be committed in this game")
+	ErrMovesMustBeOpen                 = errors.New("the moves must be made openly in this game")
 )
 
+// Snapshot is an exported state of the game.
+type Snapshot struct {
+	State       int
+	PlayerEmail string
+	Move        int
+	RuleSet     string
+	MoveMode    string
+	Creator     string
+	Opponent    string
+	Invitee     string
+	Commitments map[string]string
+	Revealed    map[string]int
+}
+
 type Aggregate struct {
 	id domain.Identifier
 
 	state       state
 	playerEmail string
 	move        Move
+	ruleSet     *RuleSet
+	moveMode    MoveMode
+
+	creator  string
+	opponent string
+	invitee  string
+
+	commitments map[string]string
+	revealed    map[string]Move
 }
 
 // NewAggregate creates a new instance of Aggregate.
@@ -37,7 +74,14 @@ func NewAggregate(ID domain.Identifier) *Aggregate {
 	if ID == nil {
 		panic("ID is required")
 	}
-	return &Aggregate{id: ID, state: notCreated}
+	return &Aggregate{
+		id:          ID,
+		state:       notCreated,
+		ruleSet:     Classic,
+		moveMode:    OpenMoves,
+		commitments: make(map[string]string),
+		revealed:    make(map[string]Move),
+	}
 }
 
 // AggregateID implements domain.Aggregate interface.
@@ -50,39 +94,337 @@ func (a *Aggregate) AggregateType() string {
 	return "game.Aggregate"
 }
 
-// CreateNewGame starts a new game.
+// CreateNewGame starts a new game played with the given rule set, the classic one is used by default.
+// The game is played either with open or with committed moves, the open ones are used by default.
 // If the game has already started then returns an error.
+//
+// It returns domain.ValidationError if the game ID, the creator's email, the rule set or the move mode is invalid.
 func (a *Aggregate) CreateNewGame(c command.CreateNewGame) ([]domain.DomainEvent, error) {
 	if a.state != notCreated {
 		return nil, ErrGameIsAlreadyStarted
 	}
-	return []domain.DomainEvent{event.GameCreated{GameID: c.GameID.String(), Creator: c.Creator}}, nil
+
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateEmail("Creator", c.Creator); err != nil {
+		return nil, err
+	}
+
+	ruleSet, err := ValidateRuleSet(c.RuleSet)
+	if err != nil {
+		return nil, err
+	}
+
+	moveMode, err := validateMoveMode(c.MoveMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return []domain.DomainEvent{
+		event.GameCreated{
+			GameID:   c.GameID.String(),
+			Creator:  c.Creator,
+			RuleSet:  ruleSet.Name(),
+			MoveMode: string(moveMode),
+		},
+	}, nil
+}
+
+// InvitePlayer invites a player to join the game, the previous invitation is replaced.
+//
+// It returns ErrTheGameHaveNotStartedOrFinished if the game haven't been created yet.
+// It returns ErrGameIsFull if a player has already joined the game.
+// It returns domain.ValidationError if the game ID or the invitee's email is invalid.
+// It returns ErrOnlyTheCreatorCanInvite if the inviter is not the creator.
+// It returns ErrPlayerIsTheSame if the creator invites themselves.
+func (a *Aggregate) InvitePlayer(c command.InvitePlayer) ([]domain.DomainEvent, error) {
+	if err := a.ensureIsOpen(c.GameID); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateEmail("Invitee", c.Invitee); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c.Inviter != a.creator:
+		return nil, ErrOnlyTheCreatorCanInvite
+	case c.Invitee == a.creator:
+		return nil, ErrPlayerIsTheSame
+	default:
+		return []domain.DomainEvent{event.PlayerInvited{GameID: c.GameID.String(), Invitee: c.Invitee}}, nil
+	}
+}
+
+// JoinGame makes the player the creator's opponent.
+//
+// Anyone can join a game nobody is invited to, otherwise only the invited player can.
+//
+// It returns ErrTheGameHaveNotStartedOrFinished if the game haven't been created yet.
+// It returns ErrGameIsFull if a player has already joined the game.
+// It returns domain.ValidationError if the game ID or the player's email is invalid.
+// It returns ErrPlayerIsTheSame if the creator joins their own game.
+// It returns ErrPlayerIsNotInvited if another player is invited.
+func (a *Aggregate) JoinGame(c command.JoinGame) ([]domain.DomainEvent, error) {
+	if err := a.ensureIsOpen(c.GameID); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateEmail("PlayerEmail", c.PlayerEmail); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c.PlayerEmail == a.creator:
+		return nil, ErrPlayerIsTheSame
+	case a.invitee != "" && c.PlayerEmail != a.invitee:
+		return nil, ErrPlayerIsNotInvited
+	default:
+		return []domain.DomainEvent{event.GameJoined{GameID: c.GameID.String(), PlayerEmail: c.PlayerEmail}}, nil
+	}
+}
+
+// DeclineInvitation declines the invitation, so that the creator can invite someone else.
+//
+// It returns ErrTheGameHaveNotStartedOrFinished if the game haven't been created yet.
+// It returns ErrGameIsFull if a player has already joined the game.
+// It returns domain.ValidationError if the game ID or the player's email is invalid.
+// It returns ErrPlayerIsNotInvited if the player is not invited.
+func (a *Aggregate) DeclineInvitation(c command.DeclineInvitation) ([]domain.DomainEvent, error) {
+	if err := a.ensureIsOpen(c.GameID); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateEmail("PlayerEmail", c.PlayerEmail); err != nil {
+		return nil, err
+	}
+
+	if c.PlayerEmail != a.invitee {
+		return nil, ErrPlayerIsNotInvited
+	}
+	return []domain.DomainEvent{event.InvitationDeclined{GameID: c.GameID.String(), Invitee: c.PlayerEmail}}, nil
 }
 
-// MakeMove makes a move.
+// MakeMove makes an open move.
 //
+// The game is started once the opponent has joined it, only the creator and the opponent can move.
 // When the second player has moved, the game is finished with a tie or a win.
 //
+// It returns ErrMovesMustBeCommitted if the game is played with committed moves, see CommitMove.
 // It returns ErrTheGameHaveNotStartedOrFinished if the game haven't started yet.
+// It returns domain.ValidationError if the game ID or the player's email is invalid.
+// It returns ErrPlayerIsNotAParticipant if the player is neither the creator nor the opponent.
 // It returns ErrPlayerIsTheSame if the player is the same.
+// It returns ErrMoveIsNotInTheRuleSet if the move cannot be made in the game.
 func (a *Aggregate) MakeMove(c command.MakeMove) ([]domain.DomainEvent, error) {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateEmail("PlayerEmail", c.PlayerEmail); err != nil {
+		return nil, err
+	}
+
 	switch {
+	case a.moveMode != OpenMoves:
+		return nil, ErrMovesMustBeCommitted
+	case a.state != started && a.state != waiting:
+		return nil, ErrTheGameHaveNotStartedOrFinished
+	case c.PlayerEmail != a.creator && c.PlayerEmail != a.opponent:
+		return nil, ErrPlayerIsNotAParticipant
 	case a.playerEmail == c.PlayerEmail:
 		return nil, ErrPlayerIsTheSame
-	case a.state == created:
+	case !a.ruleSet.Contains(NewMove(c.Move)):
+		return nil, ErrMoveIsNotInTheRuleSet
+	case a.state == started:
 		return []domain.DomainEvent{event.MoveDecided{GameID: c.GameID.String(), PlayerEmail: c.PlayerEmail, Move: c.Move}}, nil
-	case a.state == waiting:
+	default:
 		return []domain.DomainEvent{
 			event.MoveDecided{GameID: c.GameID.String(), PlayerEmail: c.PlayerEmail, Move: c.Move},
-			a.finish(c.GameID.String(), c.PlayerEmail, NewMove(c.Move)),
+			a.finish(c.GameID.String(), a.playerEmail, a.move, c.PlayerEmail, NewMove(c.Move)),
 		}, nil
+	}
+}
+
+// CommitMove commits the player to a move without disclosing it, see Commit.
+//
+// Once both players have committed their moves, they reveal them with RevealMove.
+//
+// It returns ErrMovesMustBeOpen if the game is played with open moves, see MakeMove.
+// It returns ErrTheGameHaveNotStartedOrFinished if the game haven't started yet.
+// It returns domain.ValidationError if the game ID, the player's email or the commitment is invalid.
+// It returns ErrPlayerIsNotAParticipant if the player is neither the creator nor the opponent.
+// It returns ErrMoveIsAlreadyCommitted if the player has already committed their move.
+func (a *Aggregate) CommitMove(c command.CommitMove) ([]domain.DomainEvent, error) {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateEmail("PlayerEmail", c.PlayerEmail); err != nil {
+		return nil, err
+	}
+
+	if err := validateCommitment(c.Commitment); err != nil {
+		return nil, err
+	}
+
+	_, committed := a.commitments[c.PlayerEmail]
+
+	switch {
+	case a.moveMode != CommittedMoves:
+		return nil, ErrMovesMustBeOpen
+	case a.state != started && a.state != committing:
+		return nil, ErrTheGameHaveNotStartedOrFinished
+	case c.PlayerEmail != a.creator && c.PlayerEmail != a.opponent:
+		return nil, ErrPlayerIsNotAParticipant
+	case committed:
+		return nil, ErrMoveIsAlreadyCommitted
 	default:
+		return []domain.DomainEvent{
+			event.MoveCommitted{GameID: c.GameID.String(), PlayerEmail: c.PlayerEmail, Commitment: c.Commitment},
+		}, nil
+	}
+}
+
+// RevealMove discloses the committed move.
+//
+// When the second player has revealed their move, the game is finished with a tie or a win.
+// A player whose move doesn't match their commitment or is not in the rule set is penalized by losing the game.
+//
+// It returns ErrMovesMustBeOpen if the game is played with open moves, see MakeMove.
+// It returns ErrTheGameHaveNotStartedOrFinished if nobody has committed their move yet or the game is finished.
+// It returns domain.ValidationError if the game ID, the player's email or the salt is invalid.
+// It returns ErrPlayerIsNotAParticipant if the player is neither the creator nor the opponent.
+// It returns ErrNotAllMovesAreCommitted if the opponent hasn't committed their move yet.
+// It returns ErrMoveIsAlreadyRevealed if the player has already revealed their move.
+func (a *Aggregate) RevealMove(c command.RevealMove) ([]domain.DomainEvent, error) {
+	if err := domain.ValidateIdentifier("GameID", c.GameID); err != nil {
+		return nil, err
+	}
+
+	if err := domain.ValidateEmail("PlayerEmail", c.PlayerEmail); err != nil {
+		return nil, err
+	}
+
+	if err := validateSalt(c.Salt); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case a.moveMode != CommittedMoves:
+		return nil, ErrMovesMustBeOpen
+	case a.state != committing && a.state != revealing:
 		return nil, ErrTheGameHaveNotStartedOrFinished
+	case c.PlayerEmail != a.creator && c.PlayerEmail != a.opponent:
+		return nil, ErrPlayerIsNotAParticipant
+	case a.state == committing:
+		return nil, ErrNotAllMovesAreCommitted
+	}
+
+	if _, ok := a.revealed[c.PlayerEmail]; ok {
+		return nil, ErrMoveIsAlreadyRevealed
+	}
+
+	gameID := c.GameID.String()
+	opponent := a.opponentOf(c.PlayerEmail)
+	move := NewMove(c.Move)
+
+	if reason, ok := a.verifyReveal(c.PlayerEmail, move, c.Salt); !ok {
+		return []domain.DomainEvent{
+			event.PlayerPenalized{GameID: gameID, PlayerEmail: c.PlayerEmail, Reason: reason},
+			event.GameWon{GameID: gameID, Winner: opponent, Loser: c.PlayerEmail},
+		}, nil
+	}
+
+	events := []domain.DomainEvent{event.MoveRevealed{GameID: gameID, PlayerEmail: c.PlayerEmail, Move: c.Move}}
+
+	opponentMove, ok := a.revealed[opponent]
+	if !ok {
+		return events, nil
+	}
+	return append(events, a.finish(gameID, opponent, opponentMove, c.PlayerEmail, move)), nil
+}
+
+// SnapshotState implements domain.Snapshotter interface.
+func (a *Aggregate) SnapshotState() (interface{}, error) {
+	return Snapshot{
+		State:       int(a.state),
+		PlayerEmail: a.playerEmail,
+		Move:        int(a.move),
+		RuleSet:     a.ruleSet.Name(),
+		MoveMode:    string(a.moveMode),
+		Creator:     a.creator,
+		Opponent:    a.opponent,
+		Invitee:     a.invitee,
+		Commitments: a.snapshotCommitments(),
+		Revealed:    a.snapshotRevealed(),
+	}, nil
+}
+
+// RestoreState implements domain.Snapshotter interface.
+func (a *Aggregate) RestoreState(s interface{}) error {
+	var snapshot Snapshot
+	switch v := s.(type) {
+	case Snapshot:
+		snapshot = v
+	case *Snapshot:
+		snapshot = *v
+	default:
+		return ErrInvalidSnapshot
+	}
+
+	ruleSet, err := RuleSetOf(snapshot.RuleSet)
+	if err != nil {
+		return err
+	}
+
+	moveMode, err := MoveModeOf(snapshot.MoveMode)
+	if err != nil {
+		return err
+	}
+
+	a.state = state(snapshot.State)
+	a.ruleSet = ruleSet
+	a.moveMode = moveMode
+	a.playerEmail = snapshot.PlayerEmail
+	a.move = Move(snapshot.Move)
+	a.creator = snapshot.Creator
+	a.opponent = snapshot.Opponent
+	a.invitee = snapshot.Invitee
+
+	a.commitments = make(map[string]string, len(snapshot.Commitments))
+	for playerEmail, commitment := range snapshot.Commitments {
+		a.commitments[playerEmail] = commitment
+	}
+
+	a.revealed = make(map[string]Move, len(snapshot.Revealed))
+	for playerEmail, move := range snapshot.Revealed {
+		a.revealed[playerEmail] = Move(move)
 	}
+	return nil
 }
 
 func (a *Aggregate) OnGameCreated(e event.GameCreated) {
 	a.state = created
+	a.creator = e.Creator
+	a.ruleSet = MustRuleSetOf(e.RuleSet)
+	a.moveMode = mustMoveModeOf(e.MoveMode)
+}
+
+func (a *Aggregate) OnPlayerInvited(e event.PlayerInvited) {
+	a.invitee = e.Invitee
+}
+
+func (a *Aggregate) OnInvitationDeclined(e event.InvitationDeclined) {
+	a.invitee = ""
+}
+
+func (a *Aggregate) OnGameJoined(e event.GameJoined) {
+	a.opponent = e.PlayerEmail
+	a.invitee = ""
+	a.state = started
 }
 
 func (a *Aggregate) OnMoveDecided(e event.MoveDecided) {
@@ -91,6 +433,23 @@ func (a *Aggregate) OnMoveDecided(e event.MoveDecided) {
 	a.state = waiting
 }
 
+func (a *Aggregate) OnMoveCommitted(e event.MoveCommitted) {
+	a.commitments[e.PlayerEmail] = e.Commitment
+
+	a.state = committing
+	if len(a.commitments) == 2 {
+		a.state = revealing
+	}
+}
+
+func (a *Aggregate) OnMoveRevealed(e event.MoveRevealed) {
+	a.revealed[e.PlayerEmail] = Move(e.Move)
+}
+
+func (a *Aggregate) OnPlayerPenalized(e event.PlayerPenalized) {
+	// the game is won by the opponent.
+}
+
 func (a *Aggregate) OnGameWon(e event.GameWon) {
 	a.state = won
 }
@@ -99,12 +458,64 @@ func (a *Aggregate) OnGameTied(e event.GameTied) {
 	a.state = tied
 }
 
-func (a *Aggregate) finish(gameID string, opponentEmail string, opponentMove Move) domain.DomainEvent {
+// ensureIsOpen checks that the game has been created and waits for the opponent.
+func (a *Aggregate) ensureIsOpen(gameID domain.Identifier) error {
+	if err := domain.ValidateIdentifier("GameID", gameID); err != nil {
+		return err
+	}
+
+	switch a.state {
+	case notCreated:
+		return ErrTheGameHaveNotStartedOrFinished
+	case created:
+		return nil
+	default:
+		return ErrGameIsFull
+	}
+}
+
+func (a *Aggregate) opponentOf(playerEmail string) string {
+	if playerEmail == a.creator {
+		return a.opponent
+	}
+	return a.creator
+}
+
+// verifyReveal checks that the revealed move is the committed one and can be made in the game.
+func (a *Aggregate) verifyReveal(playerEmail string, move Move, salt string) (string, bool) {
+	switch {
+	case Commit(move, salt) != a.commitments[playerEmail]:
+		return "the revealed move doesn't match the commitment", false
+	case !a.ruleSet.Contains(move):
+		return "the revealed move is not in the rule set", false
+	default:
+		return "", true
+	}
+}
+
+func (a *Aggregate) snapshotCommitments() map[string]string {
+	commitments := make(map[string]string, len(a.commitments))
+	for playerEmail, commitment := range a.commitments {
+		commitments[playerEmail] = commitment
+	}
+	return commitments
+}
+
+func (a *Aggregate) snapshotRevealed() map[string]int {
+	revealed := make(map[string]int, len(a.revealed))
+	for playerEmail, move := range a.revealed {
+		revealed[playerEmail] = int(move)
+	}
+	return revealed
+}
+
+func (a *Aggregate) finish(
+	gameID string, playerEmail string, move Move, opponentEmail string, opponentMove Move) domain.DomainEvent {
 	switch {
-	case a.move.defeats(opponentMove):
-		return event.GameWon{GameID: gameID, Winner: a.playerEmail, Loser: opponentEmail}
-	case opponentMove.defeats(a.move):
-		return event.GameWon{GameID: gameID, Winner: opponentEmail, Loser: a.playerEmail}
+	case a.ruleSet.Beats(move, opponentMove):
+		return event.GameWon{GameID: gameID, Winner: playerEmail, Loser: opponentEmail}
+	case a.ruleSet.Beats(opponentMove, move):
+		return event.GameWon{GameID: gameID, Winner: opponentEmail, Loser: playerEmail}
 	default:
 		return event.GameTied{GameID: gameID}
 	}
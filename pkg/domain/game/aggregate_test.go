@@ -16,6 +16,13 @@ import (
 	"github.com/screwyprof/roshambo/pkg/event"
 )
 
+// the salts of the players' commitments.
+const (
+	salt1 = "player1's secret salt"
+	salt2 = "player2's secret salt"
+	salt3 = "player3's secret salt"
+)
+
 // ensure that game aggregate implements domain.Aggregate interface.
 var _ domain.Aggregate = (*game.Aggregate)(nil)
 
@@ -52,27 +59,270 @@ func TestAggregate_CreateNewGame(t *testing.T) {
 		Test(t)(
 			Given(createTestAggregate()),
 			When(command.CreateNewGame{GameID: ID, Creator: "tiger@happy.com"}),
-			Then(event.GameCreated{GameID: ID.String(), Creator: "tiger@happy.com"}),
+			Then(event.GameCreated{GameID: ID.String(), Creator: "tiger@happy.com", RuleSet: "classic", MoveMode: "open"}),
 		)
 	})
 
 	t.Run("ItCannotStartANewGameIfItTheGameIsAlreadyStarted", func(t *testing.T) {
 		ID := mock.StringIdentifier("g777")
 		Test(t)(
-			Given(createTestAggregate(), event.GameCreated{GameID: ID.String()}),
+			Given(createTestAggregate(), gameCreated(ID, game.OpenMoves)),
 			When(command.CreateNewGame{GameID: ID}),
 			ThenFailWith(game.ErrGameIsAlreadyStarted),
 		)
 	})
+
+	t.Run("ItCreatesNewGameWithTheGivenRuleSet", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateNewGame{GameID: ID, Creator: "tiger@happy.com", RuleSet: "rpsls"}),
+			Then(event.GameCreated{GameID: ID.String(), Creator: "tiger@happy.com", RuleSet: "rpsls", MoveMode: "open"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheRuleSetIsUnknown", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateNewGame{GameID: ID, Creator: "tiger@happy.com", RuleSet: "chess"}),
+			ThenFailWith(domain.ValidationError{Field: "RuleSet", Reason: "is unknown"}),
+		)
+	})
+
+	t.Run("ItCreatesNewGameWithTheGivenMoveMode", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateNewGame{GameID: ID, Creator: "tiger@happy.com", MoveMode: "commit-reveal"}),
+			Then(event.GameCreated{
+				GameID: ID.String(), Creator: "tiger@happy.com", RuleSet: "classic", MoveMode: "commit-reveal"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheMoveModeIsUnknown", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateNewGame{GameID: ID, Creator: "tiger@happy.com", MoveMode: "blind"}),
+			ThenFailWith(domain.ValidationError{Field: "MoveMode", Reason: "is unknown"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameIDIsEmpty", func(t *testing.T) {
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateNewGame{GameID: mock.StringIdentifier(""), Creator: "tiger@happy.com"}),
+			ThenFailWith(domain.ValidationError{Field: "GameID", Reason: "is required"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheCreatorIsNotGiven", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateNewGame{GameID: ID}),
+			ThenFailWith(domain.ValidationError{Field: "Creator", Reason: "is required"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheCreatorIsNotAnEmail", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateNewGame{GameID: ID, Creator: "Tiger <tiger@happy.com>"}),
+			ThenFailWith(domain.ValidationError{Field: "Creator", Reason: "is not a valid email address"}),
+		)
+	})
+}
+
+func TestAggregateInvitePlayer(t *testing.T) {
+	t.Run("TheCreatorCanInviteAPlayer", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), gameCreated(ID, game.OpenMoves)),
+			When(command.InvitePlayer{GameID: ID, Inviter: "player1@game.com", Invitee: "player2@game.com"}),
+			Then(event.PlayerInvited{GameID: ID.String(), Invitee: "player2@game.com"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameHaveNotBeenCreated", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.InvitePlayer{GameID: ID, Inviter: "player1@game.com", Invitee: "player2@game.com"}),
+			ThenFailWith(game.ErrTheGameHaveNotStartedOrFinished),
+		)
+	})
+
+	t.Run("ItFailsIfTheInviterIsNotTheCreator", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), gameCreated(ID, game.OpenMoves)),
+			When(command.InvitePlayer{GameID: ID, Inviter: "player2@game.com", Invitee: "player3@game.com"}),
+			ThenFailWith(game.ErrOnlyTheCreatorCanInvite),
+		)
+	})
+
+	t.Run("ItFailsIfTheCreatorInvitesThemselves", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), gameCreated(ID, game.OpenMoves)),
+			When(command.InvitePlayer{GameID: ID, Inviter: "player1@game.com", Invitee: "player1@game.com"}),
+			ThenFailWith(game.ErrPlayerIsTheSame),
+		)
+	})
+
+	t.Run("ItFailsIfTheInviteeIsNotAnEmail", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), gameCreated(ID, game.OpenMoves)),
+			When(command.InvitePlayer{GameID: ID, Inviter: "player1@game.com", Invitee: "player2"}),
+			ThenFailWith(domain.ValidationError{Field: "Invitee", Reason: "is not a valid email address"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameIsFull", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.InvitePlayer{GameID: ID, Inviter: "player1@game.com", Invitee: "player3@game.com"}),
+			ThenFailWith(game.ErrGameIsFull),
+		)
+	})
+}
+
+func TestAggregateJoinGame(t *testing.T) {
+	t.Run("APlayerCanJoinAGameNobodyIsInvitedTo", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), gameCreated(ID, game.OpenMoves)),
+			When(command.JoinGame{GameID: ID, PlayerEmail: "player2@game.com"}),
+			Then(event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+		)
+	})
+
+	t.Run("TheInvitedPlayerCanJoinTheGame", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.PlayerInvited{GameID: ID.String(), Invitee: "player2@game.com"}),
+			When(command.JoinGame{GameID: ID, PlayerEmail: "player2@game.com"}),
+			Then(event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+		)
+	})
+
+	t.Run("ItFailsIfAnotherPlayerIsInvited", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.PlayerInvited{GameID: ID.String(), Invitee: "player2@game.com"}),
+			When(command.JoinGame{GameID: ID, PlayerEmail: "player3@game.com"}),
+			ThenFailWith(game.ErrPlayerIsNotInvited),
+		)
+	})
+
+	t.Run("ItFailsIfTheCreatorJoinsTheirOwnGame", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), gameCreated(ID, game.OpenMoves)),
+			When(command.JoinGame{GameID: ID, PlayerEmail: "player1@game.com"}),
+			ThenFailWith(game.ErrPlayerIsTheSame),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameIsFull", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.JoinGame{GameID: ID, PlayerEmail: "player3@game.com"}),
+			ThenFailWith(game.ErrGameIsFull),
+		)
+	})
+}
+
+func TestAggregateDeclineInvitation(t *testing.T) {
+	t.Run("TheInvitedPlayerCanDeclineTheInvitation", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.PlayerInvited{GameID: ID.String(), Invitee: "player2@game.com"}),
+			When(command.DeclineInvitation{GameID: ID, PlayerEmail: "player2@game.com"}),
+			Then(event.InvitationDeclined{GameID: ID.String(), Invitee: "player2@game.com"}),
+		)
+	})
+
+	t.Run("AnotherPlayerCanJoinOnceTheInvitationIsDeclined", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.PlayerInvited{GameID: ID.String(), Invitee: "player2@game.com"},
+				event.InvitationDeclined{GameID: ID.String(), Invitee: "player2@game.com"}),
+			When(command.JoinGame{GameID: ID, PlayerEmail: "player3@game.com"}),
+			Then(event.GameJoined{GameID: ID.String(), PlayerEmail: "player3@game.com"}),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotInvited", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.PlayerInvited{GameID: ID.String(), Invitee: "player2@game.com"}),
+			When(command.DeclineInvitation{GameID: ID, PlayerEmail: "player3@game.com"}),
+			ThenFailWith(game.ErrPlayerIsNotInvited),
+		)
+	})
 }
 
 func TestAggregateMakeMove(t *testing.T) {
 	t.Run("APlayerCanMakeAMove", func(t *testing.T) {
 		ID := mock.StringIdentifier("g777")
 		Test(t)(
-			Given(createTestAggregate(), event.GameCreated{GameID: ID.String()}),
-			When(command.MakeMove{GameID: ID, PlayerEmail: "player@game.com", Move: int(game.Rock)}),
-			Then(event.MoveDecided{GameID: ID.String(), PlayerEmail: "player@game.com", Move: int(game.Rock)}),
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+			Then(event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameIsPlayedWithCommittedMoves", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.CommittedMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+			ThenFailWith(game.ErrMovesMustBeCommitted),
 		)
 	})
 
@@ -80,27 +330,113 @@ func TestAggregateMakeMove(t *testing.T) {
 		ID := mock.StringIdentifier("g777")
 		Test(t)(
 			Given(createTestAggregate(),
-				event.GameCreated{GameID: ID.String()},
-				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player@game.com", Move: int(game.Rock)}),
-			When(command.MakeMove{GameID: ID, PlayerEmail: "player@game.com", Move: int(game.Rock)}),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
+				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
 			ThenFailWith(game.ErrPlayerIsTheSame),
 		)
 	})
 
+	t.Run("ItFailsIfThePlayerIsNotAParticipant", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player3@game.com", Move: int(game.Rock)}),
+			ThenFailWith(game.ErrPlayerIsNotAParticipant),
+		)
+	})
+
+	t.Run("ItFailsIfNobodyHasJoinedTheGame", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), gameCreated(ID, game.OpenMoves)),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+			ThenFailWith(game.ErrTheGameHaveNotStartedOrFinished),
+		)
+	})
+
 	t.Run("ItFailsIfTheGameHaveNotStarted", func(t *testing.T) {
 		ID := mock.StringIdentifier("g777")
 		Test(t)(
 			Given(createTestAggregate()),
-			When(command.MakeMove{GameID: ID, PlayerEmail: "player@game.com", Move: int(game.Rock)}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
 			ThenFailWith(game.ErrTheGameHaveNotStartedOrFinished),
 		)
 	})
 
+	t.Run("ItFailsIfTheMoveIsNotInTheRuleSet", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Spock)}),
+			ThenFailWith(game.ErrMoveIsNotInTheRuleSet),
+		)
+	})
+
+	t.Run("ItFailsIfTheMoveIsUnknown", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player1@game.com", Move: 42}),
+			ThenFailWith(domain.ValidationError{Field: "Move", Reason: "is not in the rule set"}),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotAnEmail", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player", Move: int(game.Rock)}),
+			ThenFailWith(domain.ValidationError{Field: "PlayerEmail", Reason: "is not a valid email address"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameIDIsNotGiven", func(t *testing.T) {
+		Test(t)(
+			Given(createTestAggregate(),
+				event.GameCreated{GameID: "g777", Creator: "player1@game.com", RuleSet: "classic", MoveMode: "open"},
+				event.GameJoined{GameID: "g777", PlayerEmail: "player2@game.com"}),
+			When(command.MakeMove{PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+			ThenFailWith(domain.ValidationError{Field: "GameID", Reason: "is required"}),
+		)
+	})
+
+	t.Run("ItPlaysTheGameByTheChosenRuleSet", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				event.GameCreated{GameID: ID.String(), Creator: "player1@game.com", RuleSet: "rpsls", MoveMode: "open"},
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
+				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Spock)}),
+			When(command.MakeMove{GameID: ID, PlayerEmail: "player2@game.com", Move: int(game.Rock)}),
+			Then(
+				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player2@game.com", Move: int(game.Rock)},
+				event.GameWon{GameID: ID.String(), Winner: "player1@game.com", Loser: "player2@game.com"},
+			),
+		)
+	})
+
 	t.Run("FirstPlayerDeclaredAWinner", func(t *testing.T) {
 		ID := mock.StringIdentifier("g777")
 		Test(t)(
 			Given(createTestAggregate(),
-				event.GameCreated{GameID: ID.String()},
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
 				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Scissors)}),
 			When(command.MakeMove{GameID: ID, PlayerEmail: "player2@game.com", Move: int(game.Paper)}),
 			Then(
@@ -114,7 +450,8 @@ func TestAggregateMakeMove(t *testing.T) {
 		ID := mock.StringIdentifier("g777")
 		Test(t)(
 			Given(createTestAggregate(),
-				event.GameCreated{GameID: ID.String()},
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
 				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)},
 				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player2@game.com", Move: int(game.Rock)},
 				event.GameTied{GameID: ID.String()}),
@@ -127,7 +464,8 @@ func TestAggregateMakeMove(t *testing.T) {
 		ID := mock.StringIdentifier("g777")
 		Test(t)(
 			Given(createTestAggregate(),
-				event.GameCreated{GameID: ID.String()},
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
 				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
 			When(command.MakeMove{GameID: ID, PlayerEmail: "player2@game.com", Move: int(game.Paper)}),
 			Then(
@@ -141,7 +479,8 @@ func TestAggregateMakeMove(t *testing.T) {
 		ID := mock.StringIdentifier("g777")
 		Test(t)(
 			Given(createTestAggregate(),
-				event.GameCreated{GameID: ID.String()},
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
 				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Scissors)}),
 			When(command.MakeMove{GameID: ID, PlayerEmail: "player2@game.com", Move: int(game.Scissors)}),
 			Then(
@@ -152,6 +491,349 @@ func TestAggregateMakeMove(t *testing.T) {
 	})
 }
 
+func TestAggregateCommitMove(t *testing.T) {
+	t.Run("APlayerCanCommitAMove", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+		commitment := game.Commit(game.Rock, salt1)
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.CommittedMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.CommitMove{GameID: ID, PlayerEmail: "player1@game.com", Commitment: commitment}),
+			Then(event.MoveCommitted{GameID: ID.String(), PlayerEmail: "player1@game.com", Commitment: commitment}),
+		)
+	})
+
+	t.Run("ItFailsIfTheMoveIsAlreadyCommitted", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+		commitment := game.Commit(game.Rock, salt1)
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.CommittedMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
+				event.MoveCommitted{GameID: ID.String(), PlayerEmail: "player1@game.com", Commitment: commitment}),
+			When(command.CommitMove{GameID: ID, PlayerEmail: "player1@game.com", Commitment: commitment}),
+			ThenFailWith(game.ErrMoveIsAlreadyCommitted),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotAParticipant", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.CommittedMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.CommitMove{GameID: ID, PlayerEmail: "player3@game.com", Commitment: game.Commit(game.Rock, salt3)}),
+			ThenFailWith(game.ErrPlayerIsNotAParticipant),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameIsPlayedWithOpenMoves", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
+				event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+			When(command.CommitMove{GameID: ID, PlayerEmail: "player2@game.com", Commitment: game.Commit(game.Rock, salt2)}),
+			ThenFailWith(game.ErrMovesMustBeOpen),
+		)
+	})
+
+	t.Run("ItFailsIfNobodyHasJoinedTheGame", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.CommittedMoves)),
+			When(command.CommitMove{GameID: ID, PlayerEmail: "player1@game.com", Commitment: game.Commit(game.Rock, salt1)}),
+			ThenFailWith(game.ErrTheGameHaveNotStartedOrFinished),
+		)
+	})
+
+	t.Run("ItFailsIfTheCommitmentIsNotASha256Hash", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.CommittedMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.CommitMove{GameID: ID, PlayerEmail: "player1@game.com", Commitment: "rock"}),
+			ThenFailWith(domain.ValidationError{Field: "Commitment", Reason: "is not a valid sha256 hash"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheCommitmentIsNotGiven", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.CommittedMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.CommitMove{GameID: ID, PlayerEmail: "player1@game.com"}),
+			ThenFailWith(domain.ValidationError{Field: "Commitment", Reason: "is required"}),
+		)
+	})
+}
+
+func TestAggregateRevealMove(t *testing.T) {
+	t.Run("APlayerCanRevealTheCommittedMove", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), committedGame(ID, game.Rock, game.Paper)...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock), Salt: salt1}),
+			Then(event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+		)
+	})
+
+	t.Run("TheGameIsDecidedOnceBothMovesAreRevealed", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(committedGame(ID, game.Rock, game.Paper),
+				event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)})...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player2@game.com", Move: int(game.Paper), Salt: salt2}),
+			Then(
+				event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player2@game.com", Move: int(game.Paper)},
+				event.GameWon{GameID: ID.String(), Winner: "player2@game.com", Loser: "player1@game.com"},
+			),
+		)
+	})
+
+	t.Run("TheGameIsTiedIfTheRevealedMovesAreTheSame", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(committedGame(ID, game.Rock, game.Rock),
+				event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player2@game.com", Move: int(game.Rock)})...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock), Salt: salt1}),
+			Then(
+				event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)},
+				event.GameTied{GameID: ID.String()},
+			),
+		)
+	})
+
+	t.Run("ThePlayerIsPenalizedIfTheMoveDoesNotMatchTheCommitment", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), committedGame(ID, game.Rock, game.Paper)...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Scissors), Salt: salt1}),
+			Then(
+				event.PlayerPenalized{
+					GameID:      ID.String(),
+					PlayerEmail: "player1@game.com",
+					Reason:      "the revealed move doesn't match the commitment",
+				},
+				event.GameWon{GameID: ID.String(), Winner: "player2@game.com", Loser: "player1@game.com"},
+			),
+		)
+	})
+
+	t.Run("ThePlayerIsPenalizedIfTheMoveIsNotInTheRuleSet", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), committedGame(ID, game.Spock, game.Paper)...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Spock), Salt: salt1}),
+			Then(
+				event.PlayerPenalized{
+					GameID:      ID.String(),
+					PlayerEmail: "player1@game.com",
+					Reason:      "the revealed move is not in the rule set",
+				},
+				event.GameWon{GameID: ID.String(), Winner: "player2@game.com", Loser: "player1@game.com"},
+			),
+		)
+	})
+
+	t.Run("ItFailsIfTheOpponentHasNotCommittedTheirMove", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.CommittedMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
+				event.MoveCommitted{
+					GameID:      ID.String(),
+					PlayerEmail: "player1@game.com",
+					Commitment:  game.Commit(game.Rock, salt1),
+				}),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock), Salt: salt1}),
+			ThenFailWith(game.ErrNotAllMovesAreCommitted),
+		)
+	})
+
+	t.Run("ItFailsIfTheMoveIsAlreadyRevealed", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(committedGame(ID, game.Rock, game.Paper),
+				event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)})...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock), Salt: salt1}),
+			ThenFailWith(game.ErrMoveIsAlreadyRevealed),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotAParticipant", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), committedGame(ID, game.Rock, game.Paper)...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player3@game.com", Move: int(game.Rock), Salt: salt3}),
+			ThenFailWith(game.ErrPlayerIsNotAParticipant),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameIsPlayedWithOpenMoves", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				gameCreated(ID, game.OpenMoves),
+				event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"}),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock), Salt: salt1}),
+			ThenFailWith(game.ErrMovesMustBeOpen),
+		)
+	})
+
+	t.Run("ItFailsIfTheSaltIsNotGiven", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), committedGame(ID, game.Rock, game.Paper)...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock)}),
+			ThenFailWith(domain.ValidationError{Field: "Salt", Reason: "is required"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheSaltIsTooShort", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), committedGame(ID, game.Rock, game.Paper)...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock), Salt: "salt1"}),
+			ThenFailWith(domain.ValidationError{Field: "Salt", Reason: "must be at least 16 bytes long"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheGameIsFinished", func(t *testing.T) {
+		ID := mock.StringIdentifier("g777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(committedGame(ID, game.Rock, game.Paper),
+				event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)},
+				event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player2@game.com", Move: int(game.Paper)},
+				event.GameWon{GameID: ID.String(), Winner: "player2@game.com", Loser: "player1@game.com"})...),
+			When(command.RevealMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Rock), Salt: salt1}),
+			ThenFailWith(game.ErrTheGameHaveNotStartedOrFinished),
+		)
+	})
+}
+
+func TestAggregateSnapshotState(t *testing.T) {
+	t.Run("ItRestoresTheGameFromASnapshot", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("g777")
+		agg := createTestAggregate()
+		assert.Ok(t, agg.Apply(
+			gameCreated(ID, game.OpenMoves),
+			event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
+			event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)},
+		))
+
+		snapshot, err := agg.Snapshot()
+		assert.Ok(t, err)
+
+		restored := createTestAggregate()
+
+		// act
+		err = restored.Restore(snapshot)
+
+		// assert
+		assert.Ok(t, err)
+		assert.Equals(t, 3, restored.Version())
+
+		got, err := restored.Handle(command.MakeMove{GameID: ID, PlayerEmail: "player2@game.com", Move: int(game.Paper)})
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{
+			event.MoveDecided{GameID: ID.String(), PlayerEmail: "player2@game.com", Move: int(game.Paper)},
+			event.GameWon{GameID: ID.String(), Winner: "player2@game.com", Loser: "player1@game.com"},
+		}, got)
+	})
+
+	t.Run("ItRestoresTheRuleSetOfTheGame", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("g777")
+		agg := createTestAggregate()
+		assert.Ok(t, agg.Apply(
+			event.GameCreated{GameID: ID.String(), Creator: "player1@game.com", RuleSet: "rpsls", MoveMode: "open"},
+			event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
+		))
+
+		snapshot, err := agg.Snapshot()
+		assert.Ok(t, err)
+
+		restored := createTestAggregate()
+
+		// act
+		err = restored.Restore(snapshot)
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := restored.Handle(command.MakeMove{GameID: ID, PlayerEmail: "player1@game.com", Move: int(game.Lizard)})
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{
+			event.MoveDecided{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Lizard)},
+		}, got)
+	})
+
+	t.Run("ItRestoresTheCommittedMovesOfTheGame", func(t *testing.T) {
+		// arrange
+		ID := mock.StringIdentifier("g777")
+		agg := createTestAggregate()
+		assert.Ok(t, agg.Apply(append(committedGame(ID, game.Rock, game.Paper),
+			event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player1@game.com", Move: int(game.Rock)})...))
+
+		snapshot, err := agg.Snapshot()
+		assert.Ok(t, err)
+
+		restored := createTestAggregate()
+
+		// act
+		err = restored.Restore(snapshot)
+
+		// assert
+		assert.Ok(t, err)
+
+		got, err := restored.Handle(
+			command.RevealMove{GameID: ID, PlayerEmail: "player2@game.com", Move: int(game.Paper), Salt: salt2})
+		assert.Ok(t, err)
+		assert.Equals(t, []domain.DomainEvent{
+			event.MoveRevealed{GameID: ID.String(), PlayerEmail: "player2@game.com", Move: int(game.Paper)},
+			event.GameWon{GameID: ID.String(), Winner: "player2@game.com", Loser: "player1@game.com"},
+		}, got)
+	})
+
+	t.Run("ItFailsToRestoreAnInvalidSnapshot", func(t *testing.T) {
+		// arrange
+		agg := game.NewAggregate(mock.StringIdentifier("g777"))
+
+		// act
+		err := agg.RestoreState("invalid")
+
+		// assert
+		assert.Equals(t, game.ErrInvalidSnapshot, err)
+	})
+}
+
 func createTestAggregate() *aggregate.Advanced {
 	gameAgg := game.NewAggregate(ksuid.New())
 
@@ -163,3 +845,27 @@ func createTestAggregate() *aggregate.Advanced {
 
 	return aggregate.NewAdvanced(gameAgg, commandHandler, eventApplier)
 }
+
+// gameCreated returns the event of a classic game created by the first player.
+func gameCreated(ID domain.Identifier, moveMode game.MoveMode) event.GameCreated {
+	return event.GameCreated{
+		GameID: ID.String(), Creator: "player1@game.com", RuleSet: "classic", MoveMode: string(moveMode)}
+}
+
+// committedGame returns the events of a game both players have committed their moves to.
+func committedGame(ID domain.Identifier, firstMove, secondMove game.Move) []domain.DomainEvent {
+	return []domain.DomainEvent{
+		gameCreated(ID, game.CommittedMoves),
+		event.GameJoined{GameID: ID.String(), PlayerEmail: "player2@game.com"},
+		event.MoveCommitted{
+			GameID:      ID.String(),
+			PlayerEmail: "player1@game.com",
+			Commitment:  game.Commit(firstMove, salt1),
+		},
+		event.MoveCommitted{
+			GameID:      ID.String(),
+			PlayerEmail: "player2@game.com",
+			Commitment:  game.Commit(secondMove, salt2),
+		},
+	}
+}
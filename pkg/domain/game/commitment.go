@@ -0,0 +1,21 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// MinSaltLength is the least number of bytes in a salt.
+//
+// The commitments are published, so a short salt would let the opponent find the move by brute force.
+const MinSaltLength = 16
+
+// Commit returns the commitment to the move, the hex encoded sha256 hash of the move salted with the given salt.
+//
+// The salt must be random, at least MinSaltLength bytes long and kept secret until the move is revealed,
+// otherwise the opponent can guess the move.
+func Commit(move Move, salt string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", move, salt)))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,26 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/domain/game"
+)
+
+func TestCommit(t *testing.T) {
+	t.Run("ItReturnsAHexEncodedSha256Hash", func(t *testing.T) {
+		assert.Equals(t, 64, len(game.Commit(game.Rock, "salt")))
+	})
+
+	t.Run("ItReturnsTheSameCommitmentForTheSameMoveAndSalt", func(t *testing.T) {
+		assert.Equals(t, game.Commit(game.Rock, "salt"), game.Commit(game.Rock, "salt"))
+	})
+
+	t.Run("ItReturnsDifferentCommitmentsForDifferentMoves", func(t *testing.T) {
+		assert.True(t, game.Commit(game.Rock, "salt") != game.Commit(game.Paper, "salt"))
+	})
+
+	t.Run("ItReturnsDifferentCommitmentsForDifferentSalts", func(t *testing.T) {
+		assert.True(t, game.Commit(game.Rock, "salt1") != game.Commit(game.Rock, "salt2"))
+	})
+}
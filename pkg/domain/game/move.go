@@ -6,21 +6,35 @@ const (
 	Rock Move = iota
 	Paper
 	Scissors
+	Lizard
+	Spock
+	Fire
+	Sponge
+	Air
+	Water
+	Snake
+	Human
+	Tree
+	Wolf
+	Dragon
+	Devil
+	Lightning
+	Gun
 )
 
+var moveNames = [...]string{
+	"rock", "paper", "scissors", "lizard", "spock", "fire", "sponge", "air", "water",
+	"snake", "human", "tree", "wolf", "dragon", "devil", "lightning", "gun",
+}
+
 func NewMove(m int) Move {
 	return Move(m)
 }
 
-func (m Move) defeats(other Move) bool {
-	switch m {
-	case Rock:
-		return other == Scissors
-	case Paper:
-		return other == Rock
-	case Scissors:
-		return other == Paper
-	default:
-		return false
+// String returns the name of the move.
+func (m Move) String() string {
+	if m < 0 || int(m) >= len(moveNames) {
+		return "unknown"
 	}
+	return moveNames[m]
 }
@@ -0,0 +1,42 @@
+package game
+
+import "errors"
+
+var ErrUnknownMoveMode = errors.New("unknown move mode")
+
+// MoveMode tells how the players make their moves.
+type MoveMode string
+
+const (
+	// OpenMoves are made in plain text, so the second player can see the first move.
+	OpenMoves MoveMode = "open"
+	// CommittedMoves are committed to first and revealed once both players have committed, see Commit.
+	CommittedMoves MoveMode = "commit-reveal"
+)
+
+// MoveModeOf returns the move mode of the given name, the open moves are used by default.
+//
+// It returns ErrUnknownMoveMode if there is no such move mode.
+func MoveModeOf(name string) (MoveMode, error) {
+	switch MoveMode(name) {
+	case "", OpenMoves:
+		return OpenMoves, nil
+	case CommittedMoves:
+		return CommittedMoves, nil
+	default:
+		return "", ErrUnknownMoveMode
+	}
+}
+
+// mustMoveModeOf returns the move mode recorded by an event, there is no default one.
+func mustMoveModeOf(name string) MoveMode {
+	if name == "" {
+		panic("move mode is required")
+	}
+
+	moveMode, err := MoveModeOf(name)
+	if err != nil {
+		panic(err)
+	}
+	return moveMode
+}
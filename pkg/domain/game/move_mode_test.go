@@ -0,0 +1,32 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/domain/game"
+)
+
+func TestMoveModeOf(t *testing.T) {
+	t.Run("ItReturnsTheOpenMovesByDefault", func(t *testing.T) {
+		got, err := game.MoveModeOf("")
+
+		assert.Ok(t, err)
+		assert.Equals(t, game.OpenMoves, got)
+	})
+
+	t.Run("ItReturnsTheKnownMoveModes", func(t *testing.T) {
+		for _, want := range []game.MoveMode{game.OpenMoves, game.CommittedMoves} {
+			got, err := game.MoveModeOf(string(want))
+
+			assert.Ok(t, err)
+			assert.Equals(t, want, got)
+		}
+	})
+
+	t.Run("ItFailsIfTheMoveModeIsUnknown", func(t *testing.T) {
+		_, err := game.MoveModeOf("blind")
+
+		assert.Equals(t, game.ErrUnknownMoveMode, err)
+	})
+}
@@ -0,0 +1,18 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/domain/game"
+)
+
+func TestMoveString(t *testing.T) {
+	t.Run("ItReturnsTheNameOfTheMove", func(t *testing.T) {
+		assert.Equals(t, "spock", game.Spock.String())
+	})
+
+	t.Run("ItReturnsUnknownForAnUnknownMove", func(t *testing.T) {
+		assert.Equals(t, "unknown", game.NewMove(42).String())
+	})
+}
@@ -0,0 +1,114 @@
+package game
+
+import "errors"
+
+var ErrUnknownRuleSet = errors.New("unknown rule set")
+
+// The built-in rule sets.
+var (
+	Classic = NewRuleSet("classic", Rock, Scissors, Paper)
+	RPSLS   = NewRuleSet("rpsls", Rock, Scissors, Lizard, Paper, Spock)
+	RPS7    = NewRuleSet("rps-7", Rock, Fire, Scissors, Sponge, Paper, Air, Water)
+	RPS15   = NewRuleSet("rps-15",
+		Rock, Fire, Scissors, Snake, Human, Tree, Wolf, Sponge, Paper, Air, Water, Dragon, Devil, Lightning, Gun)
+)
+
+// RuleSet defines the moves the game is played with and which of them beat which.
+//
+// The moves form a circle in which every move beats the half of the moves which follow it
+// and is beaten by the other half, so that each move is as good as any other.
+type RuleSet struct {
+	name      string
+	moves     []Move
+	positions map[Move]int
+}
+
+// NewRuleSet creates a new instance of RuleSet.
+//
+// The moves are given in the circular order, their number must be odd so that a move never beats itself.
+func NewRuleSet(name string, moves ...Move) *RuleSet {
+	if name == "" {
+		panic("name is required")
+	}
+
+	if len(moves)%2 == 0 {
+		panic("the number of moves must be odd")
+	}
+
+	positions := make(map[Move]int, len(moves))
+	for i, m := range moves {
+		if _, ok := positions[m]; ok {
+			panic("the moves must be unique")
+		}
+		positions[m] = i
+	}
+
+	return &RuleSet{name: name, moves: moves, positions: positions}
+}
+
+// RuleSetOf returns the built-in rule set of the given name, the classic one is used by default.
+//
+// It returns ErrUnknownRuleSet if there is no such rule set.
+func RuleSetOf(name string) (*RuleSet, error) {
+	switch name {
+	case "", Classic.name:
+		return Classic, nil
+	case RPSLS.name:
+		return RPSLS, nil
+	case RPS7.name:
+		return RPS7, nil
+	case RPS15.name:
+		return RPS15, nil
+	default:
+		return nil, ErrUnknownRuleSet
+	}
+}
+
+// MustRuleSetOf returns the built-in rule set recorded by an event, there is no default one.
+//
+// It panics if the name is not given or there is no such rule set.
+func MustRuleSetOf(name string) *RuleSet {
+	if name == "" {
+		panic("rule set is required")
+	}
+
+	ruleSet, err := RuleSetOf(name)
+	if err != nil {
+		panic(err)
+	}
+	return ruleSet
+}
+
+// Name returns the name of the rule set.
+func (r *RuleSet) Name() string {
+	return r.name
+}
+
+// Moves returns the moves of the rule set in the circular order.
+func (r *RuleSet) Moves() []Move {
+	moves := make([]Move, len(r.moves))
+	copy(moves, r.moves)
+	return moves
+}
+
+// Contains tells whether the move can be made in a game played with the rule set.
+func (r *RuleSet) Contains(m Move) bool {
+	_, ok := r.positions[m]
+	return ok
+}
+
+// Beats tells whether the move beats the other one, a move which is not in the rule set beats nothing.
+func (r *RuleSet) Beats(m, other Move) bool {
+	pos, ok := r.positions[m]
+	if !ok {
+		return false
+	}
+
+	otherPos, ok := r.positions[other]
+	if !ok {
+		return false
+	}
+
+	distance := (otherPos - pos + len(r.moves)) % len(r.moves)
+	return distance > 0 && distance <= len(r.moves)/2
+}
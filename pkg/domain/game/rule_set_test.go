@@ -0,0 +1,133 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/pkg/domain/game"
+)
+
+func TestNewRuleSet(t *testing.T) {
+	t.Run("ItPanicsIfNameIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			game.NewRuleSet("", game.Rock, game.Scissors, game.Paper)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfTheNumberOfMovesIsEven", func(t *testing.T) {
+		factory := func() {
+			game.NewRuleSet("even", game.Rock, game.Scissors, game.Paper, game.Spock)
+		}
+		assert.Panic(t, factory)
+	})
+
+	t.Run("ItPanicsIfTheMovesAreNotUnique", func(t *testing.T) {
+		factory := func() {
+			game.NewRuleSet("duplicated", game.Rock, game.Scissors, game.Rock)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestRuleSetOf(t *testing.T) {
+	t.Run("ItReturnsTheClassicRuleSetByDefault", func(t *testing.T) {
+		got, err := game.RuleSetOf("")
+
+		assert.Ok(t, err)
+		assert.Equals(t, game.Classic, got)
+	})
+
+	t.Run("ItReturnsTheBuiltInRuleSets", func(t *testing.T) {
+		for _, want := range []*game.RuleSet{game.Classic, game.RPSLS, game.RPS7, game.RPS15} {
+			got, err := game.RuleSetOf(want.Name())
+
+			assert.Ok(t, err)
+			assert.Equals(t, want, got)
+		}
+	})
+
+	t.Run("ItFailsIfTheRuleSetIsUnknown", func(t *testing.T) {
+		_, err := game.RuleSetOf("chess")
+
+		assert.Equals(t, game.ErrUnknownRuleSet, err)
+	})
+}
+
+func TestMustRuleSetOf(t *testing.T) {
+	t.Run("ItReturnsTheBuiltInRuleSets", func(t *testing.T) {
+		for _, want := range []*game.RuleSet{game.Classic, game.RPSLS, game.RPS7, game.RPS15} {
+			assert.Equals(t, want, game.MustRuleSetOf(want.Name()))
+		}
+	})
+
+	t.Run("ItPanicsIfTheRuleSetIsNotGiven", func(t *testing.T) {
+		assert.Panic(t, func() {
+			game.MustRuleSetOf("")
+		})
+	})
+
+	t.Run("ItPanicsIfTheRuleSetIsUnknown", func(t *testing.T) {
+		assert.Panic(t, func() {
+			game.MustRuleSetOf("chess")
+		})
+	})
+}
+
+func TestRuleSetContains(t *testing.T) {
+	t.Run("ItTellsWhetherTheMoveIsInTheRuleSet", func(t *testing.T) {
+		assert.True(t, game.Classic.Contains(game.Paper))
+		assert.True(t, !game.Classic.Contains(game.Spock))
+		assert.True(t, game.RPSLS.Contains(game.Spock))
+	})
+}
+
+func TestRuleSetBeats(t *testing.T) {
+	t.Run("ItFollowsTheClassicRules", func(t *testing.T) {
+		assert.True(t, game.Classic.Beats(game.Rock, game.Scissors))
+		assert.True(t, game.Classic.Beats(game.Paper, game.Rock))
+		assert.True(t, game.Classic.Beats(game.Scissors, game.Paper))
+
+		assert.True(t, !game.Classic.Beats(game.Rock, game.Paper))
+		assert.True(t, !game.Classic.Beats(game.Paper, game.Scissors))
+		assert.True(t, !game.Classic.Beats(game.Scissors, game.Rock))
+		assert.True(t, !game.Classic.Beats(game.Rock, game.Rock))
+	})
+
+	t.Run("ItFollowsTheRPSLSRules", func(t *testing.T) {
+		beats := map[game.Move][]game.Move{
+			game.Rock:     {game.Scissors, game.Lizard},
+			game.Paper:    {game.Rock, game.Spock},
+			game.Scissors: {game.Paper, game.Lizard},
+			game.Lizard:   {game.Paper, game.Spock},
+			game.Spock:    {game.Rock, game.Scissors},
+		}
+
+		for m, others := range beats {
+			for _, other := range others {
+				assert.True(t, game.RPSLS.Beats(m, other))
+				assert.True(t, !game.RPSLS.Beats(other, m))
+			}
+		}
+	})
+
+	t.Run("EveryMoveBeatsHalfOfTheOthers", func(t *testing.T) {
+		for _, ruleSet := range []*game.RuleSet{game.Classic, game.RPSLS, game.RPS7, game.RPS15} {
+			moves := ruleSet.Moves()
+			for _, m := range moves {
+				beaten := 0
+				for _, other := range moves {
+					if ruleSet.Beats(m, other) {
+						beaten++
+					}
+				}
+				assert.Equals(t, len(moves)/2, beaten)
+			}
+		}
+	})
+
+	t.Run("AMoveWhichIsNotInTheRuleSetBeatsNothing", func(t *testing.T) {
+		assert.True(t, !game.Classic.Beats(game.Spock, game.Rock))
+		assert.True(t, !game.Classic.Beats(game.Rock, game.Spock))
+	})
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+)
+
+// ValidateRuleSet returns the built-in rule set of the given name, see RuleSetOf.
+//
+// It returns domain.ValidationError if there is no such rule set.
+func ValidateRuleSet(name string) (*RuleSet, error) {
+	ruleSet, err := RuleSetOf(name)
+	if err != nil {
+		return nil, domain.ValidationError{Field: "RuleSet", Reason: "is unknown"}
+	}
+	return ruleSet, nil
+}
+
+func validateMoveMode(name string) (MoveMode, error) {
+	moveMode, err := MoveModeOf(name)
+	if err != nil {
+		return "", domain.ValidationError{Field: "MoveMode", Reason: "is unknown"}
+	}
+	return moveMode, nil
+}
+
+func validateSalt(salt string) error {
+	if err := domain.ValidateRequired("Salt", salt); err != nil {
+		return err
+	}
+
+	if len(salt) < MinSaltLength {
+		return domain.ValidationError{Field: "Salt", Reason: fmt.Sprintf("must be at least %d bytes long", MinSaltLength)}
+	}
+	return nil
+}
+
+func validateCommitment(commitment string) error {
+	if err := domain.ValidateRequired("Commitment", commitment); err != nil {
+		return err
+	}
+
+	if b, err := hex.DecodeString(commitment); err != nil || len(b) != sha256.Size {
+		return domain.ValidationError{Field: "Commitment", Reason: "is not a valid sha256 hash"}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package match
+
+import (
+	"errors"
+
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+	"github.com/screwyprof/roshambo/pkg/domain/game"
+	"github.com/screwyprof/roshambo/pkg/event"
+)
+
+type state int
+
+const (
+	notCreated state = iota
+	playing
+	won
+)
+
+var (
+	ErrMatchIsAlreadyStarted            = errors.New("match is already started")
+	ErrBestOfMustBeOddAndPositive       = domain.ValidationError{Field: "BestOf", Reason: "must be odd and positive"}
+	ErrPlayerIsNotInTheMatch            = errors.New("the player is not in the match")
+	ErrPlayerHasAlreadyMoved            = errors.New("the player has already moved in this round")
+	ErrTheMatchHaveNotStartedOrFinished = errors.New("the match haven't started or finished")
+)
+
+// Aggregate is a best-of-N match between two players.
+//
+// A match is played in rounds, a tied round is replayed.
+// The match is won by the player who first wins the majority of the rounds.
+type Aggregate struct {
+	id domain.Identifier
+
+	state   state
+	players [2]string
+	bestOf  int
+	ruleSet *game.RuleSet
+	round   int
+	scores  map[string]int
+	moves   map[string]game.Move
+}
+
+// NewAggregate creates a new instance of Aggregate.
+func NewAggregate(ID domain.Identifier) *Aggregate {
+	if ID == nil {
+		panic("ID is required")
+	}
+
+	return &Aggregate{
+		id:      ID,
+		state:   notCreated,
+		ruleSet: game.Classic,
+		scores:  make(map[string]int),
+		moves:   make(map[string]game.Move),
+	}
+}
+
+// AggregateID implements domain.Aggregate interface.
+func (a *Aggregate) AggregateID() domain.Identifier {
+	return a.id
+}
+
+// AggregateType implements domain.Aggregate interface.
+func (a *Aggregate) AggregateType() string {
+	return "match.Aggregate"
+}
+
+// CreateMatch creates a new match played with the given rule set and starts its first round.
+// The classic rule set is used by default.
+//
+// It returns ErrMatchIsAlreadyStarted if the match has already started.
+// It returns domain.ValidationError if the match ID, the players' emails or the rule set is invalid,
+// or if a player is going to play against themselves.
+// It returns ErrBestOfMustBeOddAndPositive if the match could end in a draw.
+func (a *Aggregate) CreateMatch(c command.CreateMatch) ([]domain.DomainEvent, error) {
+	if a.state != notCreated {
+		return nil, ErrMatchIsAlreadyStarted
+	}
+
+	if err := domain.ValidateIdentifier("MatchID", c.MatchID); err != nil {
+		return nil, err
+	}
+
+	if err := validatePlayers(c.FirstPlayer, c.SecondPlayer); err != nil {
+		return nil, err
+	}
+
+	ruleSet, err := game.ValidateRuleSet(c.RuleSet)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.BestOf <= 0 || c.BestOf%2 == 0 {
+		return nil, ErrBestOfMustBeOddAndPositive
+	}
+
+	matchID := c.MatchID.String()
+	return []domain.DomainEvent{
+		event.MatchCreated{
+			MatchID:      matchID,
+			FirstPlayer:  c.FirstPlayer,
+			SecondPlayer: c.SecondPlayer,
+			BestOf:       c.BestOf,
+			RuleSet:      ruleSet.Name(),
+		},
+		event.RoundStarted{MatchID: matchID, Round: 1},
+	}, nil
+}
+
+// PlayRound makes the player's move in the current round.
+//
+// When both players have moved, the round is won or tied and either the next round is started or the match is won.
+//
+// It returns ErrTheMatchHaveNotStartedOrFinished if the match haven't started yet.
+// It returns ErrPlayerIsNotInTheMatch if the player doesn't play the match.
+// It returns ErrPlayerHasAlreadyMoved if the player has already moved in the current round.
+// It returns game.ErrMoveIsNotInTheRuleSet if the move cannot be made in the match.
+func (a *Aggregate) PlayRound(c command.PlayRound) ([]domain.DomainEvent, error) {
+	if a.state != playing {
+		return nil, ErrTheMatchHaveNotStartedOrFinished
+	}
+
+	opponent, ok := a.opponentOf(c.PlayerEmail)
+	if !ok {
+		return nil, ErrPlayerIsNotInTheMatch
+	}
+
+	if _, ok := a.moves[c.PlayerEmail]; ok {
+		return nil, ErrPlayerHasAlreadyMoved
+	}
+
+	if !a.ruleSet.Contains(game.NewMove(c.Move)) {
+		return nil, game.ErrMoveIsNotInTheRuleSet
+	}
+
+	matchID := c.MatchID.String()
+	events := []domain.DomainEvent{
+		event.RoundMoveDecided{MatchID: matchID, Round: a.round, PlayerEmail: c.PlayerEmail, Move: c.Move},
+	}
+
+	opponentMove, ok := a.moves[opponent]
+	if !ok {
+		return events, nil
+	}
+
+	return append(events, a.finishRound(matchID, c.PlayerEmail, game.NewMove(c.Move), opponent, opponentMove)...), nil
+}
+
+func (a *Aggregate) OnMatchCreated(e event.MatchCreated) {
+	a.state = playing
+	a.players = [2]string{e.FirstPlayer, e.SecondPlayer}
+	a.bestOf = e.BestOf
+	a.ruleSet = game.MustRuleSetOf(e.RuleSet)
+}
+
+func (a *Aggregate) OnRoundStarted(e event.RoundStarted) {
+	a.round = e.Round
+	a.moves = make(map[string]game.Move)
+}
+
+func (a *Aggregate) OnRoundMoveDecided(e event.RoundMoveDecided) {
+	a.moves[e.PlayerEmail] = game.NewMove(e.Move)
+}
+
+func (a *Aggregate) OnRoundWon(e event.RoundWon) {
+	a.scores[e.Winner]++
+}
+
+func (a *Aggregate) OnRoundTied(e event.RoundTied) {
+	// the score doesn't change, the round is replayed.
+}
+
+func (a *Aggregate) OnMatchWon(e event.MatchWon) {
+	a.state = won
+}
+
+func (a *Aggregate) opponentOf(playerEmail string) (string, bool) {
+	switch playerEmail {
+	case a.players[0]:
+		return a.players[1], true
+	case a.players[1]:
+		return a.players[0], true
+	default:
+		return "", false
+	}
+}
+
+func (a *Aggregate) finishRound(
+	matchID string, playerEmail string, move game.Move, opponent string, opponentMove game.Move) []domain.DomainEvent {
+	next := event.RoundStarted{MatchID: matchID, Round: a.round + 1}
+
+	winner, loser := playerEmail, opponent
+	switch {
+	case a.ruleSet.Beats(opponentMove, move):
+		winner, loser = opponent, playerEmail
+	case !a.ruleSet.Beats(move, opponentMove):
+		return []domain.DomainEvent{event.RoundTied{MatchID: matchID, Round: a.round}, next}
+	}
+
+	roundWon := event.RoundWon{MatchID: matchID, Round: a.round, Winner: winner, Loser: loser}
+	if a.scores[winner]+1 > a.bestOf/2 {
+		return []domain.DomainEvent{roundWon, event.MatchWon{MatchID: matchID, Winner: winner, Loser: loser}}
+	}
+	return []domain.DomainEvent{roundWon, next}
+}
@@ -0,0 +1,325 @@
+package match_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/aggregate"
+	. "github.com/screwyprof/roshambo/internal/pkg/cqrs/aggregate/testdata/fixture"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
+
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+	"github.com/screwyprof/roshambo/pkg/domain/game"
+	"github.com/screwyprof/roshambo/pkg/domain/match"
+	"github.com/screwyprof/roshambo/pkg/event"
+)
+
+// ensure that match aggregate implements domain.Aggregate interface.
+var _ domain.Aggregate = (*match.Aggregate)(nil)
+
+const (
+	player1 = "player1@game.com"
+	player2 = "player2@game.com"
+)
+
+func TestNewAggregate(t *testing.T) {
+	t.Run("ItPanicsIfIDIsNotGiven", func(t *testing.T) {
+		factory := func() {
+			match.NewAggregate(nil)
+		}
+		assert.Panic(t, factory)
+	})
+}
+
+func TestAggregateAggregateID(t *testing.T) {
+	t.Run("ItReturnsAggregateID", func(t *testing.T) {
+		ID := mock.StringIdentifier("Match")
+		agg := match.NewAggregate(ID)
+
+		assert.Equals(t, ID, agg.AggregateID())
+	})
+}
+
+func TestAggregateAggregateType(t *testing.T) {
+	t.Run("ItReturnsAggregateType", func(t *testing.T) {
+		ID := mock.StringIdentifier("Match")
+		agg := match.NewAggregate(ID)
+
+		assert.Equals(t, "match.Aggregate", agg.AggregateType())
+	})
+}
+
+func TestAggregateCreateMatch(t *testing.T) {
+	t.Run("ItCreatesMatchAndStartsTheFirstRound", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player2, BestOf: 3}),
+			Then(
+				event.MatchCreated{
+					MatchID: ID.String(), FirstPlayer: player1, SecondPlayer: player2, BestOf: 3, RuleSet: "classic"},
+				event.RoundStarted{MatchID: ID.String(), Round: 1},
+			),
+		)
+	})
+
+	t.Run("ItCreatesMatchWithTheGivenRuleSet", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player2, BestOf: 3, RuleSet: "rpsls"}),
+			Then(
+				event.MatchCreated{
+					MatchID: ID.String(), FirstPlayer: player1, SecondPlayer: player2, BestOf: 3, RuleSet: "rpsls"},
+				event.RoundStarted{MatchID: ID.String(), Round: 1},
+			),
+		)
+	})
+
+	t.Run("ItFailsIfTheRuleSetIsUnknown", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player2, BestOf: 3, RuleSet: "chess"}),
+			ThenFailWith(domain.ValidationError{Field: "RuleSet", Reason: "is unknown"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheMatchIDIsEmpty", func(t *testing.T) {
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{
+				MatchID: mock.StringIdentifier(""), FirstPlayer: player1, SecondPlayer: player2, BestOf: 3}),
+			ThenFailWith(domain.ValidationError{Field: "MatchID", Reason: "is required"}),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotGiven", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{MatchID: ID, SecondPlayer: player2, BestOf: 3}),
+			ThenFailWith(domain.ValidationError{Field: "FirstPlayer", Reason: "is required"}),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotAnEmail", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: "player2", BestOf: 3}),
+			ThenFailWith(domain.ValidationError{Field: "SecondPlayer", Reason: "is not a valid email address"}),
+		)
+	})
+
+	t.Run("ItCannotCreateAMatchIfItIsAlreadyStarted", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), matchCreated(ID, 3)...),
+			When(command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player2, BestOf: 3}),
+			ThenFailWith(match.ErrMatchIsAlreadyStarted),
+		)
+	})
+
+	t.Run("ItFailsIfTheNumberOfRoundsIsEven", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player2, BestOf: 2}),
+			ThenFailWith(match.ErrBestOfMustBeOddAndPositive),
+		)
+	})
+
+	t.Run("ItFailsIfTheNumberOfRoundsIsNotPositive", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player2, BestOf: -1}),
+			ThenFailWith(match.ErrBestOfMustBeOddAndPositive),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayersAreTheSame", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player1, BestOf: 3}),
+			ThenFailWith(domain.ValidationError{Field: "SecondPlayer", Reason: "must differ from the first player"}),
+		)
+	})
+}
+
+func TestAggregatePlayRound(t *testing.T) {
+	t.Run("APlayerCanMakeAMove", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), matchCreated(ID, 3)...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Rock)}),
+			Then(event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player1, Move: int(game.Rock)}),
+		)
+	})
+
+	t.Run("ItFailsIfTheMatchHaveNotStarted", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate()),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Rock)}),
+			ThenFailWith(match.ErrTheMatchHaveNotStartedOrFinished),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayerIsNotInTheMatch", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), matchCreated(ID, 3)...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: "another@game.com", Move: int(game.Rock)}),
+			ThenFailWith(match.ErrPlayerIsNotInTheMatch),
+		)
+	})
+
+	t.Run("ItFailsIfThePlayerHasAlreadyMoved", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(matchCreated(ID, 3),
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player1, Move: int(game.Rock)})...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Paper)}),
+			ThenFailWith(match.ErrPlayerHasAlreadyMoved),
+		)
+	})
+
+	t.Run("ItFailsIfTheMoveIsUnknown", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), matchCreated(ID, 3)...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: 42}),
+			ThenFailWith(domain.ValidationError{Field: "Move", Reason: "is not in the rule set"}),
+		)
+	})
+
+	t.Run("ItFailsIfTheMoveIsNotInTheRuleSet", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), matchCreated(ID, 3)...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Spock)}),
+			ThenFailWith(game.ErrMoveIsNotInTheRuleSet),
+		)
+	})
+
+	t.Run("ItPlaysTheRoundsByTheChosenRuleSet", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(),
+				event.MatchCreated{MatchID: ID.String(), FirstPlayer: player1, SecondPlayer: player2, BestOf: 3, RuleSet: "rpsls"},
+				event.RoundStarted{MatchID: ID.String(), Round: 1},
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player1, Move: int(game.Spock)}),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Rock)}),
+			Then(
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player2, Move: int(game.Rock)},
+				event.RoundWon{MatchID: ID.String(), Round: 1, Winner: player1, Loser: player2},
+				event.RoundStarted{MatchID: ID.String(), Round: 2},
+			),
+		)
+	})
+
+	t.Run("TheRoundIsWonAndTheNextOneIsStarted", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(matchCreated(ID, 3),
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player1, Move: int(game.Rock)})...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Paper)}),
+			Then(
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player2, Move: int(game.Paper)},
+				event.RoundWon{MatchID: ID.String(), Round: 1, Winner: player2, Loser: player1},
+				event.RoundStarted{MatchID: ID.String(), Round: 2},
+			),
+		)
+	})
+
+	t.Run("TheTiedRoundIsReplayed", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(matchCreated(ID, 3),
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player1, Move: int(game.Rock)})...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Rock)}),
+			Then(
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player2, Move: int(game.Rock)},
+				event.RoundTied{MatchID: ID.String(), Round: 1},
+				event.RoundStarted{MatchID: ID.String(), Round: 2},
+			),
+		)
+	})
+
+	t.Run("TheMatchIsWonOnceThePlayerWinsTheMajorityOfTheRounds", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(matchCreated(ID, 3),
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player1, Move: int(game.Rock)},
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player2, Move: int(game.Scissors)},
+				event.RoundWon{MatchID: ID.String(), Round: 1, Winner: player1, Loser: player2},
+				event.RoundStarted{MatchID: ID.String(), Round: 2},
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 2, PlayerEmail: player2, Move: int(game.Paper)})...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Scissors)}),
+			Then(
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 2, PlayerEmail: player1, Move: int(game.Scissors)},
+				event.RoundWon{MatchID: ID.String(), Round: 2, Winner: player1, Loser: player2},
+				event.MatchWon{MatchID: ID.String(), Winner: player1, Loser: player2},
+			),
+		)
+	})
+
+	t.Run("ItFailsIfTheMoveIsMadeAfterTheMatchIsWon", func(t *testing.T) {
+		ID := mock.StringIdentifier("m777")
+
+		Test(t)(
+			Given(createTestAggregate(), append(matchCreated(ID, 1),
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player1, Move: int(game.Rock)},
+				event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player2, Move: int(game.Scissors)},
+				event.RoundWon{MatchID: ID.String(), Round: 1, Winner: player1, Loser: player2},
+				event.MatchWon{MatchID: ID.String(), Winner: player1, Loser: player2})...),
+			When(command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Rock)}),
+			ThenFailWith(match.ErrTheMatchHaveNotStartedOrFinished),
+		)
+	})
+}
+
+func matchCreated(ID domain.Identifier, bestOf int) []domain.DomainEvent {
+	return []domain.DomainEvent{
+		event.MatchCreated{
+			MatchID: ID.String(), FirstPlayer: player1, SecondPlayer: player2, BestOf: bestOf, RuleSet: "classic"},
+		event.RoundStarted{MatchID: ID.String(), Round: 1},
+	}
+}
+
+func createTestAggregate() *aggregate.Advanced {
+	matchAgg := match.NewAggregate(ksuid.New())
+
+	commandHandler := aggregate.NewCommandHandler()
+	commandHandler.RegisterHandlers(matchAgg)
+
+	eventApplier := aggregate.NewEventApplier()
+	eventApplier.RegisterAppliers(matchAgg)
+
+	return aggregate.NewAdvanced(matchAgg, commandHandler, eventApplier)
+}
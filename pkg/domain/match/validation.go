@@ -0,0 +1,18 @@
+package match
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+func validatePlayers(firstPlayer, secondPlayer string) error {
+	if err := domain.ValidateEmail("FirstPlayer", firstPlayer); err != nil {
+		return err
+	}
+
+	if err := domain.ValidateEmail("SecondPlayer", secondPlayer); err != nil {
+		return err
+	}
+
+	if firstPlayer == secondPlayer {
+		return domain.ValidationError{Field: "SecondPlayer", Reason: "must differ from the first player"}
+	}
+	return nil
+}
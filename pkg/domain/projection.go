@@ -0,0 +1,14 @@
+package domain
+
+// CheckpointStore keeps the global positions the projections have processed.
+//
+// LoadCheckpoint returns 0 if the projection has not processed any events yet.
+type CheckpointStore interface {
+	LoadCheckpoint(projection string) (int64, error)
+	StoreCheckpoint(projection string, position int64) error
+}
+
+// Resetter drops its state, so that it can be rebuilt from scratch.
+type Resetter interface {
+	Reset() error
+}
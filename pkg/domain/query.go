@@ -0,0 +1,34 @@
+package domain
+
+// Query is an object that is sent to the read side to fetch data.
+//
+// Queries never change state, they are named after the data they fetch, for example GetOrderSummary.
+type Query interface {
+	QueryType() string
+}
+
+// QueryHandler answers queries.
+type QueryHandler interface {
+	Handle(q Query) (interface{}, error)
+}
+
+// QueryHandlerFunc is a function that can be used as a query handler.
+type QueryHandlerFunc func(Query) (interface{}, error)
+
+// Handle implements QueryHandler interface.
+func (f QueryHandlerFunc) Handle(q Query) (interface{}, error) {
+	return f(q)
+}
+
+// ReadModelRepository stores the read models of a projection keyed by their IDs.
+//
+// Find returns false if there is no read model with the given ID.
+// FindAll returns the read models ordered by their IDs.
+// Clear removes all the read models, so that the projection can be rebuilt from scratch.
+type ReadModelRepository interface {
+	Find(ID string) (interface{}, bool, error)
+	FindAll() ([]interface{}, error)
+	Save(ID string, readModel interface{}) error
+	Remove(ID string) error
+	Clear() error
+}
@@ -0,0 +1,25 @@
+package domain
+
+// Saga is a long-running process which reacts to the events by issuing commands.
+//
+// Its state is exported and restored between the events, so it must be a Snapshotter.
+type Saga interface {
+	Snapshotter
+	Handle(e Envelope) ([]Command, error)
+}
+
+// SagaCompleter is a saga which knows when it is over.
+//
+// The state of a completed saga is deleted.
+type SagaCompleter interface {
+	Completed() bool
+}
+
+// SagaStore stores the state of the running sagas.
+//
+// LoadSagaState returns false if the saga hasn't been started yet.
+type SagaStore interface {
+	LoadSagaState(sagaType, sagaID string) (interface{}, bool, error)
+	StoreSagaState(sagaType, sagaID string, state interface{}) error
+	DeleteSagaState(sagaType, sagaID string) error
+}
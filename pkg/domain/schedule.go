@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// ScheduledCommand is a command which is due to be handled at the given time.
+//
+// The key identifies the schedule, so that it can be replaced or cancelled.
+// Attempts counts the failed deliveries of the command.
+type ScheduledCommand struct {
+	Key      string
+	Command  Command
+	DueAt    time.Time
+	Attempts int
+}
+
+// ScheduleStore keeps the pending scheduled commands.
+//
+// StoreSchedule replaces the schedule with the same key.
+// DueSchedules returns the commands due by the given time ordered by their due times.
+type ScheduleStore interface {
+	StoreSchedule(s ScheduledCommand) error
+	DueSchedules(now time.Time) ([]ScheduledCommand, error)
+	DeleteSchedule(key string) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+// SchemaVersioner is an event which knows the version of its schema.
+//
+// Events which don't implement it are considered to be of the first version.
+type SchemaVersioner interface {
+	SchemaVersion() int
+}
+
+// SchemaVersionOf returns the schema version of the given event.
+func SchemaVersionOf(e DomainEvent) int {
+	if v, ok := e.(SchemaVersioner); ok {
+		return v.SchemaVersion()
+	}
+	return 1
+}
+
+// Upcaster upgrades the stored events to their latest schema versions.
+//
+// A single event may be upcasted into several ones.
+type Upcaster interface {
+	Upcast(envelopes []Envelope) ([]Envelope, error)
+}
@@ -0,0 +1,30 @@
+package domain
+
+// Snapshot is a state of an aggregate at the given version.
+type Snapshot struct {
+	AggregateID   string
+	AggregateType string
+	Version       int
+	State         interface{}
+}
+
+// Snapshotter is an aggregate which can export its state and restore it later.
+type Snapshotter interface {
+	SnapshotState() (interface{}, error)
+	RestoreState(state interface{}) error
+}
+
+// SnapshotAggregate is an advanced aggregate which can be rehydrated from a snapshot.
+type SnapshotAggregate interface {
+	AdvancedAggregate
+	Snapshot() (Snapshot, error)
+	Restore(snapshot Snapshot) error
+}
+
+// SnapshotStore stores and loads aggregate snapshots.
+//
+// LoadSnapshot returns nil if there is no snapshot for the given aggregate.
+type SnapshotStore interface {
+	LoadSnapshot(aggregateID Identifier) (*Snapshot, error)
+	StoreSnapshot(snapshot Snapshot) error
+}
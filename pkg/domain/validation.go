@@ -0,0 +1,43 @@
+package domain
+
+import "net/mail"
+
+// ValidationError reports an invalid field of a command, e.g. to let the client know what to fix.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+// Error implements error interface.
+func (e ValidationError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+// ValidateIdentifier returns ValidationError if the identifier of the given field is not given.
+func ValidateIdentifier(field string, ID Identifier) error {
+	if ID == nil || ID.String() == "" {
+		return ValidationError{Field: field, Reason: "is required"}
+	}
+	return nil
+}
+
+// ValidateRequired returns ValidationError if the value of the given field is empty.
+func ValidateRequired(field, value string) error {
+	if value == "" {
+		return ValidationError{Field: field, Reason: "is required"}
+	}
+	return nil
+}
+
+// ValidateEmail returns ValidationError if the value of the given field is not a bare email address.
+func ValidateEmail(field, email string) error {
+	if err := ValidateRequired(field, email); err != nil {
+		return err
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return ValidationError{Field: field, Reason: "is not a valid email address"}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package event
+
+import "github.com/screwyprof/roshambo/pkg/domain"
+
+// All returns all the game and match events, it is handy to register them at once.
+func All() []domain.DomainEvent {
+	return []domain.DomainEvent{
+		GameCreated{},
+		PlayerInvited{},
+		InvitationDeclined{},
+		GameJoined{},
+		MoveDecided{},
+		MoveCommitted{},
+		MoveRevealed{},
+		PlayerPenalized{},
+		GameWon{},
+		GameTied{},
+		MatchCreated{},
+		RoundStarted{},
+		RoundMoveDecided{},
+		RoundWon{},
+		RoundTied{},
+		MatchWon{},
+	}
+}
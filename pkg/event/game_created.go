@@ -1,8 +1,10 @@
 package event
 
 type GameCreated struct {
-	GameID  string
-	Creator string
+	GameID   string
+	Creator  string
+	RuleSet  string
+	MoveMode string
 }
 
 func (c GameCreated) EventType() string {
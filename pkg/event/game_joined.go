@@ -0,0 +1,10 @@
+package event
+
+type GameJoined struct {
+	GameID      string
+	PlayerEmail string
+}
+
+func (c GameJoined) EventType() string {
+	return "GameJoined"
+}
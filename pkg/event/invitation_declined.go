@@ -0,0 +1,10 @@
+package event
+
+type InvitationDeclined struct {
+	GameID  string
+	Invitee string
+}
+
+func (c InvitationDeclined) EventType() string {
+	return "InvitationDeclined"
+}
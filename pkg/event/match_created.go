@@ -0,0 +1,13 @@
+package event
+
+type MatchCreated struct {
+	MatchID      string
+	FirstPlayer  string
+	SecondPlayer string
+	BestOf       int
+	RuleSet      string
+}
+
+func (c MatchCreated) EventType() string {
+	return "MatchCreated"
+}
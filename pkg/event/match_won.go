@@ -0,0 +1,11 @@
+package event
+
+type MatchWon struct {
+	MatchID string
+	Winner  string
+	Loser   string
+}
+
+func (c MatchWon) EventType() string {
+	return "MatchWon"
+}
@@ -0,0 +1,11 @@
+package event
+
+type MoveCommitted struct {
+	GameID      string
+	PlayerEmail string
+	Commitment  string
+}
+
+func (c MoveCommitted) EventType() string {
+	return "MoveCommitted"
+}
@@ -0,0 +1,11 @@
+package event
+
+type MoveRevealed struct {
+	GameID      string
+	PlayerEmail string
+	Move        int
+}
+
+func (c MoveRevealed) EventType() string {
+	return "MoveRevealed"
+}
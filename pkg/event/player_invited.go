@@ -0,0 +1,10 @@
+package event
+
+type PlayerInvited struct {
+	GameID  string
+	Invitee string
+}
+
+func (c PlayerInvited) EventType() string {
+	return "PlayerInvited"
+}
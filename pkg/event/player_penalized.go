@@ -0,0 +1,11 @@
+package event
+
+type PlayerPenalized struct {
+	GameID      string
+	PlayerEmail string
+	Reason      string
+}
+
+func (c PlayerPenalized) EventType() string {
+	return "PlayerPenalized"
+}
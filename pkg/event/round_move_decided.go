@@ -0,0 +1,12 @@
+package event
+
+type RoundMoveDecided struct {
+	MatchID     string
+	Round       int
+	PlayerEmail string
+	Move        int
+}
+
+func (c RoundMoveDecided) EventType() string {
+	return "RoundMoveDecided"
+}
@@ -0,0 +1,10 @@
+package event
+
+type RoundStarted struct {
+	MatchID string
+	Round   int
+}
+
+func (c RoundStarted) EventType() string {
+	return "RoundStarted"
+}
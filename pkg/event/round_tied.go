@@ -0,0 +1,10 @@
+package event
+
+type RoundTied struct {
+	MatchID string
+	Round   int
+}
+
+func (c RoundTied) EventType() string {
+	return "RoundTied"
+}
@@ -0,0 +1,12 @@
+package event
+
+type RoundWon struct {
+	MatchID string
+	Round   int
+	Winner  string
+	Loser   string
+}
+
+func (c RoundWon) EventType() string {
+	return "RoundWon"
+}
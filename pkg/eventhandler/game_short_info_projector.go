@@ -1,30 +1,55 @@
 package eventhandler
 
 import (
+	"github.com/screwyprof/roshambo/pkg/domain"
 	"github.com/screwyprof/roshambo/pkg/event"
 	"github.com/screwyprof/roshambo/pkg/report"
 )
 
+// GameShortInfoProjector keeps report.GameShortInfo of every game in the repository.
 type GameShortInfoProjector struct {
-	Projection *report.GameShortInfo
+	Repository domain.ReadModelRepository
+}
+
+func (p *GameShortInfoProjector) Reset() error {
+	return p.Repository.Clear()
 }
 
 func (p *GameShortInfoProjector) OnGameCreated(e event.GameCreated) error {
-	p.Projection.GameID = e.GameID
-	p.Projection.Creator = e.Creator
-	p.Projection.State = "created"
-	return nil
+	return p.Repository.Save(e.GameID, report.GameShortInfo{
+		GameID:  e.GameID,
+		Creator: e.Creator,
+		State:   "created",
+	})
 }
 
 func (p *GameShortInfoProjector) OnGameWon(e event.GameWon) error {
-	p.Projection.State = "game won"
-	p.Projection.Winner = e.Winner
-	p.Projection.Loser = e.Loser
+	info, err := p.find(e.GameID)
+	if err != nil {
+		return err
+	}
+
+	info.State = "game won"
+	info.Winner = e.Winner
+	info.Loser = e.Loser
 
-	return nil
+	return p.Repository.Save(e.GameID, info)
 }
 
 func (p *GameShortInfoProjector) OnGameTied(e event.GameTied) error {
-	p.Projection.State = "game tied"
-	return nil
+	info, err := p.find(e.GameID)
+	if err != nil {
+		return err
+	}
+
+	info.State = "game tied"
+	return p.Repository.Save(e.GameID, info)
+}
+
+func (p *GameShortInfoProjector) find(gameID string) (report.GameShortInfo, error) {
+	readModel, ok, err := p.Repository.Find(gameID)
+	if err != nil || !ok {
+		return report.GameShortInfo{GameID: gameID}, err
+	}
+	return readModel.(report.GameShortInfo), nil
 }
@@ -0,0 +1,70 @@
+package eventhandler
+
+import (
+	"github.com/screwyprof/roshambo/pkg/domain"
+	"github.com/screwyprof/roshambo/pkg/event"
+	"github.com/screwyprof/roshambo/pkg/report"
+)
+
+// MatchScoreProjector keeps report.MatchScore of every match in the repository.
+type MatchScoreProjector struct {
+	Repository domain.ReadModelRepository
+}
+
+func (p *MatchScoreProjector) Reset() error {
+	return p.Repository.Clear()
+}
+
+func (p *MatchScoreProjector) OnMatchCreated(e event.MatchCreated) error {
+	return p.Repository.Save(e.MatchID, report.MatchScore{
+		MatchID:      e.MatchID,
+		FirstPlayer:  e.FirstPlayer,
+		SecondPlayer: e.SecondPlayer,
+		BestOf:       e.BestOf,
+		State:        "created",
+	})
+}
+
+func (p *MatchScoreProjector) OnRoundStarted(e event.RoundStarted) error {
+	score, err := p.find(e.MatchID)
+	if err != nil {
+		return err
+	}
+
+	score.State = "playing"
+	score.Round = e.Round
+	return p.Repository.Save(e.MatchID, score)
+}
+
+func (p *MatchScoreProjector) OnRoundWon(e event.RoundWon) error {
+	score, err := p.find(e.MatchID)
+	if err != nil {
+		return err
+	}
+
+	if e.Winner == score.FirstPlayer {
+		score.FirstPlayerScore++
+	} else {
+		score.SecondPlayerScore++
+	}
+	return p.Repository.Save(e.MatchID, score)
+}
+
+func (p *MatchScoreProjector) OnMatchWon(e event.MatchWon) error {
+	score, err := p.find(e.MatchID)
+	if err != nil {
+		return err
+	}
+
+	score.State = "match won"
+	score.Winner = e.Winner
+	return p.Repository.Save(e.MatchID, score)
+}
+
+func (p *MatchScoreProjector) find(matchID string) (report.MatchScore, error) {
+	readModel, ok, err := p.Repository.Find(matchID)
+	if err != nil || !ok {
+		return report.MatchScore{MatchID: matchID}, err
+	}
+	return readModel.(report.MatchScore), nil
+}
@@ -0,0 +1,9 @@
+package query
+
+type GetGameShortInfo struct {
+	GameID string
+}
+
+func (q GetGameShortInfo) QueryType() string {
+	return "GetGameShortInfo"
+}
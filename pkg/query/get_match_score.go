@@ -0,0 +1,9 @@
+package query
+
+type GetMatchScore struct {
+	MatchID string
+}
+
+func (q GetMatchScore) QueryType() string {
+	return "GetMatchScore"
+}
@@ -0,0 +1,36 @@
+package queryhandler
+
+import (
+	"errors"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+	"github.com/screwyprof/roshambo/pkg/query"
+	"github.com/screwyprof/roshambo/pkg/report"
+)
+
+var (
+	ErrGameIsNotFound      = errors.New("game is not found")
+	ErrQueryIsNotSupported = errors.New("query is not supported")
+)
+
+// GameShortInfoFinder answers query.GetGameShortInfo with report.GameShortInfo.
+type GameShortInfoFinder struct {
+	Repository domain.ReadModelRepository
+}
+
+func (f *GameShortInfoFinder) Handle(q domain.Query) (interface{}, error) {
+	getInfo, ok := q.(query.GetGameShortInfo)
+	if !ok {
+		return nil, ErrQueryIsNotSupported
+	}
+
+	readModel, ok, err := f.Repository.Find(getInfo.GameID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrGameIsNotFound
+	}
+
+	return readModel.(report.GameShortInfo), nil
+}
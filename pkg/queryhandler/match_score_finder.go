@@ -0,0 +1,33 @@
+package queryhandler
+
+import (
+	"errors"
+
+	"github.com/screwyprof/roshambo/pkg/domain"
+	"github.com/screwyprof/roshambo/pkg/query"
+	"github.com/screwyprof/roshambo/pkg/report"
+)
+
+var ErrMatchIsNotFound = errors.New("match is not found")
+
+// MatchScoreFinder answers query.GetMatchScore with report.MatchScore.
+type MatchScoreFinder struct {
+	Repository domain.ReadModelRepository
+}
+
+func (f *MatchScoreFinder) Handle(q domain.Query) (interface{}, error) {
+	getScore, ok := q.(query.GetMatchScore)
+	if !ok {
+		return nil, ErrQueryIsNotSupported
+	}
+
+	readModel, ok, err := f.Repository.Find(getScore.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrMatchIsNotFound
+	}
+
+	return readModel.(report.MatchScore), nil
+}
@@ -0,0 +1,13 @@
+package report
+
+type MatchScore struct {
+	MatchID           string
+	FirstPlayer       string
+	SecondPlayer      string
+	FirstPlayerScore  int
+	SecondPlayerScore int
+	BestOf            int
+	Round             int
+	State             string
+	Winner            string
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventbus"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventhandler"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/querydispatcher"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/readmodel"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/store"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/testdata/mock"
 
@@ -16,19 +18,29 @@ import (
 	"github.com/screwyprof/roshambo/pkg/domain"
 	"github.com/screwyprof/roshambo/pkg/domain/game"
 	gameEventHandler "github.com/screwyprof/roshambo/pkg/eventhandler"
+	"github.com/screwyprof/roshambo/pkg/query"
+	"github.com/screwyprof/roshambo/pkg/queryhandler"
 	"github.com/screwyprof/roshambo/pkg/report"
 )
 
 func Example() {
 	ID := mock.StringIdentifier("TestGame")
-	gameInfo := report.GameShortInfo{}
-	d := createDispatcher(&gameInfo)
+	gameInfos := readmodel.NewInMemoryRepository()
+	d := createDispatcher(gameInfos)
 
 	failOnError(d.Handle(command.CreateNewGame{GameID: ID, Creator: "tiger@happy"}))
+	failOnError(d.Handle(command.InvitePlayer{GameID: ID, Inviter: "tiger@happy", Invitee: "gopher@happy"}))
+	failOnError(d.Handle(command.JoinGame{GameID: ID, PlayerEmail: "gopher@happy"}))
 	failOnError(d.Handle(command.MakeMove{GameID: ID, PlayerEmail: "gopher@happy", Move: int(game.Rock)}))
 	failOnError(d.Handle(command.MakeMove{GameID: ID, PlayerEmail: "tiger@happy", Move: int(game.Scissors)}))
 
-	printGameInfo(gameInfo)
+	queries := querydispatcher.NewDispatcher()
+	queries.Register("GetGameShortInfo", &queryhandler.GameShortInfoFinder{Repository: gameInfos})
+
+	gameInfo, err := queries.Handle(query.GetGameShortInfo{GameID: ID.String()})
+	failOnError(nil, err)
+
+	printGameInfo(gameInfo.(report.GameShortInfo))
 	// Output:
 	// Game Info
 	// Status: game won
@@ -43,9 +55,9 @@ func printGameInfo(gameInfo report.GameShortInfo) {
 	fmt.Println("Winner:", gameInfo.Winner)
 }
 
-func createDispatcher(gameInfo *report.GameShortInfo) *dispatcher.Dispatcher {
+func createDispatcher(gameInfos domain.ReadModelRepository) *dispatcher.Dispatcher {
 	gameInfoProjector := eventhandler.New()
-	gameInfoProjector.RegisterHandlers(&gameEventHandler.GameShortInfoProjector{Projection: gameInfo})
+	gameInfoProjector.RegisterHandlers(&gameEventHandler.GameShortInfoProjector{Repository: gameInfos})
 
 	f := aggregate.NewFactory()
 	f.RegisterAggregate(func(ID domain.Identifier) domain.AdvancedAggregate {
@@ -1,24 +1,48 @@
 package game
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/segmentio/ksuid"
 
 	"github.com/screwyprof/roshambo/internal/pkg/assert"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/aggregate"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/checkpointstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/dedupstore"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/dispatcher"
 	. "github.com/screwyprof/roshambo/internal/pkg/cqrs/dispatcher/testdata/fixture"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventbus"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventhandler"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/middleware"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/outbox"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/projection"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/querydispatcher"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/readmodel"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/retry"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/saga"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/sagastore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/scheduler"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/schedulestore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/serializer"
 	"github.com/screwyprof/roshambo/internal/pkg/cqrs/store"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/subscription"
 
 	"github.com/screwyprof/roshambo/pkg/command"
 	"github.com/screwyprof/roshambo/pkg/domain"
 	"github.com/screwyprof/roshambo/pkg/domain/game"
 	"github.com/screwyprof/roshambo/pkg/event"
 	gameEventHandler "github.com/screwyprof/roshambo/pkg/eventhandler"
+	"github.com/screwyprof/roshambo/pkg/query"
+	"github.com/screwyprof/roshambo/pkg/queryhandler"
 	"github.com/screwyprof/roshambo/pkg/report"
 )
 
@@ -27,7 +51,7 @@ func TestVictory(t *testing.T) {
 	player1 := "tom@game.net"
 	player2 := "jerry@game.net"
 
-	got := report.GameShortInfo{}
+	gameInfos := readmodel.NewInMemoryRepository()
 	want := report.GameShortInfo{
 		GameID:  ID.String(),
 		Creator: player1,
@@ -37,20 +61,24 @@ func TestVictory(t *testing.T) {
 	}
 
 	Test(t)(
-		Given(createDispatcher(&got)),
+		Given(createDispatcher(gameInfos)),
 		When(
 			command.CreateNewGame{GameID: ID, Creator: player1},
+			command.JoinGame{GameID: ID, PlayerEmail: player2},
 			command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock)},
 			command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper)},
 		),
 		Then(
-			event.GameCreated{GameID: ID.String(), Creator: player1},
+			event.GameCreated{GameID: ID.String(), Creator: player1, RuleSet: "classic", MoveMode: "open"},
+			event.GameJoined{GameID: ID.String(), PlayerEmail: player2},
 			event.MoveDecided{GameID: ID.String(), PlayerEmail: player1, Move: int(game.Rock)},
 			event.MoveDecided{GameID: ID.String(), PlayerEmail: player2, Move: int(game.Paper)},
 			event.GameWon{GameID: ID.String(), Winner: player2, Loser: player1},
 		),
 	)
 
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
 	assert.Equals(t, want, got)
 }
 
@@ -59,7 +87,7 @@ func TestTie(t *testing.T) {
 	player1 := "tom@game.net"
 	player2 := "jerry@game.net"
 
-	got := report.GameShortInfo{}
+	gameInfos := readmodel.NewInMemoryRepository()
 	want := report.GameShortInfo{
 		GameID:  ID.String(),
 		Creator: player2,
@@ -67,27 +95,764 @@ func TestTie(t *testing.T) {
 	}
 
 	Test(t)(
-		Given(createDispatcher(&got)),
+		Given(createDispatcher(gameInfos)),
 		When(
 			command.CreateNewGame{GameID: ID, Creator: player2},
+			command.InvitePlayer{GameID: ID, Inviter: player2, Invitee: player1},
+			command.JoinGame{GameID: ID, PlayerEmail: player1},
 			command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Scissors)},
 			command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Scissors)},
 		),
 		Then(
-			event.GameCreated{GameID: ID.String(), Creator: player2},
+			event.GameCreated{GameID: ID.String(), Creator: player2, RuleSet: "classic", MoveMode: "open"},
+			event.PlayerInvited{GameID: ID.String(), Invitee: player1},
+			event.GameJoined{GameID: ID.String(), PlayerEmail: player1},
 			event.MoveDecided{GameID: ID.String(), PlayerEmail: player1, Move: int(game.Scissors)},
 			event.MoveDecided{GameID: ID.String(), PlayerEmail: player2, Move: int(game.Scissors)},
 			event.GameTied{GameID: ID.String()},
 		),
 	)
 
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
 	assert.Equals(t, want, got)
 }
 
-func createDispatcher(gameInfo *report.GameShortInfo) *dispatcher.Dispatcher {
+func TestRPSLSGame(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	Test(t)(
+		Given(createDispatcher(readmodel.NewInMemoryRepository())),
+		When(
+			command.CreateNewGame{GameID: ID, Creator: player1, RuleSet: "rpsls"},
+			command.JoinGame{GameID: ID, PlayerEmail: player2},
+			command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Spock)},
+			command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Lizard)},
+		),
+		Then(
+			event.GameCreated{GameID: ID.String(), Creator: player1, RuleSet: "rpsls", MoveMode: "open"},
+			event.GameJoined{GameID: ID.String(), PlayerEmail: player2},
+			event.MoveDecided{GameID: ID.String(), PlayerEmail: player1, Move: int(game.Spock)},
+			event.MoveDecided{GameID: ID.String(), PlayerEmail: player2, Move: int(game.Lizard)},
+			event.GameWon{GameID: ID.String(), Winner: player2, Loser: player1},
+		),
+	)
+}
+
+func TestInvalidMoveIsRejected(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	Test(t)(
+		Given(createDispatcher(readmodel.NewInMemoryRepository())),
+		When(
+			command.CreateNewGame{GameID: ID, Creator: player1},
+			command.JoinGame{GameID: ID, PlayerEmail: player2},
+			command.MakeMove{GameID: ID, PlayerEmail: player1, Move: 42},
+		),
+		ThenFailWith(domain.ValidationError{Field: "Move", Reason: "is not in the rule set"}),
+	)
+}
+
+func TestOnlyParticipantsCanMove(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+	player3 := "spike@game.net"
+
+	d := createDispatcher(readmodel.NewInMemoryRepository())
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.InvitePlayer{GameID: ID, Inviter: player1, Invitee: player3})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.DeclineInvitation{GameID: ID, PlayerEmail: player3})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.InvitePlayer{GameID: ID, Inviter: player1, Invitee: player2})
+	assert.Ok(t, err)
+
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: player3})
+	assert.Equals(t, game.ErrPlayerIsNotInvited, err)
+
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player3, Move: int(game.Rock)})
+	assert.Equals(t, game.ErrPlayerIsNotAParticipant, err)
+}
+
+func TestCommittedMovesAreRevealed(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+	commitment1 := game.Commit(game.Scissors, "tom's secret salt")
+	commitment2 := game.Commit(game.Paper, "jerry's secret salt")
+
+	gameInfos := readmodel.NewInMemoryRepository()
+	want := report.GameShortInfo{
+		GameID:  ID.String(),
+		Creator: player1,
+		State:   "game won",
+		Winner:  player1,
+		Loser:   player2,
+	}
+
+	Test(t)(
+		Given(createDispatcher(gameInfos)),
+		When(
+			command.CreateNewGame{GameID: ID, Creator: player1, MoveMode: "commit-reveal"},
+			command.JoinGame{GameID: ID, PlayerEmail: player2},
+			command.CommitMove{GameID: ID, PlayerEmail: player1, Commitment: commitment1},
+			command.CommitMove{GameID: ID, PlayerEmail: player2, Commitment: commitment2},
+			command.RevealMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper), Salt: "jerry's secret salt"},
+			command.RevealMove{GameID: ID, PlayerEmail: player1, Move: int(game.Scissors), Salt: "tom's secret salt"},
+		),
+		Then(
+			event.GameCreated{GameID: ID.String(), Creator: player1, RuleSet: "classic", MoveMode: "commit-reveal"},
+			event.GameJoined{GameID: ID.String(), PlayerEmail: player2},
+			event.MoveCommitted{GameID: ID.String(), PlayerEmail: player1, Commitment: commitment1},
+			event.MoveCommitted{GameID: ID.String(), PlayerEmail: player2, Commitment: commitment2},
+			event.MoveRevealed{GameID: ID.String(), PlayerEmail: player2, Move: int(game.Paper)},
+			event.MoveRevealed{GameID: ID.String(), PlayerEmail: player1, Move: int(game.Scissors)},
+			event.GameWon{GameID: ID.String(), Winner: player1, Loser: player2},
+		),
+	)
+
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, want, got)
+}
+
+func TestMismatchedRevealIsPenalized(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+	salt1 := "tom's secret salt"
+	salt2 := "jerry's secret salt"
+
+	gameInfos := readmodel.NewInMemoryRepository()
+	want := report.GameShortInfo{
+		GameID:  ID.String(),
+		Creator: player1,
+		State:   "game won",
+		Winner:  player1,
+		Loser:   player2,
+	}
+
+	d := createDispatcher(gameInfos)
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: player1, MoveMode: "commit-reveal"})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.CommitMove{GameID: ID, PlayerEmail: player1, Commitment: game.Commit(game.Rock, salt1)})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.CommitMove{GameID: ID, PlayerEmail: player2, Commitment: game.Commit(game.Rock, salt2)})
+	assert.Ok(t, err)
+
+	_, err = d.Handle(command.RevealMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper), Salt: salt2})
+	assert.Ok(t, err)
+
+	_, err = d.Handle(command.RevealMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock), Salt: salt1})
+	assert.Equals(t, game.ErrTheGameHaveNotStartedOrFinished, err)
+
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, want, got)
+}
+
+func TestProjectionIsUpdatedAsynchronously(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	gameInfos := readmodel.NewInMemoryRepository()
+	gameInfoProjector := eventhandler.New()
+	gameInfoProjector.RegisterHandlers(&gameEventHandler.GameShortInfoProjector{Repository: gameInfos})
+
+	eventBus := eventbus.NewAsyncEventBus()
+	eventBus.Register(gameInfoProjector)
+	d := dispatcher.NewDispatcher(store.NewStore(eventstore.NewInInMemoryEventStore(), createAggregateFactory()), eventBus)
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Paper)})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Rock)})
+	assert.Ok(t, err)
+	eventBus.Close()
+
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, report.GameShortInfo{
+		GameID:  ID.String(),
+		Creator: player1,
+		State:   "game won",
+		Winner:  player1,
+		Loser:   player2,
+	}, got)
+}
+
+func TestMiddlewaresAreComposedAroundTheDispatcher(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	errNotTheCreator := errors.New("games can only be created by the player themselves")
+
+	gameInfos := readmodel.NewInMemoryRepository()
+	authorize := func(ctx context.Context, c domain.Command) error {
+		principal, _ := domain.PrincipalFrom(ctx)
+		if cmd, ok := c.(command.CreateNewGame); ok && cmd.Creator != principal {
+			return errNotTheCreator
+		}
+		return nil
+	}
+	h := middleware.Chain(
+		createDispatcher(gameInfos),
+		middleware.Recovery(),
+		middleware.Validation(),
+		middleware.Authorization(authorize),
+	)
+
+	ctx := domain.WithPrincipal(context.Background(), "guest@game.net")
+	_, err := h.HandleContext(ctx, command.CreateNewGame{GameID: ID, Creator: "guest"})
+	assert.Equals(t, domain.ValidationError{Field: "Creator", Reason: "is not a valid email address"}, err)
+
+	_, err = h.HandleContext(ctx, command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Equals(t, errNotTheCreator, err)
+	_, err = findGameShortInfo(gameInfos, ID)
+	assert.Equals(t, queryhandler.ErrGameIsNotFound, err)
+
+	ctx = domain.WithPrincipal(context.Background(), player1)
+	_, err = h.HandleContext(ctx, command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, report.GameShortInfo{GameID: ID.String(), Creator: player1, State: "created"}, got)
+}
+
+func TestSagasAndSchedulesAreDrivenThroughTheMiddlewares(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	es := eventstore.NewInInMemoryEventStore()
+	eventBus := eventbus.NewInMemoryEventBus()
+	d := dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), eventBus)
+
+	var handled []string
+	record := func(ctx context.Context, c domain.Command) error {
+		_, correlated := domain.CorrelationIDFrom(ctx)
+		handled = append(handled, fmt.Sprintf("%s correlated: %t", c.CommandType(), correlated))
+		return nil
+	}
+	h := domain.CommandHandlerOf(middleware.Chain(d, middleware.Validation(), middleware.Authorization(record)))
+
+	eventBus.Register(saga.NewManager(
+		"rematch",
+		func(string) domain.Saga { return &rematchSaga{} },
+		domain.MatchEvent("GameWon"),
+		sagastore.NewInMemorySagaStore(),
+		h,
+	))
+
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := scheduler.New(schedulestore.NewInMemoryScheduleStore(), h, scheduler.WithClock(scheduler.ClockFunc(
+		func() time.Time {
+			return now
+		})))
+
+	ctx := domain.WithCorrelationID(context.Background(), "series")
+	_, err := d.HandleContext(ctx, command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.HandleContext(ctx, command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+	_, err = d.HandleContext(ctx, command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock)})
+	assert.Ok(t, err)
+
+	move := command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper)}
+	assert.Ok(t, s.ScheduleAfter("move/"+player2, move, time.Second))
+	now = now.Add(time.Second)
+	assert.Ok(t, s.DeliverDue())
+
+	assert.Equals(t, []string{"MakeMove correlated: false", "CreateNewGame correlated: true"}, handled)
+}
+
+func TestCancelledCommandIsNotHandled(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+
+	gameInfos := readmodel.NewInMemoryRepository()
+	d := createDispatcher(gameInfos)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := d.HandleContext(ctx, command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Equals(t, context.Canceled, err)
+
+	_, err = d.HandleContext(context.Background(), command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, report.GameShortInfo{GameID: ID.String(), Creator: player1, State: "created"}, got)
+}
+
+func TestEventsArePublishedThroughTheOutbox(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+
+	gameInfos := readmodel.NewInMemoryRepository()
 	gameInfoProjector := eventhandler.New()
-	gameInfoProjector.RegisterHandlers(&gameEventHandler.GameShortInfoProjector{Projection: gameInfo})
+	gameInfoProjector.RegisterHandlers(&gameEventHandler.GameShortInfoProjector{Repository: gameInfos})
+
+	eventBus := eventbus.NewInMemoryEventBus()
+	eventBus.Register(gameInfoProjector)
+
+	es := eventstore.NewInInMemoryEventStore()
+	relay := outbox.NewRelay(es, eventBus)
+	d := dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), relay)
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = findGameShortInfo(gameInfos, ID)
+	assert.Equals(t, queryhandler.ErrGameIsNotFound, err)
+
+	assert.Ok(t, relay.Flush())
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, report.GameShortInfo{GameID: ID.String(), Creator: player1, State: "created"}, got)
+}
+
+func TestGameIsPersisted(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	dir, err := ioutil.TempDir("", "roshambo")
+	assert.Ok(t, err)
+	defer os.RemoveAll(dir)
+
+	registry := serializer.NewRegistry()
+	registry.Register(event.All()...)
+	eventSerializer := serializer.NewSerializer(registry, serializer.GobCodec{})
+
+	es, err := eventstore.NewFileEventStore(dir, eventSerializer)
+	assert.Ok(t, err)
+
+	Test(t)(
+		Given(dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), eventbus.NewInMemoryEventBus())),
+		When(
+			command.CreateNewGame{GameID: ID, Creator: player1},
+			command.JoinGame{GameID: ID, PlayerEmail: player2},
+			command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock)},
+		),
+		Then(
+			event.GameCreated{GameID: ID.String(), Creator: player1, RuleSet: "classic", MoveMode: "open"},
+			event.GameJoined{GameID: ID.String(), PlayerEmail: player2},
+			event.MoveDecided{GameID: ID.String(), PlayerEmail: player1, Move: int(game.Rock)},
+		),
+	)
+	assert.Ok(t, es.Close())
 
+	es, err = eventstore.NewFileEventStore(dir, eventSerializer)
+	assert.Ok(t, err)
+	defer es.Close()
+
+	Test(t)(
+		Given(dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), eventbus.NewInMemoryEventBus())),
+		When(command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper)}),
+		Then(
+			event.MoveDecided{GameID: ID.String(), PlayerEmail: player2, Move: int(game.Paper)},
+			event.GameWon{GameID: ID.String(), Winner: player2, Loser: player1},
+		),
+	)
+}
+
+func TestLateProjectorCatchesUp(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	es := eventstore.NewInInMemoryEventStore()
+	eventBus := eventbus.NewInMemoryEventBus()
+	d := dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), eventBus)
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock)})
+	assert.Ok(t, err)
+
+	gameInfos := readmodel.NewInMemoryRepository()
+	gameInfoProjector := eventhandler.New()
+	gameInfoProjector.RegisterHandlers(&gameEventHandler.GameShortInfoProjector{Repository: gameInfos})
+
+	s := subscription.New(es, gameInfoProjector)
+	eventBus.Register(s)
+	assert.Ok(t, s.CatchUp())
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, report.GameShortInfo{GameID: ID.String(), Creator: player1, State: "created"}, got)
+
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper)})
+	assert.Ok(t, err)
+	assert.Ok(t, s.CatchUp())
+
+	got, err = findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, report.GameShortInfo{
+		GameID:  ID.String(),
+		Creator: player1,
+		State:   "game won",
+		Winner:  player2,
+		Loser:   player1,
+	}, got)
+	assert.Equals(t, int64(5), s.Position())
+}
+
+func TestProjectionIsRebuilt(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	es := eventstore.NewInInMemoryEventStore()
+	d := dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), eventbus.NewInMemoryEventBus())
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock)})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Rock)})
+	assert.Ok(t, err)
+
+	dir, err := ioutil.TempDir("", "roshambo")
+	assert.Ok(t, err)
+	defer os.RemoveAll(dir)
+
+	checkpoints, err := checkpointstore.NewFileCheckpointStore(filepath.Join(dir, "checkpoints.json"))
+	assert.Ok(t, err)
+
+	gameInfos := readmodel.NewInMemoryRepository()
+	gameInfoProjector := eventhandler.New()
+	gameInfoProjector.RegisterHandlers(&gameEventHandler.GameShortInfoProjector{Repository: gameInfos})
+
+	r := projection.NewRunner("GameShortInfo", es, gameInfoProjector, checkpoints)
+	assert.Ok(t, r.CatchUp())
+
+	want := report.GameShortInfo{GameID: ID.String(), Creator: player1, State: "game tied"}
+	got, err := findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, want, got)
+
+	assert.Ok(t, gameInfos.Save(ID.String(), report.GameShortInfo{GameID: ID.String(), State: "corrupted"}))
+	assert.Ok(t, r.Rebuild())
+	got, err = findGameShortInfo(gameInfos, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, want, got)
+
+	checkpoints, err = checkpointstore.NewFileCheckpointStore(filepath.Join(dir, "checkpoints.json"))
+	assert.Ok(t, err)
+	position, err := checkpoints.LoadCheckpoint("GameShortInfo")
+	assert.Ok(t, err)
+	assert.Equals(t, int64(5), position)
+}
+
+func TestConcurrentMoves(t *testing.T) {
+	const players = 50
+
+	ID := ksuid.New()
+	es := eventstore.NewInInMemoryEventStore()
+	d := dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), eventbus.NewInMemoryEventBus())
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: "player0@game.net"})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: "player1@game.net"})
+	assert.Ok(t, err)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		failures  []error
+	)
+
+	wg.Add(players)
+	for i := 0; i < players; i++ {
+		go func(i int) {
+			defer wg.Done()
+			_, err := d.Handle(command.MakeMove{
+				GameID:      ID,
+				PlayerEmail: fmt.Sprintf("player%d@game.net", i%2),
+				Move:        i % 3,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, err)
+				return
+			}
+			succeeded++
+		}(i)
+	}
+	wg.Wait()
+
+	events, err := es.LoadEventsFor(ID)
+	assert.Ok(t, err)
+
+	assert.Equals(t, 2, succeeded)
+	assert.Equals(t, 5, len(events))
+	assert.Equals(t, "GameCreated", events[0].Event.EventType())
+	assert.Equals(t, "GameJoined", events[1].Event.EventType())
+	assert.Equals(t, "MoveDecided", events[2].Event.EventType())
+	assert.Equals(t, "MoveDecided", events[3].Event.EventType())
+	assert.True(t, events[2].Event.(event.MoveDecided).PlayerEmail != events[3].Event.(event.MoveDecided).PlayerEmail)
+	assert.True(t, events[4].Event.EventType() == "GameWon" || events[4].Event.EventType() == "GameTied")
+
+	for _, err := range failures {
+		assert.True(t, err == domain.ErrConcurrencyViolation ||
+			err == game.ErrTheGameHaveNotStartedOrFinished || err == game.ErrPlayerIsTheSame)
+	}
+}
+
+func TestConcurrentMovesAreRetriedOnConflicts(t *testing.T) {
+	const players = 10
+
+	ID := ksuid.New()
+	es := eventstore.NewInInMemoryEventStore()
+	d := dispatcher.NewDispatcher(
+		store.NewStore(es, createAggregateFactory()),
+		eventbus.NewInMemoryEventBus(),
+		dispatcher.WithConflictRetry(retry.Policy{MaxAttempts: players, InitialDelay: time.Millisecond, Jitter: 1}),
+	)
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: "player0@game.net"})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: "player1@game.net"})
+	assert.Ok(t, err)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []error
+	)
+
+	wg.Add(players)
+	for i := 0; i < players; i++ {
+		go func(i int) {
+			defer wg.Done()
+			_, err := d.Handle(command.MakeMove{
+				GameID:      ID,
+				PlayerEmail: fmt.Sprintf("player%d@game.net", i%2),
+				Move:        i % 3,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	events, err := es.LoadEventsFor(ID)
+	assert.Ok(t, err)
+	assert.Equals(t, 5, len(events))
+
+	assert.Equals(t, players-2, len(failures))
+	for _, err := range failures {
+		assert.True(t, err == game.ErrTheGameHaveNotStartedOrFinished || err == game.ErrPlayerIsTheSame)
+	}
+}
+
+func TestRetriedMoveIsNotMadeTwice(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+
+	d := dispatcher.NewDispatcher(
+		store.NewStore(eventstore.NewInInMemoryEventStore(), createAggregateFactory()),
+		eventbus.NewInMemoryEventBus(),
+		dispatcher.WithDeduplication(dedupstore.NewInMemoryDedupStore()),
+	)
+
+	_, err := d.Handle(command.CreateNewGame{ID: "create", GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{ID: "join", GameID: ID, PlayerEmail: "jerry@game.net"})
+	assert.Ok(t, err)
+
+	move := command.MakeMove{ID: "move", GameID: ID, PlayerEmail: player1, Move: int(game.Rock)}
+	want := []domain.DomainEvent{event.MoveDecided{GameID: ID.String(), PlayerEmail: player1, Move: int(game.Rock)}}
+
+	got, err := d.Handle(move)
+	assert.Ok(t, err)
+	assert.Equals(t, want, got)
+
+	got, err = d.Handle(move)
+	assert.Ok(t, err)
+	assert.Equals(t, want, got)
+}
+
+func TestCachedGameIsPlayedToTheEnd(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	es := eventstore.NewInInMemoryEventStore()
+	d := dispatcher.NewDispatcher(
+		store.NewStore(es, createAggregateFactory(), store.WithCache(8)),
+		eventbus.NewInMemoryEventBus(),
+	)
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock)})
+	assert.Ok(t, err)
+
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Paper)})
+	assert.Equals(t, game.ErrPlayerIsTheSame, err)
+
+	_, err = d.Handle(command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper)})
+	assert.Ok(t, err)
+
+	events, err := es.LoadEventsFor(ID)
+	assert.Ok(t, err)
+	assert.Equals(t, []domain.DomainEvent{
+		event.GameCreated{GameID: ID.String(), Creator: player1, RuleSet: "classic", MoveMode: "open"},
+		event.GameJoined{GameID: ID.String(), PlayerEmail: player2},
+		event.MoveDecided{GameID: ID.String(), PlayerEmail: player1, Move: int(game.Rock)},
+		event.MoveDecided{GameID: ID.String(), PlayerEmail: player2, Move: int(game.Paper)},
+		event.GameWon{GameID: ID.String(), Winner: player2, Loser: player1},
+	}, domain.EventsOf(events))
+}
+
+func TestRematchIsCreatedBySaga(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	es := eventstore.NewInInMemoryEventStore()
+	eventBus := eventbus.NewInMemoryEventBus()
+	d := dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), eventBus)
+
+	eventBus.Register(saga.NewManager(
+		"rematch",
+		func(string) domain.Saga { return &rematchSaga{} },
+		domain.MatchEvent("GameWon"),
+		sagastore.NewInMemorySagaStore(),
+		d,
+	))
+
+	ctx := domain.WithCorrelationID(context.Background(), "series")
+	_, err := d.HandleContext(ctx, command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.HandleContext(ctx, command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+	_, err = d.HandleContext(ctx, command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock)})
+	assert.Ok(t, err)
+	_, err = d.HandleContext(ctx, command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper)})
+	assert.Ok(t, err)
+
+	events, err := es.LoadAllEventsFrom(0, 0)
+	assert.Ok(t, err)
+	assert.Equals(t, 6, len(events))
+
+	rematch := events[5]
+	assert.Equals(t, "series", rematch.CorrelationID)
+	assert.Equals(t, events[4].ID, rematch.CausationID)
+	want := event.GameCreated{GameID: rematch.AggregateID, Creator: player1, RuleSet: "classic", MoveMode: "open"}
+	assert.Equals(t, want, rematch.Event)
+}
+
+func TestScheduledMoveIsMadeWhenDue(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	es := eventstore.NewInInMemoryEventStore()
+	d := dispatcher.NewDispatcher(store.NewStore(es, createAggregateFactory()), eventbus.NewInMemoryEventBus())
+
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := scheduler.ClockFunc(func() time.Time {
+		return now
+	})
+	s := scheduler.New(schedulestore.NewInMemoryScheduleStore(), d, scheduler.WithClock(clock))
+
+	_, err := d.Handle(command.CreateNewGame{GameID: ID, Creator: player1})
+	assert.Ok(t, err)
+	_, err = d.Handle(command.JoinGame{GameID: ID, PlayerEmail: player2})
+	assert.Ok(t, err)
+
+	move1 := command.MakeMove{GameID: ID, PlayerEmail: player1, Move: int(game.Rock)}
+	assert.Ok(t, s.ScheduleAfter("move/"+player1, move1, time.Second))
+	move2 := command.MakeMove{GameID: ID, PlayerEmail: player2, Move: int(game.Paper)}
+	assert.Ok(t, s.ScheduleAfter("move/"+player2, move2, time.Minute))
+	assert.Ok(t, s.Cancel("move/"+player2))
+
+	now = now.Add(time.Minute)
+	assert.Ok(t, s.DeliverDue())
+
+	events, err := es.LoadEventsFor(ID)
+	assert.Ok(t, err)
+	assert.Equals(t, []domain.DomainEvent{
+		event.GameCreated{GameID: ID.String(), Creator: player1, RuleSet: "classic", MoveMode: "open"},
+		event.GameJoined{GameID: ID.String(), PlayerEmail: player2},
+		event.MoveDecided{GameID: ID.String(), PlayerEmail: player1, Move: int(game.Rock)},
+	}, domain.EventsOf(events))
+}
+
+// rematchSaga offers the loser a rematch once.
+type rematchSaga struct {
+	offered bool
+}
+
+func (s *rematchSaga) Handle(e domain.Envelope) ([]domain.Command, error) {
+	if s.offered {
+		return nil, nil
+	}
+
+	s.offered = true
+	return []domain.Command{command.CreateNewGame{GameID: ksuid.New(), Creator: e.Event.(event.GameWon).Loser}}, nil
+}
+
+func (s *rematchSaga) SnapshotState() (interface{}, error) {
+	return s.offered, nil
+}
+
+func (s *rematchSaga) RestoreState(state interface{}) error {
+	s.offered = state.(bool)
+	return nil
+}
+
+func createDispatcher(gameInfos domain.ReadModelRepository) *dispatcher.Dispatcher {
+	gameInfoProjector := eventhandler.New()
+	gameInfoProjector.RegisterHandlers(&gameEventHandler.GameShortInfoProjector{Repository: gameInfos})
+
+	aggregateStore := store.NewStore(eventstore.NewInInMemoryEventStore(), createAggregateFactory())
+	eventBus := eventbus.NewInMemoryEventBus()
+	eventBus.Register(gameInfoProjector)
+
+	return dispatcher.NewDispatcher(aggregateStore, eventBus)
+}
+
+func findGameShortInfo(gameInfos domain.ReadModelRepository, ID domain.Identifier) (interface{}, error) {
+	queries := querydispatcher.NewDispatcher()
+	queries.Register("GetGameShortInfo", &queryhandler.GameShortInfoFinder{Repository: gameInfos})
+
+	return queries.Handle(query.GetGameShortInfo{GameID: ID.String()})
+}
+
+func createAggregateFactory() *aggregate.Factory {
 	f := aggregate.NewFactory()
 	f.RegisterAggregate(func(ID domain.Identifier) domain.AdvancedAggregate {
 		gameAgg := game.NewAggregate(ID)
@@ -101,9 +866,5 @@ func createDispatcher(gameInfo *report.GameShortInfo) *dispatcher.Dispatcher {
 		return aggregate.NewAdvanced(gameAgg, commandHandler, eventApplier)
 	})
 
-	aggregateStore := store.NewStore(eventstore.NewInInMemoryEventStore(), f)
-	eventBus := eventbus.NewInMemoryEventBus()
-	eventBus.Register(gameInfoProjector)
-
-	return dispatcher.NewDispatcher(aggregateStore, eventBus)
+	return f
 }
@@ -0,0 +1,138 @@
+package match_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/screwyprof/roshambo/internal/pkg/assert"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/aggregate"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/dispatcher"
+	. "github.com/screwyprof/roshambo/internal/pkg/cqrs/dispatcher/testdata/fixture"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventbus"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventhandler"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/eventstore"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/querydispatcher"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/readmodel"
+	"github.com/screwyprof/roshambo/internal/pkg/cqrs/store"
+
+	"github.com/screwyprof/roshambo/pkg/command"
+	"github.com/screwyprof/roshambo/pkg/domain"
+	"github.com/screwyprof/roshambo/pkg/domain/game"
+	"github.com/screwyprof/roshambo/pkg/domain/match"
+	"github.com/screwyprof/roshambo/pkg/event"
+	gameEventHandler "github.com/screwyprof/roshambo/pkg/eventhandler"
+	"github.com/screwyprof/roshambo/pkg/query"
+	"github.com/screwyprof/roshambo/pkg/queryhandler"
+	"github.com/screwyprof/roshambo/pkg/report"
+)
+
+func TestBestOfThree(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	matchScores := readmodel.NewInMemoryRepository()
+	want := report.MatchScore{
+		MatchID:           ID.String(),
+		FirstPlayer:       player1,
+		SecondPlayer:      player2,
+		FirstPlayerScore:  1,
+		SecondPlayerScore: 2,
+		BestOf:            3,
+		Round:             4,
+		State:             "match won",
+		Winner:            player2,
+	}
+
+	Test(t)(
+		Given(createDispatcher(matchScores)),
+		When(
+			command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player2, BestOf: 3},
+			command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Rock)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Paper)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Rock)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Rock)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Paper)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Scissors)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Paper)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Scissors)},
+		),
+		Then(
+			event.MatchCreated{MatchID: ID.String(), FirstPlayer: player1, SecondPlayer: player2, BestOf: 3, RuleSet: "classic"},
+			event.RoundStarted{MatchID: ID.String(), Round: 1},
+			event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player1, Move: int(game.Rock)},
+			event.RoundMoveDecided{MatchID: ID.String(), Round: 1, PlayerEmail: player2, Move: int(game.Paper)},
+			event.RoundWon{MatchID: ID.String(), Round: 1, Winner: player2, Loser: player1},
+			event.RoundStarted{MatchID: ID.String(), Round: 2},
+			event.RoundMoveDecided{MatchID: ID.String(), Round: 2, PlayerEmail: player1, Move: int(game.Rock)},
+			event.RoundMoveDecided{MatchID: ID.String(), Round: 2, PlayerEmail: player2, Move: int(game.Rock)},
+			event.RoundTied{MatchID: ID.String(), Round: 2},
+			event.RoundStarted{MatchID: ID.String(), Round: 3},
+			event.RoundMoveDecided{MatchID: ID.String(), Round: 3, PlayerEmail: player2, Move: int(game.Paper)},
+			event.RoundMoveDecided{MatchID: ID.String(), Round: 3, PlayerEmail: player1, Move: int(game.Scissors)},
+			event.RoundWon{MatchID: ID.String(), Round: 3, Winner: player1, Loser: player2},
+			event.RoundStarted{MatchID: ID.String(), Round: 4},
+			event.RoundMoveDecided{MatchID: ID.String(), Round: 4, PlayerEmail: player1, Move: int(game.Paper)},
+			event.RoundMoveDecided{MatchID: ID.String(), Round: 4, PlayerEmail: player2, Move: int(game.Scissors)},
+			event.RoundWon{MatchID: ID.String(), Round: 4, Winner: player2, Loser: player1},
+			event.MatchWon{MatchID: ID.String(), Winner: player2, Loser: player1},
+		),
+	)
+
+	got, err := findMatchScore(matchScores, ID)
+	assert.Ok(t, err)
+	assert.Equals(t, want, got)
+}
+
+func TestMoveCannotBeMadeAfterTheMatchIsWon(t *testing.T) {
+	ID := ksuid.New()
+	player1 := "tom@game.net"
+	player2 := "jerry@game.net"
+
+	Test(t)(
+		Given(createDispatcher(readmodel.NewInMemoryRepository())),
+		When(
+			command.CreateMatch{MatchID: ID, FirstPlayer: player1, SecondPlayer: player2, BestOf: 1},
+			command.PlayRound{MatchID: ID, PlayerEmail: player1, Move: int(game.Rock)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Scissors)},
+			command.PlayRound{MatchID: ID, PlayerEmail: player2, Move: int(game.Paper)},
+		),
+		ThenFailWith(match.ErrTheMatchHaveNotStartedOrFinished),
+	)
+}
+
+func createDispatcher(matchScores domain.ReadModelRepository) *dispatcher.Dispatcher {
+	matchScoreProjector := eventhandler.New()
+	matchScoreProjector.RegisterHandlers(&gameEventHandler.MatchScoreProjector{Repository: matchScores})
+
+	aggregateStore := store.NewStore(eventstore.NewInInMemoryEventStore(), createAggregateFactory())
+	eventBus := eventbus.NewInMemoryEventBus()
+	eventBus.Register(matchScoreProjector)
+
+	return dispatcher.NewDispatcher(aggregateStore, eventBus)
+}
+
+func findMatchScore(matchScores domain.ReadModelRepository, ID domain.Identifier) (interface{}, error) {
+	queries := querydispatcher.NewDispatcher()
+	queries.Register("GetMatchScore", &queryhandler.MatchScoreFinder{Repository: matchScores})
+
+	return queries.Handle(query.GetMatchScore{MatchID: ID.String()})
+}
+
+func createAggregateFactory() *aggregate.Factory {
+	f := aggregate.NewFactory()
+	f.RegisterAggregate(func(ID domain.Identifier) domain.AdvancedAggregate {
+		matchAgg := match.NewAggregate(ID)
+
+		commandHandler := aggregate.NewCommandHandler()
+		commandHandler.RegisterHandlers(matchAgg)
+
+		eventApplier := aggregate.NewEventApplier()
+		eventApplier.RegisterAppliers(matchAgg)
+
+		return aggregate.NewAdvanced(matchAgg, commandHandler, eventApplier)
+	})
+
+	return f
+}